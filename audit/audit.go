@@ -0,0 +1,263 @@
+// Package audit keeps an append-only record of who did what: logins, operator
+// actions such as acknowledgements, denied requests and configuration reloads.
+// Records are appended to a JSON-lines file that is rotated by size; without a
+// file they are only kept in memory.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/util"
+)
+
+// Actions recorded by the dashboard.
+const (
+	ActionLogin          = "login"
+	ActionLoginFailed    = "login_failed"
+	ActionLogout         = "logout"
+	ActionAccessDenied   = "access_denied"
+	ActionConfigReload   = "config_reload"
+	ActionRecheck        = "recheck"
+	ActionNologgingAck   = "nologging_ack"
+	ActionMaintenanceAdd = "maintenance_add"
+	ActionMaintenanceEnd = "maintenance_end"
+	ActionAlertAck       = "alert_ack"
+	ActionAlertUnack     = "alert_unack"
+	ActionNotePin        = "note_pin"
+	ActionNoteRemove     = "note_remove"
+)
+
+// Outcomes of a recorded action.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeDenied  = "denied"
+)
+
+// memoryRecords is how many records are kept when no file is configured.
+const memoryRecords = 1000
+
+// Record is one audited action.
+type Record struct {
+	Time    time.Time         `json:"time"`
+	Actor   string            `json:"actor"`            // User or token name; "system" for automatic actions
+	Method  string            `json:"method,omitempty"` // Authentication method of the actor
+	Client  string            `json:"client,omitempty"` // Client address
+	Action  string            `json:"action"`
+	Target  string            `json:"target,omitempty"` // Database or path acted on
+	Outcome string            `json:"outcome"`
+	Details map[string]string `json:"details,omitempty"`
+	Changes []Change          `json:"changes,omitempty"` // Configuration differences of a reload
+}
+
+// Filter selects records for Query. Empty fields match everything.
+type Filter struct {
+	From   time.Time
+	To     time.Time
+	Actor  string
+	Action string
+	Target string
+	Limit  int // Newest records first; no limit when zero
+}
+
+// Matches reports whether a record passes the filter.
+func (f Filter) Matches(r Record) bool {
+	if !f.From.IsZero() && r.Time.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !r.Time.Before(f.To) {
+		return false
+	}
+	if f.Actor != "" && !strings.EqualFold(f.Actor, r.Actor) {
+		return false
+	}
+	if f.Action != "" && f.Action != r.Action {
+		return false
+	}
+	return f.Target == "" || strings.EqualFold(f.Target, r.Target)
+}
+
+var (
+	mu      sync.Mutex
+	cfg     models.AuditConfig
+	file    *os.File
+	size    int64
+	records []Record // Used when no file is configured
+)
+
+// Open starts writing records to the configured file. It may be called again
+// after a configuration reload; the file is reopened when its name changed.
+func Open(config models.AuditConfig) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if file != nil && config.File == cfg.File {
+		cfg = config
+		return nil
+	}
+	if file != nil {
+		file.Close()
+		file = nil
+	}
+	cfg = config
+	if config.File == "" {
+		return nil
+	}
+	if dir := filepath.Dir(config.File); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create audit log directory '%s': %w", dir, err)
+		}
+	}
+	return openFile()
+}
+
+// Log appends a record, filling in the time when it is not set. Failures to
+// write are reported in the application log; the action itself is not undone.
+func Log(record Record) {
+	if record.Time.IsZero() {
+		record.Time = time.Now()
+	}
+	if record.Outcome == "" {
+		record.Outcome = OutcomeSuccess
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if cfg.File == "" {
+		records = append(records, record)
+		if len(records) > memoryRecords {
+			records = records[len(records)-memoryRecords:]
+		}
+		return
+	}
+	if err := write(record); err != nil && util.Logger != nil {
+		util.Logger.Printf("Failed to write audit record %s by %s: %v", record.Action, record.Actor, err)
+	}
+}
+
+// Query returns the records matching the filter, newest first.
+func Query(filter Filter) ([]Record, error) {
+	mu.Lock()
+	path := cfg.File
+	var result []Record
+	if path == "" {
+		for _, record := range records {
+			if filter.Matches(record) {
+				result = append(result, record)
+			}
+		}
+	}
+	mu.Unlock()
+
+	if path != "" {
+		// Rotated files sort by their timestamp suffix, the current file is the newest.
+		files := append(rotatedFiles(path), path)
+		for _, name := range files {
+			if err := readFile(name, func(record Record) {
+				if filter.Matches(record) {
+					result = append(result, record)
+				}
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Time.After(result[j].Time) })
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+// openFile opens the audit file for appending. The caller must hold mu.
+func openFile() error {
+	f, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log '%s': %w", cfg.File, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to open audit log '%s': %w", cfg.File, err)
+	}
+	file, size = f, info.Size()
+	return nil
+}
+
+// write appends one record, rotating the file first when it would grow beyond
+// the configured size. The caller must hold mu.
+func write(record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	if file == nil {
+		if err := openFile(); err != nil {
+			return err
+		}
+	}
+	if size > 0 && size+int64(len(line)) > int64(cfg.MaxSizeMB)*1024*1024 {
+		if err := rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := file.Write(line)
+	size += int64(n)
+	return err
+}
+
+// rotate renames the current file with a timestamp suffix, deletes the oldest
+// rotated files beyond the configured number and starts a new file. The caller must hold mu.
+func rotate() error {
+	file.Close()
+	file = nil
+	ext := filepath.Ext(cfg.File)
+	rotated := strings.TrimSuffix(cfg.File, ext) + "-" + time.Now().UTC().Format("20060102T150405.000") + ext
+	if err := os.Rename(cfg.File, rotated); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+	if old := rotatedFiles(cfg.File); len(old) > cfg.MaxBackups {
+		for _, name := range old[:len(old)-cfg.MaxBackups] {
+			os.Remove(name)
+		}
+	}
+	return openFile()
+}
+
+// rotatedFiles returns the rotated files of path, oldest first.
+func rotatedFiles(path string) []string {
+	ext := filepath.Ext(path)
+	matches, _ := filepath.Glob(strings.TrimSuffix(path, ext) + "-*" + ext)
+	sort.Strings(matches)
+	return matches
+}
+
+// readFile calls fn for each record in a file, skipping damaged lines.
+func readFile(path string, fn func(Record)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to open audit log '%s': %w", path, err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var record Record
+		if json.Unmarshal(scanner.Bytes(), &record) == nil {
+			fn(record)
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,173 @@
+package audit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Kinds of configuration change.
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// masked replaces the value of secret settings in recorded changes.
+const masked = "******"
+
+// Change is a database added, removed or changed by a configuration reload, or
+// a changed top-level section such as "auth" or "health".
+type Change struct {
+	Kind    string        `json:"kind"`
+	Section string        `json:"section"`
+	Name    string        `json:"name,omitempty"` // Database name
+	Fields  []FieldChange `json:"fields,omitempty"`
+}
+
+// FieldChange is one setting that differs; secrets are masked.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// ConfigDiff compares two configurations. Databases are matched by name;
+// passwords, password hashes, tokens and secrets never appear in clear text.
+func ConfigDiff(oldConfig, newConfig models.Config) []Change {
+	var changes []Change
+
+	oldDBs := make(map[string]models.DatabaseConfig)
+	for _, db := range oldConfig.DBs {
+		oldDBs[db.Name] = db
+	}
+	newNames := make(map[string]bool)
+	for _, db := range newConfig.DBs {
+		newNames[db.Name] = true
+		previous, ok := oldDBs[db.Name]
+		if !ok {
+			changes = append(changes, Change{Kind: ChangeAdded, Section: "databases", Name: db.Name})
+			continue
+		}
+		if fields := diffFields(flatten(previous), flatten(db)); len(fields) > 0 {
+			changes = append(changes, Change{Kind: ChangeChanged, Section: "databases", Name: db.Name, Fields: fields})
+		}
+	}
+	for _, db := range oldConfig.DBs {
+		if !newNames[db.Name] {
+			changes = append(changes, Change{Kind: ChangeRemoved, Section: "databases", Name: db.Name})
+		}
+	}
+
+	oldSections, newSections := sections(oldConfig), sections(newConfig)
+	names := make([]string, 0, len(newSections))
+	for name := range newSections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if fields := diffFields(oldSections[name], newSections[name]); len(fields) > 0 {
+			changes = append(changes, Change{Kind: ChangeChanged, Section: name, Fields: fields})
+		}
+	}
+	return changes
+}
+
+// sections flattens every top-level section except the databases.
+func sections(config models.Config) map[string]map[string]string {
+	config.DBs = nil
+	all := flatten(config)
+	result := make(map[string]map[string]string)
+	for key, value := range all {
+		section, field, _ := strings.Cut(key, ".")
+		section = strings.SplitN(section, "[", 2)[0]
+		if section == "databases" {
+			continue
+		}
+		if result[section] == nil {
+			result[section] = make(map[string]string)
+		}
+		if field == "" {
+			field = key
+		}
+		result[section][field] = value
+	}
+	return result
+}
+
+// diffFields lists the keys whose values differ, sorted by key.
+func diffFields(oldValues, newValues map[string]string) []FieldChange {
+	keys := make(map[string]bool)
+	for key := range oldValues {
+		keys[key] = true
+	}
+	for key := range newValues {
+		keys[key] = true
+	}
+	var fields []FieldChange
+	for key := range keys {
+		if oldValues[key] == newValues[key] {
+			continue
+		}
+		change := FieldChange{Field: key, Old: oldValues[key], New: newValues[key]}
+		if isSecret(key) {
+			change.Old, change.New = maskValue(change.Old), maskValue(change.New)
+		}
+		fields = append(fields, change)
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+	return fields
+}
+
+// flatten turns a configuration value into dotted keys, e.g. "auth.users[0].username",
+// using its YAML field names.
+func flatten(v interface{}) map[string]string {
+	result := make(map[string]string)
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return result
+	}
+	var tree interface{}
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return result
+	}
+	flattenInto(result, "", tree)
+	return result
+}
+
+func flattenInto(result map[string]string, prefix string, node interface{}) {
+	switch value := node.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flattenInto(result, key, child)
+		}
+	case []interface{}:
+		for i, child := range value {
+			flattenInto(result, fmt.Sprintf("%s[%d]", prefix, i), child)
+		}
+	case nil:
+	default:
+		result[prefix] = fmt.Sprint(value)
+	}
+}
+
+// isSecret reports whether a flattened key names a password, hash, token or secret.
+func isSecret(key string) bool {
+	leaf := key[strings.LastIndex(key, ".")+1:]
+	leaf = strings.ToLower(strings.SplitN(leaf, "[", 2)[0])
+	return strings.Contains(leaf, "password") || strings.Contains(leaf, "secret") || leaf == "token"
+}
+
+// maskValue hides a secret but still shows whether it was set.
+func maskValue(value string) string {
+	if value == "" {
+		return ""
+	}
+	return masked
+}
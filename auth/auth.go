@@ -0,0 +1,158 @@
+// Package auth authenticates dashboard and API requests. Authenticators for
+// session cookies, API tokens and kiosk tokens are tried in turn by Middleware;
+// local password and OIDC logins issue the session cookies.
+package auth
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/audit"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+// Authentication methods reported in Principal.Method.
+const (
+	MethodPassword = "password"
+	MethodOIDC     = "oidc"
+	MethodToken    = "token"
+	MethodKiosk    = "kiosk"
+)
+
+// principalKey is the gin context key holding the authenticated *Principal.
+const principalKey = "auth.principal"
+
+// Principal is the authenticated user or client of a request. Roles,
+// permissions and tags are resolved from the configuration on every request.
+type Principal struct {
+	Name        string   `json:"name"`
+	Method      string   `json:"method"`
+	ReadOnly    bool     `json:"read_only"`        // Kiosk tokens may only read
+	Groups      []string `json:"groups,omitempty"` // OIDC groups
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+	Tags        []string `json:"tags,omitempty"` // Databases visible to the principal; all when empty
+}
+
+// Authenticator recognizes one kind of credential on a request. It returns nil
+// when the request does not carry that credential or it is not valid.
+type Authenticator interface {
+	Authenticate(c *gin.Context, cfg models.AuthConfig) *Principal
+}
+
+// authenticators are tried in order; explicit credentials win over the session cookie.
+var authenticators = []Authenticator{tokenAuthenticator{}, kioskAuthenticator{}, sessionAuthenticator{}}
+
+// Register adds an authenticator that is tried after the built-in ones.
+func Register(a Authenticator) {
+	authenticators = append(authenticators, a)
+}
+
+// publicPaths are reachable without authentication: the login page, the login
+// endpoints and what the login page needs to render. Entries ending in a slash
+// cover everything below them; the others match exactly.
+var publicPaths = []string{"/login", "/auth/", "/static/", "/favicon.ico", "/api/i18n/"}
+
+// Middleware rejects requests without valid credentials when auth.enabled is
+// set. Browsers asking for a page are sent to the login page; everything else
+// gets a 401 in the ApiResponse envelope. Read-only principals may not change
+// anything; what others may do is checked per route group by Require.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := models.GetConfig().Auth
+		if !cfg.Enabled || isPublic(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+		for _, a := range authenticators {
+			principal := a.Authenticate(c, cfg)
+			if principal == nil || !applyGrants(principal, cfg) {
+				continue
+			}
+			c.Set(principalKey, principal)
+			if principal.ReadOnly && c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+				Audit(c, audit.Record{Action: audit.ActionAccessDenied, Target: c.Request.URL.Path, Outcome: audit.OutcomeDenied,
+					Details: map[string]string{"request": c.Request.Method, "reason": "read-only"}})
+				Abort(c, http.StatusForbidden, "read-only access")
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if c.Request.Method == http.MethodGet && !strings.HasPrefix(c.Request.URL.Path, "/api/") &&
+			strings.Contains(c.GetHeader("Accept"), "text/html") {
+			next := BasePath() + strings.TrimPrefix(c.Request.URL.RequestURI(), "/")
+			c.Redirect(http.StatusFound, BasePath()+"login?next="+url.QueryEscape(next))
+			c.Abort()
+			return
+		}
+		c.Header("WWW-Authenticate", `Bearer realm="dashboard"`)
+		Abort(c, http.StatusUnauthorized, "authentication required")
+	}
+}
+
+// Current returns the principal of the request, or nil when auth is disabled.
+func Current(c *gin.Context) *Principal {
+	if value, ok := c.Get(principalKey); ok {
+		return value.(*Principal)
+	}
+	return nil
+}
+
+// Actor names who performed an action: the principal, or the client address when auth is disabled.
+func Actor(c *gin.Context) string {
+	if principal := Current(c); principal != nil {
+		return principal.Name
+	}
+	return c.ClientIP()
+}
+
+// Audit records an action of the request's principal in the audit log.
+func Audit(c *gin.Context, record audit.Record) {
+	record.Actor = Actor(c)
+	if principal := Current(c); principal != nil {
+		record.Method = principal.Method
+	}
+	record.Client = c.ClientIP()
+	audit.Log(record)
+}
+
+// Abort ends the request with an error in the ApiResponse envelope.
+func Abort(c *gin.Context, status int, message string) {
+	c.AbortWithStatusJSON(status, models.ApiResponse{Code: status, Message: message, Timestamp: time.Now().Unix()})
+}
+
+// BasePath returns server.public_base_path with a trailing slash, "/" by default.
+func BasePath() string {
+	base := models.GetConfig().Server.PublicBasePath
+	if base == "" {
+		return "/"
+	}
+	if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	return base
+}
+
+// isPublic reports whether a path is reachable without authentication.
+func isPublic(path string) bool {
+	for _, public := range publicPaths {
+		if path == public || (strings.HasSuffix(public, "/") && (path == strings.TrimSuffix(public, "/") || strings.HasPrefix(path, public))) {
+			return true
+		}
+	}
+	return false
+}
+
+// safeRedirect returns next if it is a local absolute path, otherwise the dashboard root.
+// This keeps the login endpoints from redirecting to other sites.
+func safeRedirect(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.Contains(next, `\`) {
+		return BasePath()
+	}
+	return next
+}
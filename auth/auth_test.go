@@ -0,0 +1,30 @@
+package auth
+
+import "testing"
+
+func TestIsPublic(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "/login", want: true},
+		{path: "/login/", want: false},
+		{path: "/loginfo", want: false},
+		{path: "/login.html", want: false},
+		{path: "/auth", want: true},
+		{path: "/auth/login", want: true},
+		{path: "/auth/oidc/callback", want: true},
+		{path: "/authz", want: false},
+		{path: "/static/app.js", want: true},
+		{path: "/favicon.ico", want: true},
+		{path: "/favicon.ico.bak", want: false},
+		{path: "/api/i18n/en", want: true},
+		{path: "/api/data", want: false},
+		{path: "/", want: false},
+	}
+	for _, tt := range tests {
+		if got := isPublic(tt.path); got != tt.want {
+			t.Errorf("isPublic(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
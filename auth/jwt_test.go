@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/util"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	util.Logger = log.New(io.Discard, "", 0)
+	os.Exit(m.Run())
+}
+
+// signJWT returns a compact JWS of claims signed with key, an *rsa.PrivateKey
+// (RS256) or an *ecdsa.PrivateKey on P-256 (ES256).
+func signJWT(t *testing.T, key crypto.Signer, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	alg := "RS256"
+	if _, ok := key.(*ecdsa.PrivateKey); ok {
+		alg = "ES256"
+	}
+	header, _ := json.Marshal(jwtHeader{Alg: alg, Kid: kid})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("marshal claims: %v", err)
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		if r, s, err = ecdsa.Sign(rand.Reader, k, digest[:]); err == nil {
+			signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+		}
+	}
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate RSA key: %v", err)
+	}
+	return key
+}
+
+func TestVerifyJWT(t *testing.T) {
+	rsaKey := newRSAKey(t)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate EC key: %v", err)
+	}
+	keys := map[string]crypto.PublicKey{"rsa": rsaKey.Public(), "ec": ecKey.Public()}
+	claims := map[string]interface{}{"sub": "alice"}
+
+	valid := signJWT(t, rsaKey, "rsa", claims)
+	parts := strings.Split(valid, ".")
+	tamperedClaims, _ := json.Marshal(map[string]interface{}{"sub": "mallory"})
+	tampered := parts[0] + "." + base64.RawURLEncoding.EncodeToString(tamperedClaims) + "." + parts[2]
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","kid":"rsa"}`)) + "." + parts[1] + "."
+
+	tests := []struct {
+		name    string
+		token   string
+		keys    map[string]crypto.PublicKey
+		wantErr bool
+	}{
+		{name: "RS256", token: valid, keys: keys},
+		{name: "ES256", token: signJWT(t, ecKey, "ec", claims), keys: keys},
+		{name: "no kid with a single key", token: signJWT(t, rsaKey, "", claims), keys: map[string]crypto.PublicKey{"rsa": rsaKey.Public()}},
+		{name: "signed by another key", token: signJWT(t, newRSAKey(t), "rsa", claims), keys: keys, wantErr: true},
+		{name: "tampered claims", token: tampered, keys: keys, wantErr: true},
+		{name: "algorithm none", token: unsigned, keys: keys, wantErr: true},
+		{name: "RSA kid on an EC token", token: signJWT(t, ecKey, "rsa", claims), keys: keys, wantErr: true},
+		{name: "malformed", token: "not.a-token", keys: keys, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := verifyJWT(tt.token, tt.keys)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got claims %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if got["sub"] != "alice" {
+				t.Errorf("got sub %v, want alice", got["sub"])
+			}
+		})
+	}
+}
+
+func TestVerifyJWTUnknownKey(t *testing.T) {
+	key := newRSAKey(t)
+	token := signJWT(t, key, "rotated", map[string]interface{}{"sub": "alice"})
+	keys := map[string]crypto.PublicKey{"old": key.Public(), "other": newRSAKey(t).Public()}
+	if _, err := verifyJWT(token, keys); !errors.Is(err, errUnknownKey) {
+		t.Fatalf("got error %v, want errUnknownKey", err)
+	}
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/audit"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/util"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// HashPassword returns the bcrypt hash to put into auth.users[].password_hash.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+// checkPassword verifies a local user's password. Unknown users are checked
+// against a dummy hash, so the response time does not reveal which users exist.
+func checkPassword(cfg models.AuthConfig, username, password string) bool {
+	for _, user := range cfg.Users {
+		if user.Username == username {
+			return bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) == nil
+		}
+	}
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+	})
+	bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+	return false
+}
+
+// loginHandler handles POST /auth/login with a JSON body of username and password.
+func loginHandler(c *gin.Context) {
+	cfg := models.GetConfig().Auth
+	var request struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Next     string `json:"next"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil || request.Username == "" {
+		Abort(c, http.StatusBadRequest, "username and password are required")
+		return
+	}
+	if !cfg.Enabled || !checkPassword(cfg, request.Username, request.Password) {
+		util.Logger.Printf("Login failed: %s (%s) from %s", request.Username, MethodPassword, c.ClientIP())
+		audit.Log(audit.Record{Actor: request.Username, Method: MethodPassword, Client: c.ClientIP(), Action: audit.ActionLoginFailed, Outcome: audit.OutcomeFailure})
+		Abort(c, http.StatusUnauthorized, "invalid username or password")
+		return
+	}
+	principal := &Principal{Name: request.Username, Method: MethodPassword}
+	applyGrants(principal, cfg)
+	StartSession(c, cfg, principal)
+	data := gin.H{"user": principal, "next": safeRedirect(request.Next)}
+	c.JSON(http.StatusOK, models.ApiResponse{Code: 200, Data: data, Message: "success", Timestamp: time.Now().Unix()})
+}
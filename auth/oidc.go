@@ -0,0 +1,319 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/audit"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/util"
+)
+
+const (
+	oidcFlowCookie = "drdash_oidc"
+	oidcFlowTTL    = 10 * time.Minute
+	// oidcRefresh is how long discovery documents and keys are reused.
+	oidcRefresh = time.Hour
+	// clockLeeway tolerates clock differences with the provider when checking expiry.
+	clockLeeway = time.Minute
+)
+
+var oidcClient = &http.Client{Timeout: 10 * time.Second}
+
+// oidcProvider is the discovery document of an issuer together with its signing keys.
+type oidcProvider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+var (
+	providerLock sync.Mutex
+	provider     *oidcProvider
+)
+
+// oidcFlow is kept in a signed cookie between the redirect to the provider and the callback.
+type oidcFlow struct {
+	State    string `json:"s"`
+	Nonce    string `json:"n"`
+	Verifier string `json:"v"` // PKCE code verifier
+	Next     string `json:"x"`
+	Expires  int64  `json:"e"`
+}
+
+// getProvider returns the discovery document and keys of the configured issuer,
+// fetching them when missing, stale or when refreshKeys is set.
+func getProvider(cfg models.OIDCConfig, refreshKeys bool) (*oidcProvider, error) {
+	providerLock.Lock()
+	defer providerLock.Unlock()
+	if provider != nil && provider.Issuer == strings.TrimSuffix(cfg.Issuer, "/") && !refreshKeys && time.Since(provider.fetchedAt) < oidcRefresh {
+		return provider, nil
+	}
+
+	var discovered oidcProvider
+	if err := getJSON(strings.TrimSuffix(cfg.Issuer, "/")+"/.well-known/openid-configuration", &discovered); err != nil {
+		return nil, fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+	if strings.TrimSuffix(discovered.Issuer, "/") != strings.TrimSuffix(cfg.Issuer, "/") {
+		return nil, fmt.Errorf("OIDC discovery returned issuer '%s', expected '%s'", discovered.Issuer, cfg.Issuer)
+	}
+	discovered.Issuer = strings.TrimSuffix(discovered.Issuer, "/")
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := getJSON(discovered.JWKSURI, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to fetch OIDC signing keys: %w", err)
+	}
+	discovered.keys = make(map[string]crypto.PublicKey)
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			util.Logger.Printf("Skipping OIDC signing key: %v", err)
+			continue
+		}
+		discovered.keys[jwk.Kid] = key
+	}
+	discovered.fetchedAt = time.Now()
+	provider = &discovered
+	return provider, nil
+}
+
+// oidcLoginHandler redirects the browser to the provider's authorization endpoint.
+func oidcLoginHandler(c *gin.Context) {
+	cfg := models.GetConfig().Auth
+	if !cfg.Enabled || cfg.OIDC.Issuer == "" {
+		Abort(c, http.StatusNotFound, "OIDC login is not configured")
+		return
+	}
+	p, err := getProvider(cfg.OIDC, false)
+	if err != nil {
+		util.Logger.Printf("OIDC login failed: %v", err)
+		c.Redirect(http.StatusFound, BasePath()+"login?error=oidc")
+		return
+	}
+
+	flow := oidcFlow{
+		State:    randomString(),
+		Nonce:    randomString(),
+		Verifier: randomString(),
+		Next:     safeRedirect(c.Query("next")),
+		Expires:  time.Now().Add(oidcFlowTTL).Unix(),
+	}
+	setCookie(c, cfg, oidcFlowCookie, writeSigned(cfg, flow), int(oidcFlowTTL.Seconds()))
+
+	challenge := sha256.Sum256([]byte(flow.Verifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {cfg.OIDC.ClientID},
+		"redirect_uri":          {cfg.OIDC.RedirectURL},
+		"scope":                 {strings.Join(cfg.OIDC.Scopes, " ")},
+		"state":                 {flow.State},
+		"nonce":                 {flow.Nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(p.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	c.Redirect(http.StatusFound, p.AuthorizationEndpoint+separator+query.Encode())
+}
+
+// oidcCallbackHandler exchanges the authorization code, verifies the ID token
+// and starts a session for the user named by auth.oidc.username_claim.
+func oidcCallbackHandler(c *gin.Context) {
+	cfg := models.GetConfig().Auth
+	principal, next, err := completeOIDCLogin(c, cfg)
+	setCookie(c, cfg, oidcFlowCookie, "", -1)
+	if err != nil {
+		util.Logger.Printf("OIDC login failed from %s: %v", c.ClientIP(), err)
+		audit.Log(audit.Record{Actor: "anonymous", Method: MethodOIDC, Client: c.ClientIP(), Action: audit.ActionLoginFailed,
+			Outcome: audit.OutcomeFailure, Details: map[string]string{"error": err.Error()}})
+		c.Redirect(http.StatusFound, BasePath()+"login?error=oidc")
+		return
+	}
+	StartSession(c, cfg, principal)
+	c.Redirect(http.StatusFound, next)
+}
+
+func completeOIDCLogin(c *gin.Context, cfg models.AuthConfig) (*Principal, string, error) {
+	if !cfg.Enabled || cfg.OIDC.Issuer == "" {
+		return nil, "", errors.New("OIDC login is not configured")
+	}
+	if providerError := c.Query("error"); providerError != "" {
+		return nil, "", fmt.Errorf("provider returned %s: %s", providerError, c.Query("error_description"))
+	}
+	value, err := c.Cookie(oidcFlowCookie)
+	var flow oidcFlow
+	if err != nil || !readSigned(cfg, value, &flow) || time.Now().Unix() > flow.Expires {
+		return nil, "", errors.New("login flow expired or missing")
+	}
+	if c.Query("state") != flow.State {
+		return nil, "", errors.New("state mismatch")
+	}
+
+	p, err := getProvider(cfg.OIDC, false)
+	if err != nil {
+		return nil, "", err
+	}
+	idToken, err := exchangeCode(p, cfg.OIDC, c.Query("code"), flow.Verifier)
+	if err != nil {
+		return nil, "", err
+	}
+	claims, err := verifyJWT(idToken, p.keys)
+	if errors.Is(err, errUnknownKey) {
+		// The provider may have rotated its keys since they were fetched.
+		if p, err = getProvider(cfg.OIDC, true); err == nil {
+			claims, err = verifyJWT(idToken, p.keys)
+		}
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid ID token: %w", err)
+	}
+	if err := validateClaims(claims, p.Issuer, cfg.OIDC.ClientID, flow.Nonce); err != nil {
+		return nil, "", fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	name := ""
+	for _, claim := range []string{cfg.OIDC.UsernameClaim, "email", "sub"} {
+		if value, ok := claims[claim].(string); ok && value != "" {
+			name = value
+			break
+		}
+	}
+	if name == "" {
+		return nil, "", fmt.Errorf("ID token has no '%s', email or sub claim to name the user", cfg.OIDC.UsernameClaim)
+	}
+	// Only groups with a role binding are kept, as providers may list hundreds
+	// and the session cookie has to stay small.
+	var groups []string
+	for _, group := range claimStrings(claims[cfg.OIDC.GroupsClaim]) {
+		for _, binding := range cfg.OIDC.GroupRoles {
+			if strings.EqualFold(group, binding.Group) {
+				groups = appendNew(groups, group)
+			}
+		}
+	}
+	return &Principal{Name: name, Method: MethodOIDC, Groups: groups}, flow.Next, nil
+}
+
+// exchangeCode redeems the authorization code at the token endpoint and returns the ID token.
+func exchangeCode(p *oidcProvider, cfg models.OIDCConfig, code, verifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {cfg.RedirectURL},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequest(http.MethodPost, p.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(cfg.ClientID), url.QueryEscape(cfg.ClientSecret))
+	resp, err := oidcClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("invalid token response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || result.IDToken == "" {
+		return "", fmt.Errorf("token request rejected (HTTP %d): %s %s", resp.StatusCode, result.Error, result.ErrorDescription)
+	}
+	return result.IDToken, nil
+}
+
+// validateClaims checks issuer, audience, expiry and nonce of an ID token.
+func validateClaims(claims map[string]interface{}, issuer, clientID, nonce string) error {
+	if iss, _ := claims["iss"].(string); strings.TrimSuffix(iss, "/") != issuer {
+		return fmt.Errorf("issuer '%s' does not match", iss)
+	}
+	audienceOK := false
+	switch aud := claims["aud"].(type) {
+	case string:
+		audienceOK = aud == clientID
+	case []interface{}:
+		for _, item := range aud {
+			if item == clientID {
+				audienceOK = true
+			}
+		}
+	}
+	if !audienceOK {
+		return errors.New("token was not issued for this client")
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok || time.Now().Add(-clockLeeway).After(time.Unix(int64(exp), 0)) {
+		return errors.New("token expired")
+	}
+	if claimed, _ := claims["nonce"].(string); claimed != nonce {
+		return errors.New("nonce mismatch")
+	}
+	return nil
+}
+
+// claimStrings reads a claim holding a list of strings or a single string.
+func claimStrings(claim interface{}) []string {
+	switch value := claim.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		var result []string
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+func getJSON(url string, v interface{}) error {
+	resp, err := oidcClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned HTTP %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// randomString returns 32 random bytes, base64url encoded.
+func randomString() string {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		panic("auth: failed to read random bytes: " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+const (
+	testClientID = "dashboard"
+	testCode     = "code-1"
+	testState    = "state-1"
+	testNonce    = "nonce-1"
+	testVerifier = "verifier-1"
+)
+
+// testIssuer is an OpenID provider serving discovery, its signing key and a
+// token endpoint that answers testCode with IDToken.
+type testIssuer struct {
+	*httptest.Server
+	Key     *rsa.PrivateKey
+	IDToken string
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	issuer := &testIssuer{Key: newRSAKey(t)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer.URL,
+			"authorization_endpoint": issuer.URL + "/authorize",
+			"token_endpoint":         issuer.URL + "/token",
+			"jwks_uri":               issuer.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		pub := issuer.Key.PublicKey
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []jsonWebKey{{
+			Kty: "RSA",
+			Kid: "key-1",
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, _, _ := r.BasicAuth()
+		if r.PostFormValue("code") != testCode || r.PostFormValue("code_verifier") != testVerifier || clientID != testClientID {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": issuer.IDToken})
+	})
+	issuer.Server = httptest.NewServer(mux)
+	t.Cleanup(issuer.Close)
+	t.Cleanup(func() {
+		providerLock.Lock()
+		provider = nil
+		providerLock.Unlock()
+	})
+	return issuer
+}
+
+// claims returns valid ID token claims for the issuer.
+func (i *testIssuer) claims() map[string]interface{} {
+	return map[string]interface{}{
+		"iss":                i.URL,
+		"aud":                testClientID,
+		"sub":                "0001",
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"nonce":              testNonce,
+		"preferred_username": "alice",
+		"email":              "alice@example.com",
+		"groups":             []string{"dba", "everyone"},
+	}
+}
+
+func testAuthConfig(issuer string) models.AuthConfig {
+	return models.AuthConfig{
+		Enabled:       true,
+		SessionSecret: "test-secret",
+		SessionHours:  1,
+		OIDC: models.OIDCConfig{
+			Issuer:        issuer,
+			ClientID:      testClientID,
+			RedirectURL:   "http://dashboard.example.com/auth/oidc/callback",
+			UsernameClaim: "preferred_username",
+			GroupsClaim:   "groups",
+			GroupRoles:    []models.GroupGrant{{Group: "DBA"}},
+		},
+	}
+}
+
+// callbackContext returns the context of the provider's redirect back to the
+// dashboard, carrying flowCookie and the given query.
+func callbackContext(flowCookie string, query url.Values) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/auth/oidc/callback?"+query.Encode(), nil)
+	if flowCookie != "" {
+		c.Request.AddCookie(&http.Cookie{Name: oidcFlowCookie, Value: flowCookie})
+	}
+	return c
+}
+
+func TestCompleteOIDCLogin(t *testing.T) {
+	issuer := newTestIssuer(t)
+	cfg := testAuthConfig(issuer.URL)
+	flow := oidcFlow{State: testState, Nonce: testNonce, Verifier: testVerifier, Next: "/detail", Expires: time.Now().Add(time.Minute).Unix()}
+	validFlow := writeSigned(cfg, flow)
+	callback := url.Values{"state": {testState}, "code": {testCode}}
+
+	tests := []struct {
+		name     string
+		claims   func(map[string]interface{})
+		token    func(map[string]interface{}) string // Signs the claims; the issuer's key when nil
+		flow     string
+		query    url.Values
+		wantName string
+		wantErr  string
+	}{
+		{name: "valid", wantName: "alice"},
+		{name: "email when the username claim is missing", claims: func(c map[string]interface{}) { delete(c, "preferred_username") }, wantName: "alice@example.com"},
+		{name: "sub as the last resort", claims: func(c map[string]interface{}) {
+			delete(c, "preferred_username")
+			delete(c, "email")
+		}, wantName: "0001"},
+		{name: "audience list", claims: func(c map[string]interface{}) { c["aud"] = []string{"other", testClientID} }, wantName: "alice"},
+		{name: "no name claim", claims: func(c map[string]interface{}) {
+			delete(c, "preferred_username")
+			delete(c, "email")
+			delete(c, "sub")
+		}, wantErr: "no 'preferred_username', email or sub claim"},
+		{name: "bad signature", token: func(c map[string]interface{}) string { return signJWT(t, newRSAKey(t), "key-1", c) }, wantErr: "invalid ID token"},
+		{name: "unknown key", token: func(c map[string]interface{}) string { return signJWT(t, newRSAKey(t), "key-2", c) }, wantErr: "unknown key"},
+		{name: "wrong issuer", claims: func(c map[string]interface{}) { c["iss"] = "https://evil.example.com" }, wantErr: "issuer"},
+		{name: "wrong audience", claims: func(c map[string]interface{}) { c["aud"] = "other" }, wantErr: "not issued for this client"},
+		{name: "expired", claims: func(c map[string]interface{}) { c["exp"] = time.Now().Add(-2 * clockLeeway).Unix() }, wantErr: "expired"},
+		{name: "no expiry", claims: func(c map[string]interface{}) { delete(c, "exp") }, wantErr: "expired"},
+		{name: "nonce mismatch", claims: func(c map[string]interface{}) { c["nonce"] = "nonce-2" }, wantErr: "nonce mismatch"},
+		{name: "state mismatch", query: url.Values{"state": {"state-2"}, "code": {testCode}}, wantErr: "state mismatch"},
+		{name: "missing flow cookie", flow: "-", wantErr: "expired or missing"},
+		{name: "tampered flow cookie", flow: tamper(validFlow, func(f map[string]interface{}) { f["s"] = "state-2" }), wantErr: "expired or missing"},
+		{name: "expired flow", flow: writeSigned(cfg, oidcFlow{State: testState, Nonce: testNonce, Verifier: testVerifier, Expires: time.Now().Add(-time.Second).Unix()}), wantErr: "expired or missing"},
+		{name: "rejected code", query: url.Values{"state": {testState}, "code": {"code-2"}}, wantErr: "invalid_grant"},
+		{name: "provider error", query: url.Values{"error": {"access_denied"}}, wantErr: "access_denied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := issuer.claims()
+			if tt.claims != nil {
+				tt.claims(claims)
+			}
+			if tt.token != nil {
+				issuer.IDToken = tt.token(claims)
+			} else {
+				issuer.IDToken = signJWT(t, issuer.Key, "key-1", claims)
+			}
+			flowCookie, query := validFlow, callback
+			if tt.flow == "-" {
+				flowCookie = ""
+			} else if tt.flow != "" {
+				flowCookie = tt.flow
+			}
+			if tt.query != nil {
+				query = tt.query
+			}
+
+			principal, next, err := completeOIDCLogin(callbackContext(flowCookie, query), cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("login: %v", err)
+			}
+			if principal.Name != tt.wantName || principal.Method != MethodOIDC || next != "/detail" {
+				t.Errorf("got %s (%s) redirected to %s; want %s (oidc) redirected to /detail", principal.Name, principal.Method, next, tt.wantName)
+			}
+			if len(principal.Groups) != 1 || principal.Groups[0] != "dba" {
+				t.Errorf("got groups %v, want only the bound group dba", principal.Groups)
+			}
+		})
+	}
+}
+
+func TestGetProviderIssuerMismatch(t *testing.T) {
+	issuer := newTestIssuer(t)
+	cfg := testAuthConfig(issuer.URL + "/realms/other")
+	if _, err := getProvider(cfg.OIDC, false); err == nil {
+		t.Fatal("expected an error when discovery is served for another issuer")
+	}
+}
@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/audit"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/util"
+)
+
+// applyGrants fills in the roles, permissions and tag scope of a principal from
+// the current configuration, so that role changes apply without logging in
+// again. It returns false when the user or token behind the principal has been
+// removed from the configuration.
+func applyGrants(p *Principal, cfg models.AuthConfig) bool {
+	var grants []models.Grant
+	switch p.Method {
+	case MethodPassword:
+		user, ok := findUser(cfg, p.Name)
+		if !ok {
+			return false
+		}
+		grants = append(grants, user.Grant)
+	case MethodToken:
+		token, ok := findToken(cfg.APITokens, p.Name)
+		if !ok {
+			return false
+		}
+		grants = append(grants, token.Grant)
+	case MethodKiosk:
+		token, ok := findToken(cfg.KioskTokens, strings.TrimPrefix(p.Name, "kiosk:"))
+		if !ok {
+			return false
+		}
+		grants = append(grants, models.Grant{Roles: []string{"viewer"}, Tags: token.Tags})
+	case MethodOIDC:
+		for _, group := range cfg.OIDC.GroupRoles {
+			if containsFold(p.Groups, group.Group) {
+				grants = append(grants, group.Grant)
+			}
+		}
+	default:
+		// Principals of registered authenticators may come with their own roles.
+		grants = append(grants, models.Grant{Roles: p.Roles, Tags: p.Tags})
+	}
+
+	roles, tags, scoped := []string{}, []string{}, len(grants) > 0
+	for _, grant := range grants {
+		grantRoles := grant.Roles
+		if len(grantRoles) == 0 {
+			grantRoles = cfg.DefaultRoles
+		}
+		roles = appendNew(roles, grantRoles...)
+		if len(grant.Tags) == 0 {
+			scoped = false
+		}
+		tags = appendNew(tags, grant.Tags...)
+	}
+	if len(grants) == 0 {
+		roles = appendNew(roles, cfg.DefaultRoles...)
+	}
+
+	permissions := []string{}
+	for _, role := range roles {
+		rolePermissions, _ := cfg.RolePermissions(role)
+		permissions = appendNew(permissions, rolePermissions...)
+	}
+	sort.Strings(roles)
+	sort.Strings(permissions)
+	p.Roles, p.Permissions, p.Tags = roles, permissions, nil
+	if scoped {
+		sort.Strings(tags)
+		p.Tags = tags
+	}
+	return true
+}
+
+// Can reports whether the principal holds a permission.
+func (p *Principal) Can(permission string) bool {
+	for _, held := range p.Permissions {
+		if held == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Require rejects requests whose principal lacks the permission with a 403.
+// With auth disabled, everything is allowed.
+func Require(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !Allowed(c, permission) {
+			util.Logger.Printf("Access denied: %s lacks permission '%s' for %s %s", Actor(c), permission, c.Request.Method, c.Request.URL.Path)
+			Audit(c, audit.Record{Action: audit.ActionAccessDenied, Target: c.Request.URL.Path, Outcome: audit.OutcomeDenied,
+				Details: map[string]string{"request": c.Request.Method, "permission": permission}})
+			Abort(c, http.StatusForbidden, fmt.Sprintf("permission '%s' required", permission))
+			return
+		}
+		c.Next()
+	}
+}
+
+// Allowed reports whether the request may use a permission.
+func Allowed(c *gin.Context, permission string) bool {
+	principal := Current(c)
+	return principal == nil || principal.Can(permission)
+}
+
+// Scope returns the tags limiting which databases the request may see, or nil
+// when it may see all of them.
+func Scope(c *gin.Context) []string {
+	if principal := Current(c); principal != nil {
+		return principal.Tags
+	}
+	return nil
+}
+
+// InScope reports whether the request may see the configured database with the
+// given name. Requests without a tag scope may see every database.
+func InScope(c *gin.Context, database string) bool {
+	if Scope(c) == nil {
+		return true
+	}
+	filter := models.DatabaseFilter{Names: []string{database}, Scope: Scope(c)}
+	for _, db := range models.GetConfig().DBs {
+		if filter.MatchesDatabase(db) {
+			return true
+		}
+	}
+	return false
+}
+
+func findUser(cfg models.AuthConfig, name string) (models.LocalUser, bool) {
+	for _, user := range cfg.Users {
+		if user.Username == name {
+			return user, true
+		}
+	}
+	return models.LocalUser{}, false
+}
+
+func findToken(tokens []models.TokenConfig, name string) (models.TokenConfig, bool) {
+	for _, token := range tokens {
+		if token.Name == name {
+			return token, true
+		}
+	}
+	return models.TokenConfig{}, false
+}
+
+// appendNew appends the values not yet in list.
+func appendNew(list []string, values ...string) []string {
+	for _, value := range values {
+		if !containsFold(list, value) {
+			list = append(list, value)
+		}
+	}
+	return list
+}
+
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
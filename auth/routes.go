@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+// RegisterRoutes adds the login, logout and OIDC endpoints. The login page
+// itself is a static file served by the server package at /login.
+func RegisterRoutes(router gin.IRouter) {
+	router.POST("/auth/login", loginHandler)
+	router.POST("/auth/logout", logoutHandler)
+	router.GET("/auth/logout", logoutHandler)
+	router.GET("/auth/oidc/login", oidcLoginHandler)
+	router.GET("/auth/oidc/callback", oidcCallbackHandler)
+	router.GET("/auth/providers", providersHandler)
+	router.GET("/api/auth/me", meHandler)
+}
+
+// logoutHandler ends the session and returns to the login page.
+func logoutHandler(c *gin.Context) {
+	EndSession(c, models.GetConfig().Auth)
+	if c.Request.Method == http.MethodGet {
+		c.Redirect(http.StatusFound, BasePath()+"login")
+		return
+	}
+	c.JSON(http.StatusOK, models.ApiResponse{Code: 200, Message: "success", Timestamp: time.Now().Unix()})
+}
+
+// providersHandler tells the login page which login methods to offer.
+func providersHandler(c *gin.Context) {
+	cfg := models.GetConfig().Auth
+	data := gin.H{
+		"enabled":   cfg.Enabled,
+		"password":  len(cfg.Users) > 0,
+		"oidc":      cfg.OIDC.Issuer != "",
+		"oidc_name": cfg.OIDC.DisplayName,
+	}
+	c.JSON(http.StatusOK, models.ApiResponse{Code: 200, Data: data, Message: "success", Timestamp: time.Now().Unix()})
+}
+
+// meHandler returns the principal of the request; null when auth is disabled.
+func meHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, models.ApiResponse{Code: 200, Data: Current(c), Message: "success", Timestamp: time.Now().Unix()})
+}
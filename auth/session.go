@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/audit"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/util"
+)
+
+const sessionCookie = "drdash_session"
+
+// processKey signs cookies when no session_secret is configured, so logins
+// do not survive a restart.
+var processKey = func() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic("auth: failed to generate session key: " + err.Error())
+	}
+	return key
+}()
+
+// session is the content of the session cookie. It is signed, not encrypted,
+// and holds nothing secret.
+type session struct {
+	Name     string   `json:"n"`
+	Method   string   `json:"m"`
+	ReadOnly bool     `json:"r,omitempty"`
+	Groups   []string `json:"g,omitempty"`
+	Expires  int64    `json:"e"`
+}
+
+type sessionAuthenticator struct{}
+
+func (sessionAuthenticator) Authenticate(c *gin.Context, cfg models.AuthConfig) *Principal {
+	value, err := c.Cookie(sessionCookie)
+	if err != nil {
+		return nil
+	}
+	var s session
+	if !readSigned(cfg, value, &s) || time.Now().Unix() > s.Expires {
+		return nil
+	}
+	return &Principal{Name: s.Name, Method: s.Method, ReadOnly: s.ReadOnly, Groups: s.Groups}
+}
+
+// StartSession logs the principal in by setting the session cookie.
+func StartSession(c *gin.Context, cfg models.AuthConfig, principal *Principal) {
+	lifetime := time.Duration(cfg.SessionHours) * time.Hour
+	s := session{Name: principal.Name, Method: principal.Method, ReadOnly: principal.ReadOnly, Groups: principal.Groups, Expires: time.Now().Add(lifetime).Unix()}
+	setCookie(c, cfg, sessionCookie, writeSigned(cfg, s), int(lifetime.Seconds()))
+	util.Logger.Printf("Login: %s (%s) from %s", principal.Name, principal.Method, c.ClientIP())
+	audit.Log(audit.Record{Actor: principal.Name, Method: principal.Method, Client: c.ClientIP(), Action: audit.ActionLogin})
+}
+
+// EndSession removes the session cookie, recording the logout of a valid session.
+func EndSession(c *gin.Context, cfg models.AuthConfig) {
+	if principal := (sessionAuthenticator{}).Authenticate(c, cfg); principal != nil {
+		audit.Log(audit.Record{Actor: principal.Name, Method: principal.Method, Client: c.ClientIP(), Action: audit.ActionLogout})
+	}
+	setCookie(c, cfg, sessionCookie, "", -1)
+}
+
+// setCookie sets an HttpOnly, SameSite=Lax cookie scoped to the public base path.
+// Lax keeps the cookie on the top-level redirect back from the OIDC provider.
+func setCookie(c *gin.Context, cfg models.AuthConfig, name, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     BasePath(),
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   cfg.SecureCookies || c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https"),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// signingKey derives the cookie signing key from session_secret.
+func signingKey(cfg models.AuthConfig) []byte {
+	if cfg.SessionSecret == "" {
+		return processKey
+	}
+	sum := sha256.Sum256([]byte(cfg.SessionSecret))
+	return sum[:]
+}
+
+// writeSigned encodes v as base64url JSON followed by its HMAC-SHA256.
+func writeSigned(cfg models.AuthConfig, v interface{}) string {
+	payload, _ := json.Marshal(v)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, signingKey(cfg))
+	mac.Write([]byte(encoded))
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// readSigned verifies a value written by writeSigned and decodes it into v.
+func readSigned(cfg models.AuthConfig, value string, v interface{}) bool {
+	encoded, signature, ok := strings.Cut(value, ".")
+	if !ok {
+		return false
+	}
+	mac := hmac.New(sha256.New, signingKey(cfg))
+	mac.Write([]byte(encoded))
+	expected := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	return err == nil && json.Unmarshal(payload, v) == nil
+}
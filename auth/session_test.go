@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tamper decodes the payload of a value written by writeSigned, lets edit
+// change it and re-encodes it, keeping the original signature.
+func tamper(value string, edit func(map[string]interface{})) string {
+	encoded, signature, _ := strings.Cut(value, ".")
+	payload, _ := base64.RawURLEncoding.DecodeString(encoded)
+	var fields map[string]interface{}
+	json.Unmarshal(payload, &fields)
+	edit(fields)
+	payload, _ = json.Marshal(fields)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + signature
+}
+
+// sessionContext returns a request context carrying the session cookie value.
+func sessionContext(value string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/data", nil)
+	if value != "" {
+		c.Request.AddCookie(&http.Cookie{Name: sessionCookie, Value: value})
+	}
+	return c
+}
+
+func TestSessionRoundTrip(t *testing.T) {
+	cfg := testAuthConfig("")
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodPost, "/auth/login", nil)
+	StartSession(c, cfg, &Principal{Name: "alice", Method: MethodOIDC, Groups: []string{"dba"}})
+
+	var value string
+	for _, cookie := range recorder.Result().Cookies() {
+		if cookie.Name == sessionCookie {
+			value = cookie.Value
+			if !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode {
+				t.Errorf("session cookie is not HttpOnly with SameSite=Lax: %+v", cookie)
+			}
+		}
+	}
+	if value == "" {
+		t.Fatal("StartSession did not set the session cookie")
+	}
+	principal := (sessionAuthenticator{}).Authenticate(sessionContext(value), cfg)
+	if principal == nil || principal.Name != "alice" || principal.Method != MethodOIDC || len(principal.Groups) != 1 {
+		t.Fatalf("got principal %+v, want alice (oidc) in group dba", principal)
+	}
+}
+
+func TestSessionRejected(t *testing.T) {
+	cfg := testAuthConfig("")
+	valid := writeSigned(cfg, session{Name: "alice", Method: MethodPassword, ReadOnly: true, Expires: time.Now().Add(time.Hour).Unix()})
+	otherSecret := cfg
+	otherSecret.SessionSecret = "other-secret"
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "renamed user", value: tamper(valid, func(s map[string]interface{}) { s["n"] = "admin" })},
+		{name: "read-only flag removed", value: tamper(valid, func(s map[string]interface{}) { delete(s, "r") })},
+		{name: "extended expiry", value: tamper(valid, func(s map[string]interface{}) { s["e"] = time.Now().Add(time.Hour * 24 * 365).Unix() })},
+		{name: "signed with another secret", value: writeSigned(otherSecret, session{Name: "alice", Expires: time.Now().Add(time.Hour).Unix()})},
+		{name: "expired", value: writeSigned(cfg, session{Name: "alice", Expires: time.Now().Add(-time.Second).Unix()})},
+		{name: "no signature", value: strings.SplitN(valid, ".", 2)[0]},
+		{name: "missing", value: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if principal := (sessionAuthenticator{}).Authenticate(sessionContext(tt.value), cfg); principal != nil {
+				t.Fatalf("accepted session for %+v", principal)
+			}
+		})
+	}
+	if (sessionAuthenticator{}).Authenticate(sessionContext(valid), cfg) == nil {
+		t.Fatal("rejected the untampered session")
+	}
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+// kioskParam is the URL parameter carrying a kiosk token, e.g. /?kiosk_token=...&lang=en.
+// It is separate from the "kiosk" parameter that turns on kiosk pagination.
+const kioskParam = "kiosk_token"
+
+// tokenAuthenticator accepts API tokens sent as "Authorization: Bearer <token>" or "X-API-Token".
+type tokenAuthenticator struct{}
+
+func (tokenAuthenticator) Authenticate(c *gin.Context, cfg models.AuthConfig) *Principal {
+	token := c.GetHeader("X-API-Token")
+	if header := c.GetHeader("Authorization"); token == "" && strings.HasPrefix(header, "Bearer ") {
+		token = strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+	}
+	if name, ok := matchToken(cfg.APITokens, token); ok {
+		return &Principal{Name: name, Method: MethodToken}
+	}
+	return nil
+}
+
+// kioskAuthenticator accepts read-only kiosk tokens from the URL, so that wall
+// screens can open the dashboard without anyone logging in.
+type kioskAuthenticator struct{}
+
+func (kioskAuthenticator) Authenticate(c *gin.Context, cfg models.AuthConfig) *Principal {
+	if name, ok := matchToken(cfg.KioskTokens, c.Query(kioskParam)); ok {
+		return &Principal{Name: "kiosk:" + name, Method: MethodKiosk, ReadOnly: true}
+	}
+	return nil
+}
+
+// matchToken returns the name of the configured token equal to token, comparing in constant time.
+func matchToken(tokens []models.TokenConfig, token string) (string, bool) {
+	if token == "" {
+		return "", false
+	}
+	for _, candidate := range tokens {
+		if subtle.ConstantTimeCompare([]byte(candidate.Token), []byte(token)) == 1 {
+			return candidate.Name, true
+		}
+	}
+	return "", false
+}
+
+// RedactKioskToken replaces the kiosk token in a request URI, so that the URI
+// can be written to access logs.
+func RedactKioskToken(uri string) string {
+	path, query, ok := strings.Cut(uri, "?")
+	if !ok {
+		return uri
+	}
+	params := strings.Split(query, "&")
+	for i, param := range params {
+		key, _, _ := strings.Cut(param, "=")
+		if key, err := url.QueryUnescape(key); err == nil && key == kioskParam {
+			params[i] = kioskParam + "=REDACTED"
+		}
+	}
+	return path + "?" + strings.Join(params, "&")
+}
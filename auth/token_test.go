@@ -0,0 +1,22 @@
+package auth
+
+import "testing"
+
+func TestRedactKioskToken(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{uri: "/", want: "/"},
+		{uri: "/api/data?group=ERP", want: "/api/data?group=ERP"},
+		{uri: "/?kiosk_token=s3cret&kiosk=1", want: "/?kiosk_token=REDACTED&kiosk=1"},
+		{uri: "/api/data?lang=en&kiosk_token=s3cret", want: "/api/data?lang=en&kiosk_token=REDACTED"},
+		{uri: "/?kiosk%5Ftoken=s3cret", want: "/?kiosk_token=REDACTED"},
+		{uri: "/?kiosk=1&kiosk_tokens=x", want: "/?kiosk=1&kiosk_tokens=x"},
+	}
+	for _, tt := range tests {
+		if got := RedactKioskToken(tt.uri); got != tt.want {
+			t.Errorf("RedactKioskToken(%q) = %q, want %q", tt.uri, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/handlers"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/history"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+// Exit codes of the "check" subcommand, as defined for Nagios and Icinga plugins.
+const (
+	exitOK       = 0
+	exitWarning  = 1
+	exitCritical = 2
+	exitUnknown  = 3
+)
+
+// checkResult is what the "check" subcommand prints as JSON or YAML.
+type checkResult struct {
+	CheckedAt time.Time               `json:"checked_at"`
+	Level     string                  `json:"level"` // Worst health of the checked databases
+	Databases []models.DatabaseStatus `json:"databases"`
+}
+
+// runCheck implements the "check" subcommand: it runs the status checks once
+// and prints the result. The exit code follows the Nagios plugin convention in
+// every format: 0 when all databases are OK or in maintenance, 1 on warnings,
+// 2 on critical findings and 3 when the state is unknown or the check failed.
+func runCheck(configFile string, args []string) int {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	format := fs.String("format", "table", "Output format: table, json, yaml or nagios")
+	dbNames := fs.String("db", "", "Comma-separated list of database names to check")
+	group := fs.String("group", "", "Only check databases in this group")
+	tags := fs.String("tag", "", "Only check databases with all of these comma-separated tags")
+	verbose := fs.Bool("verbose", false, "Show log output while checking")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s check:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *format != "table" && *format != "json" && *format != "yaml" && *format != "nagios" {
+		fmt.Fprintf(os.Stderr, "Unsupported format '%s' (expected table, json, yaml or nagios)\n", *format)
+		return exitUnknown
+	}
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	if err := models.LoadConfig(configFile); err != nil {
+		if *format == "nagios" {
+			fmt.Printf("DR UNKNOWN - failed to load configuration: %v\n", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		}
+		return exitUnknown
+	}
+	// The history is shared with a running dashboard, which owns it: this
+	// check reads its state but records nothing.
+	store, err := history.OpenReadOnly(models.GetConfig().History.Dir)
+	if err != nil {
+		if *format == "nagios" {
+			fmt.Printf("DR UNKNOWN - failed to read check history: %v\n", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Failed to read check history: %v\n", err)
+		}
+		return exitUnknown
+	}
+	handlers.UseHistory(store)
+
+	filter := models.DatabaseFilter{Names: splitList(*dbNames), Group: *group, Tags: splitList(*tags)}
+	result := checkResult{CheckedAt: time.Now(), Level: models.HealthOK}
+	result.Databases = handlers.GetDatabaseStatus(filter)
+	if len(result.Databases) == 0 {
+		result.Level = models.HealthUnknown
+	}
+	for _, status := range result.Databases {
+		if models.HealthSeverity(status.Health.Level) > models.HealthSeverity(result.Level) ||
+			(result.Level == models.HealthOK && status.Health.Level == models.HealthMaintenance) {
+			result.Level = status.Health.Level
+		}
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(result)
+	case "yaml":
+		if err := printYAML(result); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to print YAML: %v\n", err)
+			return exitUnknown
+		}
+	case "nagios":
+		printNagios(result)
+	default:
+		printCheck(result)
+	}
+
+	switch result.Level {
+	case models.HealthOK, models.HealthMaintenance:
+		return exitOK
+	case models.HealthWarning:
+		return exitWarning
+	case models.HealthCritical:
+		return exitCritical
+	default:
+		return exitUnknown
+	}
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// printCheck writes the statuses as a human-readable table.
+func printCheck(result checkResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DATABASE\tGROUP\tHEALTH\tPRODUCTION\tDR\tLB TARGET\tLAG\tCONNECTIONS\tREASONS")
+	for _, status := range result.Databases {
+		lag := "-"
+		if seconds, ok := standbyLag(status); ok {
+			lag = strconv.FormatFloat(seconds, 'f', 0, 64) + "s"
+		}
+		connections := "-"
+		if status.Connections >= 0 {
+			connections = strconv.Itoa(status.Connections)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s/%s\t%s/%s\t%s\t%s\t%s\t%s\n",
+			status.Name, orDash(status.Group), status.Health.Level,
+			status.ProductionRole, status.ProductionStatus, status.DisasterRole, status.DisasterStatus,
+			status.LoadBalancerTarget, lag, connections, orDash(strings.Join(reasonList(status.Health), "; ")))
+	}
+	w.Flush()
+	fmt.Printf("\nOverall: %s\n", result.Level)
+}
+
+// printYAML writes the result with the field names of the JSON API: it is
+// encoded as JSON first and re-encoded in block style.
+func printYAML(result checkResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// blockStyle clears the flow and quoting styles the JSON input left on the nodes.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// printNagios writes the plugin output: a status line with performance data
+// for the standby lag and the primary connections, then one line per database.
+func printNagios(result checkResult) {
+	cfg := models.GetConfig().Health
+	counts := make(map[string]int)
+	var problems, perfdata []string
+	for _, status := range result.Databases {
+		counts[status.Health.Level]++
+		if models.HealthSeverity(status.Health.Level) > 0 {
+			problems = append(problems, fmt.Sprintf("%s %s", status.Name, status.Health.Level))
+		}
+		lag, connections := "U", "U"
+		if seconds, ok := standbyLag(status); ok {
+			lag = strconv.FormatFloat(seconds, 'f', 0, 64) + "s"
+		}
+		if status.Connections >= 0 {
+			connections = strconv.Itoa(status.Connections)
+		}
+		perfdata = append(perfdata,
+			fmt.Sprintf("%s=%s;%d;%d;0", perfLabel(status.Name+"_lag"), lag, cfg.LagWarningSeconds, cfg.LagCriticalSeconds),
+			fmt.Sprintf("%s=%s;;;0", perfLabel(status.Name+"_connections"), connections))
+	}
+
+	summary := "no databases matched"
+	if len(result.Databases) > 0 {
+		summary = fmt.Sprintf("%d databases: %d OK, %d warning, %d critical, %d unknown, %d in maintenance",
+			len(result.Databases), counts[models.HealthOK], counts[models.HealthWarning], counts[models.HealthCritical],
+			len(result.Databases)-counts[models.HealthOK]-counts[models.HealthWarning]-counts[models.HealthCritical]-counts[models.HealthMaintenance],
+			counts[models.HealthMaintenance])
+	}
+	if len(problems) > 0 {
+		summary += " (" + strings.Join(problems, ", ") + ")"
+	}
+	// The state word must match the exit code, so maintenance is reported as OK.
+	state := result.Level
+	if state == models.HealthMaintenance {
+		state = models.HealthOK
+	}
+	fmt.Printf("DR %s - %s | %s\n", state, summary, strings.Join(perfdata, " "))
+	for _, status := range result.Databases {
+		fmt.Printf("%s: %s", status.Name, status.Health.Level)
+		if reasons := reasonList(status.Health); len(reasons) > 0 {
+			fmt.Printf(" - %s", strings.Join(reasons, "; "))
+		}
+		fmt.Println()
+	}
+}
+
+// perfLabel quotes a performance data label if it contains characters that
+// would end it; single quotes are not allowed inside labels.
+func perfLabel(label string) string {
+	label = strings.ReplaceAll(label, "'", "_")
+	label = strings.ReplaceAll(label, "=", "_")
+	if strings.ContainsAny(label, " \t") {
+		return "'" + label + "'"
+	}
+	return label
+}
+
+// standbyLag returns the lag of the standby member in seconds as recorded in
+// the history: the larger of the Data Guard and the heartbeat lag. ok is false
+// when the lag is unknown.
+func standbyLag(status models.DatabaseStatus) (seconds float64, ok bool) {
+	sample := history.NewSample(status, time.Now())
+	standby := sample.Disaster
+	if sample.Disaster.Role == "PRIMARY" && sample.Disaster.Connected {
+		standby = sample.Production
+	}
+	if !standby.Connected || standby.Lag < 0 {
+		return 0, false
+	}
+	return standby.Lag, true
+}
+
+// reasonList returns the reasons of a verdict as "CODE (member)", marking acknowledged ones.
+func reasonList(verdict models.HealthVerdict) []string {
+	reasons := make([]string, 0, len(verdict.Reasons))
+	for _, reason := range verdict.Reasons {
+		text := reason.Code
+		if reason.Member != "" {
+			text += " (" + reason.Member + ")"
+		}
+		if reason.Acknowledged {
+			text += " [ack]"
+		}
+		reasons = append(reasons, text)
+	}
+	return reasons
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/history"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+// writeCheckConfig writes a configuration with one database whose members all
+// refuse connections, keeping its history in historyDir.
+func writeCheckConfig(t *testing.T, historyDir string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	config := fmt.Sprintf(`history:
+  dir: %q
+databases:
+  - name: "ERP_DB"
+    lb_ip: "127.0.0.1"
+    prod_ip: "127.0.0.1"
+    dr_ip: "127.0.0.1"
+    port: %d
+    service_name: "ERPPDB"
+    username: "monitor_user"
+    password: "secret"
+    check_mode: "tcp-only"
+`, historyDir, port)
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func TestRunCheckReadsPersistedMaintenance(t *testing.T) {
+	historyDir := t.TempDir()
+	configFile := writeCheckConfig(t, historyDir)
+
+	// A window added through the API of a running dashboard is only in its state document.
+	store, err := history.Open(historyDir, 1)
+	if err != nil {
+		t.Fatalf("open history: %v", err)
+	}
+	start, end := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	state := map[string]interface{}{"windows": []models.MaintenanceWindow{{
+		ID: "mw-1", Database: "ERP_DB", Start: &start, End: &end, Reason: "patching", Owner: "dba", Source: models.MaintenanceFromAPI,
+	}}}
+	if err := store.SaveState("maintenance", state); err != nil {
+		t.Fatalf("save maintenance state: %v", err)
+	}
+	before, _ := os.ReadFile(filepath.Join(historyDir, "maintenance.state.json"))
+
+	output, err := os.Create(filepath.Join(t.TempDir(), "check.json"))
+	if err != nil {
+		t.Fatalf("create output: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = output
+	code := runCheck(configFile, []string{"-format", "json"})
+	os.Stdout = stdout
+	output.Close()
+
+	data, _ := os.ReadFile(output.Name())
+	var result checkResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("parse check output: %v\n%s", err, data)
+	}
+	if code != exitOK || result.Level != models.HealthMaintenance {
+		t.Fatalf("got exit code %d and level %s during a persisted maintenance window, want %d and MAINTENANCE", code, result.Level, exitOK)
+	}
+	if len(result.Databases) != 1 || len(result.Databases[0].Health.Reasons) == 0 {
+		t.Fatalf("expected the unreachable database to keep its reasons under maintenance: %s", data)
+	}
+
+	after, _ := os.ReadFile(filepath.Join(historyDir, "maintenance.state.json"))
+	if string(after) != string(before) {
+		t.Error("check changed the maintenance state of the dashboard")
+	}
+	if files, _ := filepath.Glob(filepath.Join(historyDir, "*.jsonl")); len(files) > 0 {
+		t.Errorf("check recorded history: %v", files)
+	}
+}
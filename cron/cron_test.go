@@ -0,0 +1,111 @@
+package cron
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// at returns a UTC time in 2026; 2026-01-01 is a Thursday.
+func at(month time.Month, day, hour, minute int) time.Time {
+	return time.Date(2026, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		expr  string
+		after time.Time
+		want  []time.Time // Consecutive firing times
+	}{
+		// Single values, lists, ranges and steps
+		{expr: "30 22 * * *", after: at(1, 1, 23, 0), want: []time.Time{at(1, 2, 22, 30), at(1, 3, 22, 30)}},
+		{expr: "0,15,45 8 * * *", after: at(1, 1, 8, 0), want: []time.Time{at(1, 1, 8, 15), at(1, 1, 8, 45), at(1, 2, 8, 0)}},
+		{expr: "0 9-11 * * *", after: at(1, 1, 10, 30), want: []time.Time{at(1, 1, 11, 0), at(1, 2, 9, 0)}},
+		{expr: "*/20 * * * *", after: at(1, 1, 8, 41), want: []time.Time{at(1, 1, 9, 0), at(1, 1, 9, 20)}},
+		{expr: "5/30 * * * *", after: at(1, 1, 8, 0), want: []time.Time{at(1, 1, 8, 5), at(1, 1, 8, 35), at(1, 1, 9, 5)}},
+		{expr: "0 8-18/4 * * *", after: at(1, 1, 9, 0), want: []time.Time{at(1, 1, 12, 0), at(1, 1, 16, 0), at(1, 2, 8, 0)}},
+		// Names and 7 as Sunday
+		{expr: "0 2 * * SUN", after: at(1, 1, 0, 0), want: []time.Time{at(1, 4, 2, 0), at(1, 11, 2, 0)}},
+		{expr: "0 2 * * 7", after: at(1, 1, 0, 0), want: []time.Time{at(1, 4, 2, 0), at(1, 11, 2, 0)}},
+		{expr: "0 2 * * 0", after: at(1, 1, 0, 0), want: []time.Time{at(1, 4, 2, 0)}},
+		{expr: "0 6 * * mon-fri", after: at(1, 2, 7, 0), want: []time.Time{at(1, 5, 6, 0), at(1, 6, 6, 0)}},
+		{expr: "0 6 * * FRI-7", after: at(1, 2, 7, 0), want: []time.Time{at(1, 3, 6, 0), at(1, 4, 6, 0), at(1, 9, 6, 0)}},
+		{expr: "0 0 1 JAN,jul *", after: at(1, 1, 0, 0), want: []time.Time{at(7, 1, 0, 0), time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		// Macros
+		{expr: "@hourly", after: at(1, 1, 8, 59), want: []time.Time{at(1, 1, 9, 0), at(1, 1, 10, 0)}},
+		{expr: "@daily", after: at(1, 1, 8, 0), want: []time.Time{at(1, 2, 0, 0)}},
+		{expr: "@midnight", after: at(1, 1, 8, 0), want: []time.Time{at(1, 2, 0, 0)}},
+		{expr: "@weekly", after: at(1, 1, 8, 0), want: []time.Time{at(1, 4, 0, 0), at(1, 11, 0, 0)}},
+		{expr: "@monthly", after: at(1, 15, 8, 0), want: []time.Time{at(2, 1, 0, 0), at(3, 1, 0, 0)}},
+		{expr: "@YEARLY", after: at(1, 15, 8, 0), want: []time.Time{time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		// Both day fields restricted: either matches.
+		{expr: "0 8 13 * FRI", after: at(2, 1, 0, 0), want: []time.Time{at(2, 6, 8, 0), at(2, 13, 8, 0), at(2, 20, 8, 0)}},
+		// A day field starting with "*" is unrestricted: both must match.
+		{expr: "0 8 */2 * 1", after: at(1, 1, 0, 0), want: []time.Time{at(1, 5, 8, 0), at(1, 19, 8, 0), at(2, 9, 8, 0)}},
+		{expr: "0 8 13 * *", after: at(2, 1, 0, 0), want: []time.Time{at(2, 13, 8, 0), at(3, 13, 8, 0)}},
+		{expr: "0 8 1-7 * */7", after: at(1, 1, 0, 0), want: []time.Time{at(1, 4, 8, 0), at(2, 1, 8, 0)}},
+		// Months without the day are skipped.
+		{expr: "0 0 31 * *", after: at(1, 31, 0, 0), want: []time.Time{at(3, 31, 0, 0), at(5, 31, 0, 0)}},
+		{expr: "0 0 29 2 *", after: at(1, 1, 0, 0), want: []time.Time{time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)}},
+		// February 29 that is a Sunday: 2032.
+		{expr: "0 0 29 2 */7", after: at(1, 1, 0, 0), want: []time.Time{time.Date(2032, 2, 29, 0, 0, 0, 0, time.UTC)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			next := tt.after
+			for _, want := range tt.want {
+				next = s.Next(next)
+				if !next.Equal(want) {
+					t.Fatalf("got %s, want %s", next.Format(time.RFC3339+" Mon"), want.Format(time.RFC3339+" Mon"))
+				}
+			}
+		})
+	}
+}
+
+func TestNextKeepsLocation(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	s, err := Parse("0 2 * * *")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	next := s.Next(time.Date(2026, 1, 1, 3, 0, 0, 0, tokyo))
+	if want := time.Date(2026, 1, 2, 2, 0, 0, 0, tokyo); !next.Equal(want) || next.Location() != tokyo {
+		t.Errorf("got %s, want %s", next, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{expr: "0 2 * *", wantErr: "must have 5 fields"},
+		{expr: "@reboot", wantErr: "must have 5 fields"},
+		{expr: "60 * * * *", wantErr: "minute"},
+		{expr: "0 24 * * *", wantErr: "hour"},
+		{expr: "0 0 0 * *", wantErr: "day of month"},
+		{expr: "0 0 32 * *", wantErr: "day of month"},
+		{expr: "0 0 * 13 *", wantErr: "month"},
+		{expr: "0 0 * * 8", wantErr: "day of week"},
+		{expr: "0 0 * * MON-SUNDAY", wantErr: "invalid value"},
+		{expr: "0 0 * * 5-1", wantErr: "outside"},
+		{expr: "*/0 * * * *", wantErr: "invalid step"},
+		{expr: "*/x * * * *", wantErr: "invalid step"},
+		{expr: "a * * * *", wantErr: "invalid value"},
+		{expr: "0 0 31 2 *", wantErr: "never fires"},
+		{expr: "0 0 30,31 FEB *", wantErr: "never fires"},
+		{expr: "0 0 31 4,6,9,11 *", wantErr: "never fires"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if _, err := Parse(tt.expr); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/handlers"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+// runDiagnose implements the "diagnose" subcommand: it runs the monitoring
+// account self-check once and prints the result. The exit code is 0 when
+// everything is OK, 1 on warnings and 2 on critical findings or errors.
+func runDiagnose(configFile string, args []string) int {
+	fs := flag.NewFlagSet("diagnose", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "Print the report as JSON")
+	dbNames := fs.String("db", "", "Comma-separated list of database names to check")
+	group := fs.String("group", "", "Only check databases in this group")
+	verbose := fs.Bool("verbose", false, "Show log output while checking")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s diagnose:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	if err := models.LoadConfig(configFile); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		return 2
+	}
+
+	filter := models.DatabaseFilter{Names: splitList(*dbNames), Group: *group}
+
+	report := handlers.RunDiagnostics(filter)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else {
+		printDiagnostics(report)
+	}
+
+	switch report.Verdict.Level {
+	case models.HealthOK:
+		return 0
+	case models.HealthWarning:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// printDiagnostics writes the report as a human-readable table.
+func printDiagnostics(report models.DiagnosticsReport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DATABASE\tMEMBER\tADDRESS\tLEVEL\tACCOUNT\tEXPIRES\tFINDINGS")
+	for _, db := range report.Databases {
+		for _, member := range db.Members {
+			account, expires := "-", "-"
+			if member.Account != nil {
+				account = member.Account.AccountStatus
+				expires = "never"
+				if member.Account.ExpiryDate != nil {
+					expires = fmt.Sprintf("%s (%dd)", member.Account.ExpiryDate.Format("2006-01-02"), *member.Account.DaysUntilExpiry)
+				}
+			}
+
+			var findings []string
+			for _, reason := range member.Verdict.Reasons {
+				findings = append(findings, reason.Code)
+			}
+			for _, check := range member.Privileges {
+				if !check.Granted {
+					findings = append(findings, "no access to "+check.Object)
+				}
+			}
+			if member.Error != nil {
+				findings = append(findings, member.Error.Message)
+			}
+			if len(findings) == 0 {
+				findings = append(findings, "-")
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				db.Name, member.Member, member.Address, member.Verdict.Level, account, expires, strings.Join(findings, "; "))
+		}
+	}
+	w.Flush()
+	fmt.Printf("\nOverall: %s\n", report.Verdict.Level)
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/util"
+)
+
+// backupCache avoids reading V$RMAN_BACKUP_JOB_DETAILS on every cycle.
+var backupCache memberCache[[]models.BackupJob]
+
+// fetchBackupJobs returns the backup jobs of a member, re-reading them only when
+// the cached copy is older than backup.check_interval_minutes.
+func fetchBackupJobs(oraDB *util.OracleDB, dbConfig models.DatabaseConfig, ip, instanceType string) ([]models.BackupJob, *models.MemberError) {
+	cfg := models.GetConfig().Backup
+	maxAge := time.Duration(cfg.CheckIntervalMinutes) * time.Minute
+	return backupCache.get(dbConfig.Name+"|"+ip, maxAge, func() ([]models.BackupJob, *models.MemberError) {
+		jobs, err := oraDB.GetBackupJobs(cfg.LookbackDays)
+		if err != nil {
+			log.Printf("Warning: Failed to get RMAN backup jobs for %s %s (%s:%d): %v", instanceType, dbConfig.Name, ip, dbConfig.Port, err)
+			return nil, util.ClassifyOracleError(err, models.StageBackup)
+		}
+		return jobs, nil
+	})
+}
+
+// backupType maps an RMAN INPUT_TYPE to the backup type it counts for, or "" if it is not tracked.
+func backupType(inputType string) string {
+	switch strings.ToUpper(inputType) {
+	case "DB FULL":
+		return models.BackupFull
+	case "DB INCR":
+		return models.BackupIncremental
+	case "ARCHIVELOG":
+		return models.BackupArchivelog
+	}
+	return ""
+}
+
+// backupSucceeded reports whether a finished job produced a usable backup.
+func backupSucceeded(status string) bool {
+	return status == "COMPLETED" || status == "COMPLETED WITH WARNINGS"
+}
+
+// backupFailed reports whether a job finished without a usable backup.
+func backupFailed(status string) bool {
+	return status == "FAILED" || status == "COMPLETED WITH ERRORS"
+}
+
+// summarizeBackups finds the latest successful and failed backup of each type
+// across both members and compares the age of the last success with its SLA.
+func summarizeBackups(prodJobs, drJobs []models.BackupJob, cfg models.BackupConfig, now time.Time) *models.BackupStatus {
+	slas := []struct {
+		backupType string
+		hours      float64
+	}{
+		{models.BackupFull, cfg.FullSLAHours},
+		{models.BackupIncremental, cfg.IncrementalSLAHours},
+		{models.BackupArchivelog, cfg.ArchivelogSLAHours},
+	}
+
+	latest := make(map[string]*models.BackupTypeStatus)
+	result := &models.BackupStatus{Types: make([]models.BackupTypeStatus, 0, len(slas))}
+	for _, sla := range slas {
+		latest[sla.backupType] = &models.BackupTypeStatus{Type: sla.backupType, SLAHours: sla.hours}
+	}
+
+	consider := func(jobs []models.BackupJob, member string) {
+		for i := range jobs {
+			job := jobs[i]
+			job.Member = member
+			typeStatus, ok := latest[backupType(job.InputType)]
+			if !ok || job.EndTime == nil {
+				continue
+			}
+			switch {
+			case backupSucceeded(job.Status) && (typeStatus.LastSuccess == nil || job.EndTime.After(*typeStatus.LastSuccess.EndTime)):
+				typeStatus.LastSuccess = &job
+			case backupFailed(job.Status) && (typeStatus.LastFailure == nil || job.EndTime.After(*typeStatus.LastFailure.EndTime)):
+				typeStatus.LastFailure = &job
+			}
+		}
+	}
+	consider(prodJobs, models.MemberProduction)
+	consider(drJobs, models.MemberDisaster)
+
+	for _, sla := range slas {
+		typeStatus := latest[sla.backupType]
+		if typeStatus.LastFailure != nil && typeStatus.LastSuccess != nil && typeStatus.LastFailure.EndTime.Before(*typeStatus.LastSuccess.EndTime) {
+			typeStatus.LastFailure = nil // Superseded by a later success
+		}
+		if typeStatus.LastSuccess != nil {
+			age := now.Sub(*typeStatus.LastSuccess.EndTime).Hours()
+			typeStatus.AgeHours = &age
+		}
+		if sla.hours > 0 {
+			typeStatus.Overdue = typeStatus.AgeHours == nil || *typeStatus.AgeHours > sla.hours
+		}
+		result.Types = append(result.Types, *typeStatus)
+	}
+	return result
+}
+
+// evaluateBackups raises findings for overdue and failed backups.
+func evaluateBackups(backup *models.BackupStatus, verdict *models.HealthVerdict) {
+	if backup == nil {
+		return
+	}
+	// Without any member listing its jobs, the backup state is simply unknown.
+	if len(backup.Types) == 0 {
+		verdict.Raise(models.HealthWarning, "BACKUP_STATUS_UNKNOWN", "")
+		return
+	}
+	for _, typeStatus := range backup.Types {
+		if typeStatus.Overdue {
+			verdict.Raise(models.HealthWarning, "BACKUP_OVERDUE_"+typeStatus.Type, "")
+		}
+		if typeStatus.LastFailure != nil {
+			verdict.Raise(models.HealthWarning, "BACKUP_FAILED_"+typeStatus.Type, typeStatus.LastFailure.Member)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+// memberCache keeps the result of a slow-changing per-member query, so that it
+// is not re-read on every check cycle. Keys combine database name and member address.
+type memberCache[T any] struct {
+	mu      sync.Mutex
+	entries map[string]memberCacheEntry[T]
+}
+
+type memberCacheEntry[T any] struct {
+	value     T
+	err       *models.MemberError
+	fetchedAt time.Time
+}
+
+// get returns the cached result for key, calling fetch when it is missing or older than maxAge.
+func (c *memberCache[T]) get(key string, maxAge time.Duration, fetch func() (T, *models.MemberError)) (T, *models.MemberError) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && time.Since(entry.fetchedAt) < maxAge {
+		return entry.value, entry.err
+	}
+
+	entry = memberCacheEntry[T]{fetchedAt: time.Now()}
+	entry.value, entry.err = fetch()
+
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = make(map[string]memberCacheEntry[T])
+	}
+	c.entries[key] = entry
+	c.mu.Unlock()
+	return entry.value, entry.err
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/history"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+var (
+	snapshotLock sync.RWMutex
+	snapshot     []models.DatabaseStatus
+	snapshotTime time.Time
+
+	// historyStore receives the samples of every collection cycle and holds the
+	// persisted state; nil when no history is used at all.
+	historyStore *history.Store
+	collectNow   = make(chan struct{}, 1)
+)
+
+// StartCollector checks all databases every server.refresh_interval seconds in
+// the background, keeps the latest results for the API and records them in the
+// history store.
+func StartCollector(store *history.Store) {
+	UseHistory(store)
+	go func() {
+		for {
+			collect()
+			interval := time.Duration(models.GetConfig().Server.RefreshInterval) * time.Second
+			select {
+			case <-time.After(interval):
+			case <-collectNow:
+			}
+		}
+	}()
+}
+
+// UseHistory makes the checks read maintenance windows, acknowledgements,
+// NOLOGGING records and space trends from store without starting the
+// collector, e.g. for one-shot command line checks. Call it before the first check.
+func UseHistory(store *history.Store) {
+	historyStore = store
+}
+
+// TriggerCollection starts the next collection cycle right away, e.g. after the
+// configuration was reloaded. It does nothing if a trigger is already pending.
+func TriggerCollection() {
+	select {
+	case collectNow <- struct{}{}:
+	default:
+	}
+}
+
+// LatestStatus returns the statuses of the last collection cycle that match the
+// filter, and when that cycle started. ok is false until the first cycle completed.
+func LatestStatus(filter models.DatabaseFilter) (statuses []models.DatabaseStatus, collectedAt time.Time, ok bool) {
+	snapshotLock.RLock()
+	defer snapshotLock.RUnlock()
+	if snapshot == nil {
+		return nil, time.Time{}, false
+	}
+	statuses = make([]models.DatabaseStatus, 0, len(snapshot))
+	for _, status := range snapshot {
+		if filter.Matches(status.Name, status.Group, status.Tags) {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses, snapshotTime, true
+}
+
+// collect runs one check cycle over all configured databases.
+func collect() {
+	started := time.Now()
+	statuses := GetAllDatabaseStatus()
+
+	snapshotLock.Lock()
+	snapshot, snapshotTime = statuses, started
+	snapshotLock.Unlock()
+
+	trackMaintenance(statuses, started)
+	trackOperatorState(statuses, started)
+	if historyStore == nil {
+		return
+	}
+	samples := make([]history.Sample, len(statuses))
+	for i, status := range statuses {
+		samples[i] = history.NewSample(status, started)
+	}
+	if err := historyStore.Record(samples); err != nil {
+		log.Printf("Warning: Failed to record check history: %v", err)
+	}
+}
+
+// recordEvent stores an event in the history store, if the collector is running.
+func recordEvent(event history.Event) {
+	if historyStore == nil {
+		return
+	}
+	if err := historyStore.RecordEvent(event); err != nil {
+		log.Printf("Warning: Failed to record %s event for %s: %v", event.Type, event.Database, err)
+	}
+}
+
+// updateSnapshot changes the latest status of a database in place and re-judges
+// its health, so that actions such as acknowledgements show before the next cycle.
+func updateSnapshot(dbName string, update func(*models.DatabaseStatus)) {
+	snapshotLock.Lock()
+	defer snapshotLock.Unlock()
+	for i := range snapshot {
+		if snapshot[i].Name == dbName {
+			update(&snapshot[i])
+			ApplyHealth(&snapshot[i], models.GetConfig().Health)
+		}
+	}
+}
@@ -1,24 +1,42 @@
 package handlers
 
 import (
+	"errors"
+
 	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
 	"github.com/goodwaysIT/go-oracle-dr-dashboard/util"
 	"log"
+	"strings"
 	"sync"
 	"time"
 )
 
 // GetAllDatabaseStatus retrieves the status of all configured databases.
 func GetAllDatabaseStatus() []models.DatabaseStatus {
+	return GetDatabaseStatus(models.DatabaseFilter{})
+}
+
+// GetDatabaseStatus retrieves the status of the configured databases matching the filter.
+// Databases that do not match are not checked at all.
+func GetDatabaseStatus(filter models.DatabaseFilter) []models.DatabaseStatus {
 	currentConfig := models.GetConfig()
-	statusList := make([]models.DatabaseStatus, len(currentConfig.DBs))
+	dbs := make([]models.DatabaseConfig, 0, len(currentConfig.DBs))
+	for _, db := range currentConfig.DBs {
+		if filter.MatchesDatabase(db) {
+			dbs = append(dbs, db)
+		}
+	}
+
+	statusList := make([]models.DatabaseStatus, len(dbs))
 	var wg sync.WaitGroup
 
-	for i, db := range currentConfig.DBs {
+	for i, db := range dbs {
 		wg.Add(1)
 		go func(idx int, dbConfig models.DatabaseConfig) {
 			defer wg.Done()
 			statusList[idx] = checkDatabaseSystem(dbConfig)
+			estimateSpaceTrend(&statusList[idx])
+			ApplyHealth(&statusList[idx], currentConfig.Health)
 		}(i, db)
 	}
 	wg.Wait()
@@ -37,32 +55,35 @@ func checkOracleInstanceDetailed(instanceIP string, dbConfig models.DatabaseConf
 		Connections: -1,
 	}
 
-	var pingErr, portErr error
-	res.IsAlive, pingErr = util.PingHost(instanceIP, 3*time.Second)
-	if pingErr != nil {
-		log.Printf("Error pinging %s %s (%s): %v", instanceType, dbConfig.Name, instanceIP, pingErr)
-	}
+	res.IsAlive, res.PortOpen, res.Ping = checkReachability(instanceIP, dbConfig, instanceType)
 
 	if !res.IsAlive {
 		res.CurrentStatus = "OFFLINE"
 		return res
 	}
 
-	res.PortOpen, portErr = util.CheckTCPPort(instanceIP, dbConfig.Port, 3*time.Second)
-	if portErr != nil {
-		log.Printf("Error checking port for %s %s (%s:%d): %v", instanceType, dbConfig.Name, instanceIP, dbConfig.Port, portErr)
-	}
-
 	if !res.PortOpen {
 		res.CurrentStatus = "PORT_ERROR"
 		return res
 	}
 
+	if dbConfig.TNSProbeEnabled() {
+		res.ListenerStatus = probeListener(instanceIP, dbConfig, instanceType)
+		if res.ListenerStatus == ListenerServiceNotRegistered || res.ListenerStatus == ListenerBlocked {
+			// The listener already told us why a login cannot work. Other
+			// refusals are left to the login attempt, as the probe may have
+			// been rejected for reasons that do not affect real clients.
+			res.CurrentStatus = res.ListenerStatus
+			return res
+		}
+	}
+
 	oraCfg := util.CreateOraUtilConfig(instanceIP, dbConfig)
 	oraDB, err := util.NewOracleDB(oraCfg)
 	if err != nil {
 		log.Printf("Warning: Could not connect to %s database %s (%s:%d): %v", instanceType, dbConfig.Name, instanceIP, dbConfig.Port, err)
 		res.CurrentStatus = "DB_CONNECTION_ERROR"
+		res.Error = util.ClassifyOracleError(err, models.StageConnect)
 		return res
 	}
 	res.DbConnected = true
@@ -72,6 +93,7 @@ func checkOracleInstanceDetailed(instanceIP string, dbConfig models.DatabaseConf
 	if infoErr != nil {
 		log.Printf("Warning: Failed to get %s database info for %s (%s:%d): %v", instanceType, dbConfig.Name, instanceIP, dbConfig.Port, infoErr)
 		res.CurrentStatus = "INFO_FETCH_FAILED"
+		res.Error = util.ClassifyOracleError(infoErr, models.StageInfo)
 		return res
 	}
 
@@ -82,6 +104,10 @@ func checkOracleInstanceDetailed(instanceIP string, dbConfig models.DatabaseConf
 		res.Role = role // Return the raw role
 	}
 	res.CurrentStatus = openMode // Return the raw open_mode
+	res.DbUniqueName, _ = dbInfo["DB_UNIQUE_NAME"].(string)
+	res.InstanceName, _ = dbInfo["INSTANCE_NAME"].(string)
+	res.HostName, _ = dbInfo["SERVER_HOST"].(string)
+	res.ResetlogsChange, _ = dbInfo["RESETLOGS_CHANGE#"].(int64)
 
 	// Get Lag or Connections based on Open Mode
 	if openMode != "READ WRITE" && openMode != "" { // Typically STANDBY or READ ONLY
@@ -89,6 +115,7 @@ func checkOracleInstanceDetailed(instanceIP string, dbConfig models.DatabaseConf
 		if lagErr != nil {
 			log.Printf("Warning: Failed to get ADG lag for %s %s (%s:%d): %v", instanceType, dbConfig.Name, instanceIP, dbConfig.Port, lagErr)
 			// DgDelay remains -1
+			res.Error = util.ClassifyOracleError(lagErr, models.StageLag)
 		} else {
 			res.DgDelay = delay
 		}
@@ -100,11 +127,24 @@ func checkOracleInstanceDetailed(instanceIP string, dbConfig models.DatabaseConf
 			if connErr != nil {
 				log.Printf("Warning: Failed to get business connection count for %s %s (%s:%d): %v", instanceType, dbConfig.Name, instanceIP, dbConfig.Port, connErr)
 				// Connections remains -1
+				res.Error = util.ClassifyOracleError(connErr, models.StageConnections)
 			} else {
 				res.Connections = conns
 			}
 		}
 	}
+
+	space, spaceErr := oraDB.GetSpaceUsage()
+	if spaceErr != nil {
+		log.Printf("Warning: Failed to get recovery area usage for %s %s (%s:%d): %v", instanceType, dbConfig.Name, instanceIP, dbConfig.Port, spaceErr)
+		space.Error = util.ClassifyOracleError(spaceErr, models.StageSpace)
+	}
+	res.Space = &space
+
+	res.BackupJobs, res.BackupError = fetchBackupJobs(oraDB, dbConfig, instanceIP, instanceType)
+	res.Settings, res.SettingsError = fetchConfigSettings(oraDB, dbConfig, instanceIP, instanceType)
+	res.Nologging = collectNologging(oraDB, dbConfig, instanceIP, instanceType, role)
+	res.PDBs, res.PDBError = collectPDBs(oraDB, dbConfig, instanceIP, instanceType)
 	return res
 }
 
@@ -112,6 +152,9 @@ func checkOracleInstanceDetailed(instanceIP string, dbConfig models.DatabaseConf
 func checkDatabaseSystem(db models.DatabaseConfig) models.DatabaseStatus {
 	status := models.DatabaseStatus{
 		Name:              db.Name,
+		Group:             db.Group,
+		Tier:              db.Tier,
+		Tags:              db.Tags,
 		LoadBalancerIP:    db.LBIP,
 		ProductionIP:      db.ProdIP,
 		DisasterIP:        db.DRIP,
@@ -124,25 +167,24 @@ func checkDatabaseSystem(db models.DatabaseConfig) models.DatabaseStatus {
 		DisasterStatus:    "CHECKING",
 	}
 
+	var prodBackups, drBackups []models.BackupJob
+	var prodBackupError, drBackupError *models.MemberError
+	var prodQueried, drQueried bool
+	var prodSettings, drSettings map[string]models.ConfigSetting
+	var prodSettingsError, drSettingsError *models.MemberError
+	var prodNologging, drNologging *models.MemberNologging
+	var prodPDBs, drPDBs []models.PDBState
+	var prodPDBError, drPDBError *models.MemberError
+
 	var wg sync.WaitGroup
 	wg.Add(3) // One goroutine for LB, one for Production, one for DR
 
 	// --- Load Balancer Checks ---
 	go func() {
 		defer wg.Done()
-		var pingErr, portErr error
-		status.LoadBalancerAlive, pingErr = util.PingHost(db.LBIP, 3*time.Second)
-		if pingErr != nil {
-			log.Printf("Error pinging Load Balancer %s (%s): %v", db.Name, db.LBIP, pingErr)
-		}
-		if status.LoadBalancerAlive {
-			status.LoadBalancerPort1521, portErr = util.CheckTCPPort(db.LBIP, db.Port, 3*time.Second)
-			if portErr != nil {
-				log.Printf("Error checking port for Load Balancer %s (%s:%d): %v", db.Name, db.LBIP, db.Port, portErr)
-			}
-			if status.LoadBalancerPort1521 {
-				status.LoadBalancerDbConnect = util.TestConnection(db.LBIP, db.Port, db.Username, db.Password, db.ServiceName)
-			}
+		status.LoadBalancerAlive, status.LoadBalancerPort1521, status.LoadBalancerPing = checkReachability(db.LBIP, db, "Load Balancer")
+		if status.LoadBalancerPort1521 {
+			probeLoadBalancerRoute(db, &status)
 		}
 	}()
 
@@ -151,11 +193,22 @@ func checkDatabaseSystem(db models.DatabaseConfig) models.DatabaseStatus {
 		defer wg.Done()
 		prodStatus := checkOracleInstanceDetailed(db.ProdIP, db, "Production")
 		status.ProductionAlive = prodStatus.IsAlive
+		status.ProductionPing = prodStatus.Ping
+		status.ProductionListener = prodStatus.ListenerStatus
+		status.ProductionError = prodStatus.Error
 		status.ProductionPort1521 = prodStatus.PortOpen
 		status.ProductionDbConnect = prodStatus.DbConnected
 		status.ProductionStatus = prodStatus.CurrentStatus
 		status.ProductionRole = prodStatus.Role
 		status.ProductionDgDelay = prodStatus.DgDelay
+		status.ProductionDbUniqueName = prodStatus.DbUniqueName
+		status.ProductionInstanceName = prodStatus.InstanceName
+		status.ProductionResetlogsChange = prodStatus.ResetlogsChange
+		status.ProductionSpace = prodStatus.Space
+		prodBackups, prodBackupError, prodQueried = prodStatus.BackupJobs, prodStatus.BackupError, prodStatus.DbConnected
+		prodSettings, prodSettingsError = prodStatus.Settings, prodStatus.SettingsError
+		prodNologging = prodStatus.Nologging
+		prodPDBs, prodPDBError = prodStatus.PDBs, prodStatus.PDBError
 		if prodStatus.Connections != -1 { // Only update if valid connections count was fetched
 			status.Connections = prodStatus.Connections
 		}
@@ -166,31 +219,185 @@ func checkDatabaseSystem(db models.DatabaseConfig) models.DatabaseStatus {
 		defer wg.Done()
 		drStatus := checkOracleInstanceDetailed(db.DRIP, db, "Disaster Recovery")
 		status.DisasterAlive = drStatus.IsAlive
+		status.DisasterPing = drStatus.Ping
+		status.DisasterListener = drStatus.ListenerStatus
+		status.DisasterError = drStatus.Error
 		status.DisasterPort1521 = drStatus.PortOpen
 		status.DisasterDbConnect = drStatus.DbConnected
 		status.DisasterStatus = drStatus.CurrentStatus
 		status.DisasterRole = drStatus.Role
 		status.DisasterDgDelay = drStatus.DgDelay
+		status.DisasterDbUniqueName = drStatus.DbUniqueName
+		status.DisasterInstanceName = drStatus.InstanceName
+		status.DisasterResetlogsChange = drStatus.ResetlogsChange
+		status.DisasterSpace = drStatus.Space
+		drBackups, drBackupError, drQueried = drStatus.BackupJobs, drStatus.BackupError, drStatus.DbConnected
+		drSettings, drSettingsError = drStatus.Settings, drStatus.SettingsError
+		drNologging = drStatus.Nologging
+		drPDBs, drPDBError = drStatus.PDBs, drStatus.PDBError
 		// Connections field is typically not set for DR unless it becomes primary.
 	}()
 
 	wg.Wait()
+	if prodQueried || drQueried {
+		// Only judge the backups if at least one member could list its jobs.
+		status.Backup = &models.BackupStatus{Types: []models.BackupTypeStatus{}}
+		if (prodQueried && prodBackupError == nil) || (drQueried && drBackupError == nil) {
+			status.Backup = summarizeBackups(prodBackups, drBackups, models.GetConfig().Backup, time.Now())
+		}
+		status.Backup.ProductionError, status.Backup.DisasterError = prodBackupError, drBackupError
+	}
+	if prodQueried && drQueried {
+		status.Drift = &models.DriftReport{Differences: []models.DriftItem{}}
+		if prodSettings != nil && drSettings != nil {
+			status.Drift = compareSettings(prodSettings, drSettings, models.GetConfig().Drift.Ignore)
+		}
+		status.Drift.ProductionError, status.Drift.DisasterError = prodSettingsError, drSettingsError
+	}
+	status.Nologging = trackNologging(db.Name, prodNologging, drNologging)
+	if prodPDBs != nil || drPDBs != nil {
+		// Only CDBs are compared; a member that failed is shown with its error instead of missing PDBs.
+		status.PDBs = comparePDBs(
+			pdbMember{member: models.MemberProduction, role: status.ProductionRole, openMode: status.ProductionStatus, pdbs: pdbsByName(prodPDBs), queried: prodQueried && prodPDBError == nil},
+			pdbMember{member: models.MemberDisaster, role: status.DisasterRole, openMode: status.DisasterStatus, pdbs: pdbsByName(drPDBs), queried: drQueried && drPDBError == nil})
+		status.PDBs.ProductionError, status.PDBs.DisasterError = prodPDBError, drPDBError
+	}
+	if db.HeartbeatTable != "" {
+		measureHeartbeatLag(db, &status)
+	}
+	if status.LoadBalancerDbConnect {
+		status.LoadBalancerRoutedTo = matchRoutedMember(&status)
+	}
 	// Final status refinement is mostly handled within checkOracleInstanceDetailed now.
 	// This block can be simplified or removed if statuses are definitive from checkers.
 	// For instance, if prodStatus.CurrentStatus is "Offline", status.ProductionStatus will be "Offline".
 	return status
 }
 
-// createOraUtilConfig is a helper function to create OracleConfig.
-func createOraUtilConfig(ip string, dbCfg models.DatabaseConfig) *util.OracleConfig {
-	return &util.OracleConfig{
-		Host:        ip,
-		Port:        dbCfg.Port,
-		ServiceName: dbCfg.ServiceName,
-		Username:    dbCfg.Username,
-		Password:    dbCfg.Password,
-		ConnTimeout: 5, // Short timeout for status check connection attempt
-		ConnectType: "service_name",
-		URLOptions:  make(map[string]string),
-	}
-} 
\ No newline at end of file
+// checkReachability pings the host and, if it answers, checks the listener port.
+// In tcp-only mode, or when no ICMP socket can be opened, ICMP is skipped and
+// the host counts as alive when the port accepts connections.
+// label is "Production", "Disaster Recovery" or "Load Balancer" for logging.
+func checkReachability(ip string, db models.DatabaseConfig, label string) (alive bool, portOpen bool, ping *models.PingStats) {
+	checkTimeout := time.Duration(db.CheckTimeout) * time.Second
+
+	tcpOnly := db.CheckMode == models.CheckModeTCPOnly
+	if !tcpOnly {
+		result, pingErr := util.Ping(ip, db.PingCount, checkTimeout)
+		switch {
+		case errors.Is(pingErr, util.ErrICMPUnavailable):
+			icmpUnavailableOnce.Do(func() {
+				log.Printf("Warning: ICMP is unavailable, judging reachability by the TCP port check alone: %v", pingErr)
+			})
+			tcpOnly = true
+		case pingErr != nil:
+			log.Printf("Error pinging %s %s (%s): %v", label, db.Name, ip, pingErr)
+			return false, false, nil
+		default:
+			ping = &models.PingStats{
+				RTTMs:      float64(result.AvgRTT) / float64(time.Millisecond),
+				JitterMs:   float64(result.Jitter) / float64(time.Millisecond),
+				PacketLoss: result.PacketLoss,
+			}
+			if result.Received == 0 {
+				return false, false, ping
+			}
+		}
+	}
+
+	portOpen, portErr := util.CheckTCPPort(ip, db.Port, checkTimeout)
+	if portErr != nil {
+		log.Printf("Error checking port for %s %s (%s:%d): %v", label, db.Name, ip, db.Port, portErr)
+	}
+	if tcpOnly {
+		return portOpen, portOpen, nil
+	}
+	return true, portOpen, ping
+}
+
+// icmpUnavailableOnce limits the warning about missing ICMP sockets to one per run.
+var icmpUnavailableOnce sync.Once
+
+// Listener states reported in OracleInstanceStatus.ListenerStatus and, for the
+// non-registered cases, as the member's current status.
+const (
+	ListenerServiceRegistered    = "SERVICE_REGISTERED"
+	ListenerServiceNotRegistered = "SERVICE_NOT_REGISTERED"
+	ListenerBlocked              = "LISTENER_BLOCKED"
+	ListenerRefused              = "LISTENER_REFUSED"
+)
+
+// probeListener asks the TNS listener for the configured service without credentials.
+// It returns an empty string when the probe could not be completed, and
+// ListenerRefused when the refusal could not be classified; in both cases the
+// regular login attempt decides the member's status.
+func probeListener(ip string, db models.DatabaseConfig, label string) string {
+	result, err := util.ProbeTNSListener(ip, db.Port, db.ServiceName, time.Duration(db.CheckTimeout)*time.Second)
+	if err != nil {
+		log.Printf("Warning: TNS listener probe failed for %s %s (%s:%d): %v", label, db.Name, ip, db.Port, err)
+		return ""
+	}
+
+	switch result.Outcome {
+	case util.TNSAccept, util.TNSRedirect:
+		return ListenerServiceRegistered
+	}
+
+	log.Printf("Warning: TNS listener for %s %s (%s:%d) refused service '%s': %s", label, db.Name, ip, db.Port, db.ServiceName, result.Data)
+	switch result.ErrorCode {
+	case 12514, 12505: // Service or SID not known by the listener
+		return ListenerServiceNotRegistered
+	case 12516, 12519, 12520, 12526, 12527, 12528: // No handler available, or instances blocking/restricted
+		return ListenerBlocked
+	default:
+		return ListenerRefused
+	}
+}
+
+// probeLoadBalancerRoute logs in through the load balancer VIP and records which
+// instance actually answered, so the routing can be matched against the members.
+func probeLoadBalancerRoute(db models.DatabaseConfig, status *models.DatabaseStatus) {
+	oraDB, err := util.NewOracleDB(util.CreateOraUtilConfig(db.LBIP, db))
+	if err != nil {
+		log.Printf("Warning: Could not connect through Load Balancer %s (%s:%d): %v", db.Name, db.LBIP, db.Port, err)
+		status.LoadBalancerError = util.ClassifyOracleError(err, models.StageConnect)
+		return
+	}
+	defer oraDB.Close()
+	status.LoadBalancerDbConnect = true
+
+	dbInfo, err := oraDB.GetDatabaseInfo()
+	if err != nil {
+		log.Printf("Warning: Failed to identify instance behind Load Balancer %s (%s:%d): %v", db.Name, db.LBIP, db.Port, err)
+		status.LoadBalancerError = util.ClassifyOracleError(err, models.StageInfo)
+		return
+	}
+	status.LoadBalancerRoutedRole, _ = dbInfo["DATABASE_ROLE"].(string)
+	status.LoadBalancerRoutedDbUniqueName, _ = dbInfo["DB_UNIQUE_NAME"].(string)
+	status.LoadBalancerRoutedInstance, _ = dbInfo["INSTANCE_NAME"].(string)
+	status.LoadBalancerRoutedHost, _ = dbInfo["SERVER_HOST"].(string)
+}
+
+// matchRoutedMember compares the identity seen through the load balancer with the
+// identities reported by the members. DB_UNIQUE_NAME differs between primary and
+// standby in a Data Guard configuration; the instance name breaks ties when it does not.
+func matchRoutedMember(status *models.DatabaseStatus) string {
+	routed := status.LoadBalancerRoutedDbUniqueName
+	if routed == "" {
+		return models.RoutedUnknown
+	}
+	prodMatch := status.ProductionDbUniqueName != "" && strings.EqualFold(routed, status.ProductionDbUniqueName)
+	drMatch := status.DisasterDbUniqueName != "" && strings.EqualFold(routed, status.DisasterDbUniqueName)
+	if prodMatch && drMatch {
+		prodMatch = strings.EqualFold(status.LoadBalancerRoutedInstance, status.ProductionInstanceName)
+		drMatch = strings.EqualFold(status.LoadBalancerRoutedInstance, status.DisasterInstanceName)
+	}
+	switch {
+	case prodMatch && !drMatch:
+		return models.MemberProduction
+	case drMatch && !prodMatch:
+		return models.MemberDisaster
+	default:
+		return models.RoutedUnknown
+	}
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/util"
+)
+
+// databaseNotOpenCode is raised when a dictionary view is queried on a mounted database.
+const databaseNotOpenCode = "ORA-01219"
+
+// readOnlyDatabaseCode is raised when DML is run on a standby open read-only.
+const readOnlyDatabaseCode = "ORA-16000"
+
+// heartbeatWritePrivileges are needed by the MERGE that writes the heartbeat on the primary.
+var heartbeatWritePrivileges = []string{"INSERT", "UPDATE"}
+
+// RunDiagnostics verifies, for every database matching the filter and each of its
+// members, that the monitoring account can log in, is not locked or about to
+// expire, and holds the privileges required by the status checks.
+func RunDiagnostics(filter models.DatabaseFilter) models.DiagnosticsReport {
+	currentConfig := models.GetConfig()
+	report := models.DiagnosticsReport{
+		GeneratedAt: time.Now(),
+		Databases:   []models.DatabaseDiagnostics{},
+		Verdict:     newVerdict(),
+	}
+
+	var dbs []models.DatabaseConfig
+	for _, db := range currentConfig.DBs {
+		if filter.MatchesDatabase(db) {
+			dbs = append(dbs, db)
+		}
+	}
+
+	results := make([]models.DatabaseDiagnostics, len(dbs))
+	var wg sync.WaitGroup
+	for i, db := range dbs {
+		wg.Add(1)
+		go func(idx int, dbConfig models.DatabaseConfig) {
+			defer wg.Done()
+			results[idx] = diagnoseDatabase(dbConfig, currentConfig.Diagnostics)
+		}(i, db)
+	}
+	wg.Wait()
+
+	for _, result := range results {
+		report.Databases = append(report.Databases, result)
+		mergeVerdict(&report.Verdict, result.Verdict)
+	}
+	return report
+}
+
+// diagnoseDatabase runs the self-check on both members of a database.
+func diagnoseDatabase(db models.DatabaseConfig, cfg models.DiagnosticsConfig) models.DatabaseDiagnostics {
+	result := models.DatabaseDiagnostics{
+		Name:    db.Name,
+		Members: make([]models.MemberDiagnostics, 2),
+		Verdict: newVerdict(),
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		result.Members[0] = diagnoseMember(db, db.ProdIP, models.MemberProduction, cfg)
+	}()
+	go func() {
+		defer wg.Done()
+		result.Members[1] = diagnoseMember(db, db.DRIP, models.MemberDisaster, cfg)
+	}()
+	wg.Wait()
+
+	for _, member := range result.Members {
+		mergeVerdict(&result.Verdict, member.Verdict)
+	}
+	return result
+}
+
+// diagnoseMember logs in to one member and checks the account and view privileges.
+func diagnoseMember(db models.DatabaseConfig, ip, member string, cfg models.DiagnosticsConfig) models.MemberDiagnostics {
+	result := models.MemberDiagnostics{
+		Member:     member,
+		Address:    ip,
+		Privileges: []models.PrivilegeCheck{},
+		Verdict:    newVerdict(),
+	}
+
+	oraDB, err := util.NewOracleDB(util.CreateOraUtilConfig(ip, db))
+	if err != nil {
+		log.Printf("Diagnostics: could not connect to %s %s (%s:%d): %v", member, db.Name, ip, db.Port, err)
+		result.Error = util.ClassifyOracleError(err, models.StageConnect)
+		result.Verdict.Raise(models.HealthCritical, errorReason(result.Error, "DB_CONNECT_FAILED"), member)
+		return result
+	}
+	defer oraDB.Close()
+	result.Connected = true
+
+	account, err := oraDB.GetAccountStatus()
+	if err != nil {
+		if util.ClassifyOracleError(err, models.StagePrivileges).Code == databaseNotOpenCode {
+			// USER_USERS cannot be read on a mounted standby; the account is checked on the primary.
+			log.Printf("Diagnostics: account status not available on mounted %s %s (%s:%d)", member, db.Name, ip, db.Port)
+		} else {
+			log.Printf("Diagnostics: failed to read account status on %s %s (%s:%d): %v", member, db.Name, ip, db.Port, err)
+			result.Verdict.Raise(models.HealthWarning, "ACCOUNT_STATUS_UNKNOWN", member)
+		}
+	} else {
+		evaluateAccount(&account, cfg, member, &result.Verdict)
+		result.Account = &account
+	}
+
+	for _, view := range util.RequiredViews {
+		check := models.PrivilegeCheck{Object: view, Granted: true}
+		if err := oraDB.CheckViewAccess(view); err != nil {
+			check.Granted = false
+			check.Error = util.ClassifyOracleError(err, models.StagePrivileges)
+			if check.Error.Code == databaseNotOpenCode {
+				// DBA_ views cannot be read on a mounted standby; nothing to verify there.
+				continue
+			}
+			result.Verdict.Raise(models.HealthCritical, "MISSING_PRIVILEGE", member)
+		}
+		result.Privileges = append(result.Privileges, check)
+	}
+	if db.HeartbeatTable != "" {
+		checkHeartbeatTable(oraDB, db.HeartbeatTable, member, &result)
+	}
+	return result
+}
+
+// checkHeartbeatTable verifies that the heartbeat table can be read, and written
+// as the MERGE on the primary does. Either member may become the primary, so
+// both are checked where the open mode allows it.
+func checkHeartbeatTable(oraDB *util.OracleDB, table, member string, result *models.MemberDiagnostics) {
+	check := models.PrivilegeCheck{Object: table, Granted: true}
+	if err := oraDB.CheckViewAccess(table); err != nil {
+		check.Granted = false
+		check.Error = util.ClassifyOracleError(err, models.StagePrivileges)
+		if check.Error.Code == databaseNotOpenCode {
+			return
+		}
+		result.Verdict.Raise(models.HealthWarning, "MISSING_PRIVILEGE", member)
+	}
+	result.Privileges = append(result.Privileges, check)
+
+	for _, privilege := range heartbeatWritePrivileges {
+		check := models.PrivilegeCheck{Object: table + " (" + privilege + ")", Granted: true}
+		if err := oraDB.CheckTableWrite(table, privilege); err != nil {
+			check.Granted = false
+			check.Error = util.ClassifyOracleError(err, models.StagePrivileges)
+			if check.Error.Code == readOnlyDatabaseCode || check.Error.Code == databaseNotOpenCode {
+				// A standby rejects any DML, so the grant can only be verified on the primary.
+				continue
+			}
+			result.Verdict.Raise(models.HealthWarning, "MISSING_PRIVILEGE", member)
+		}
+		result.Privileges = append(result.Privileges, check)
+	}
+}
+
+// evaluateAccount fills in the days until the password expires and raises
+// findings for locked, expired or soon-to-expire accounts.
+func evaluateAccount(account *models.AccountStatus, cfg models.DiagnosticsConfig, member string, verdict *models.HealthVerdict) {
+	status := strings.ToUpper(account.AccountStatus)
+	switch {
+	case strings.Contains(status, "LOCKED"):
+		verdict.Raise(models.HealthCritical, models.ErrAccountLocked, member)
+	case strings.Contains(status, "GRACE"):
+		verdict.Raise(models.HealthWarning, "PASSWORD_IN_GRACE", member)
+	case strings.Contains(status, "EXPIRED"):
+		verdict.Raise(models.HealthCritical, models.ErrPasswordExpired, member)
+	}
+
+	if account.ExpiryDate == nil {
+		return
+	}
+	days := int(time.Until(*account.ExpiryDate).Hours() / 24)
+	account.DaysUntilExpiry = &days
+	if days <= cfg.ExpiryWarningDays && !strings.Contains(status, "EXPIRED") {
+		verdict.Raise(models.HealthWarning, "PASSWORD_EXPIRES_SOON", member)
+	}
+}
+
+// newVerdict returns an OK verdict with an empty reason list.
+func newVerdict() models.HealthVerdict {
+	return models.HealthVerdict{Level: models.HealthOK, Reasons: []models.HealthReason{}}
+}
+
+// mergeVerdict adds the reasons of src to dst and escalates dst's level if needed.
+func mergeVerdict(dst *models.HealthVerdict, src models.HealthVerdict) {
+	dst.Reasons = append(dst.Reasons, src.Reasons...)
+	if models.HealthSeverity(src.Level) > models.HealthSeverity(dst.Level) {
+		dst.Level = src.Level
+	}
+}
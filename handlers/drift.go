@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/util"
+)
+
+// roleSpecificSettings are expected to differ between primary and standby and are never reported as drift.
+var roleSpecificSettings = []string{
+	"parameter.db_unique_name",
+	"parameter.instance_name",
+	"parameter.instance_number",
+	"parameter.thread",
+	"parameter.service_names",
+	"parameter.local_listener",
+	"parameter.remote_listener",
+	"parameter.fal_server",
+	"parameter.fal_client",
+	"parameter.log_archive_dest_*",
+	"parameter.log_archive_dest_state_*",
+	"parameter.control_files",
+	"parameter.db_file_name_convert",
+	"parameter.log_file_name_convert",
+	"parameter.dg_broker_config_file*",
+	"parameter.spfile",
+	"parameter.audit_file_dest",
+	"parameter.background_dump_dest",
+	"parameter.user_dump_dest",
+	"parameter.core_dump_dest",
+	"parameter.diagnostic_dest",
+	"parameter.dispatchers",
+}
+
+// driftCache avoids reading the configuration of each member on every cycle.
+var driftCache memberCache[map[string]models.ConfigSetting]
+
+// fetchConfigSettings returns the configuration settings of a member, re-reading
+// them only when the cached copy is older than drift.check_interval_minutes.
+func fetchConfigSettings(oraDB *util.OracleDB, dbConfig models.DatabaseConfig, ip, instanceType string) (map[string]models.ConfigSetting, *models.MemberError) {
+	maxAge := time.Duration(models.GetConfig().Drift.CheckIntervalMinutes) * time.Minute
+	return driftCache.get(dbConfig.Name+"|"+ip, maxAge, func() (map[string]models.ConfigSetting, *models.MemberError) {
+		settings, err := oraDB.GetConfigSettings()
+		if err != nil {
+			log.Printf("Warning: Failed to get configuration settings for %s %s (%s:%d): %v", instanceType, dbConfig.Name, ip, dbConfig.Port, err)
+			return nil, util.ClassifyOracleError(err, models.StageDrift)
+		}
+		return settings, nil
+	})
+}
+
+// missingSetting is shown for a parameter that only one member reports.
+const missingSetting = "(missing)"
+
+// compareSettings diffs the settings of both members; parameters are compared
+// only when non-default on at least one side. A redo log layout missing on one
+// side counts as no groups, so that standby redo logs configured on one member
+// only are reported. The SQL patches are left out unless both members could
+// read them, as a mounted standby cannot.
+func compareSettings(prod, dr map[string]models.ConfigSetting, ignore []string) *models.DriftReport {
+	report := &models.DriftReport{Differences: []models.DriftItem{}}
+	patterns := append(append([]string{}, roleSpecificSettings...), ignore...)
+
+	keys := make(map[string]bool, len(prod))
+	for key := range prod {
+		keys[key] = true
+	}
+	for key := range dr {
+		keys[key] = true
+	}
+	for key := range keys {
+		prodSetting, prodOK := prod[key]
+		drSetting, drOK := dr[key]
+		if !prodOK || !drOK {
+			switch {
+			case strings.HasPrefix(key, "redo."):
+				absent := models.ConfigSetting{Value: "0"}
+				if !prodOK {
+					prodSetting = absent
+				} else {
+					drSetting = absent
+				}
+			case strings.HasPrefix(key, "parameter."):
+				absent := models.ConfigSetting{Value: missingSetting, Default: true}
+				if !prodOK {
+					prodSetting = absent
+				} else {
+					drSetting = absent
+				}
+			default:
+				continue
+			}
+		}
+		if (prodSetting.Default && drSetting.Default) || ignoredSetting(key, patterns) {
+			continue
+		}
+		report.Compared++
+		if !strings.EqualFold(strings.TrimSpace(prodSetting.Value), strings.TrimSpace(drSetting.Value)) {
+			report.Differences = append(report.Differences, models.DriftItem{Key: key, Production: prodSetting.Value, Disaster: drSetting.Value})
+		}
+	}
+	sort.Slice(report.Differences, func(i, j int) bool { return report.Differences[i].Key < report.Differences[j].Key })
+	return report
+}
+
+// ignoredSetting reports whether a key matches one of the ignore patterns.
+func ignoredSetting(key string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(strings.ToLower(pattern), key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateDrift raises a finding when the members are configured differently.
+func evaluateDrift(drift *models.DriftReport, verdict *models.HealthVerdict) {
+	if drift != nil && len(drift.Differences) > 0 {
+		verdict.Raise(models.HealthWarning, "CONFIG_DRIFT", "")
+	}
+}
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+// memberState collects the fields of one member of a DatabaseStatus needed to judge its health.
+type memberState struct {
+	alive     bool
+	portOpen  bool
+	dbConnect bool
+	status    string
+	role      string
+	delay     int
+	err       *models.MemberError
+	heartbeat *models.HeartbeatLag
+	space     *models.SpaceUsage
+}
+
+// ApplyHealth computes the per-member and per-database health verdicts and the
+// effective load balancer target, and stores them on the status. Members under
+// an active maintenance window are judged as MAINTENANCE, and reasons acknowledged
+// by an operator are flagged.
+func ApplyHealth(status *models.DatabaseStatus, cfg models.HealthConfig) {
+	prod := memberState{
+		alive:     status.ProductionAlive,
+		portOpen:  status.ProductionPort1521,
+		dbConnect: status.ProductionDbConnect,
+		status:    status.ProductionStatus,
+		role:      status.ProductionRole,
+		delay:     status.ProductionDgDelay,
+		err:       status.ProductionError,
+		heartbeat: status.ProductionHeartbeat,
+		space:     status.ProductionSpace,
+	}
+	dr := memberState{
+		alive:     status.DisasterAlive,
+		portOpen:  status.DisasterPort1521,
+		dbConnect: status.DisasterDbConnect,
+		status:    status.DisasterStatus,
+		role:      status.DisasterRole,
+		delay:     status.DisasterDgDelay,
+		err:       status.DisasterError,
+		heartbeat: status.DisasterHeartbeat,
+		space:     status.DisasterSpace,
+	}
+
+	status.ProductionHealth = evaluateMember(prod, models.MemberProduction, cfg)
+	status.DisasterHealth = evaluateMember(dr, models.MemberDisaster, cfg)
+	status.LoadBalancerTarget = determineLoadBalancerTarget(status)
+
+	overall := newVerdict()
+	mergeVerdict(&overall, status.ProductionHealth)
+	mergeVerdict(&overall, status.DisasterHealth)
+
+	// Load balancer reachability.
+	switch {
+	case !status.LoadBalancerAlive:
+		overall.Raise(models.HealthCritical, "HOST_UNREACHABLE", models.MemberLoadBalancer)
+	case !status.LoadBalancerPort1521:
+		overall.Raise(models.HealthCritical, "PORT_CLOSED", models.MemberLoadBalancer)
+	case !status.LoadBalancerDbConnect:
+		overall.Raise(models.HealthWarning, errorReason(status.LoadBalancerError, "DB_CONNECT_FAILED"), models.MemberLoadBalancer)
+	}
+
+	// Role consistency across members. Only judged when both roles are known,
+	// otherwise the member verdicts already carry the reason.
+	if prod.dbConnect && dr.dbConnect {
+		switch {
+		case prod.role == "PRIMARY" && dr.role == "PRIMARY":
+			overall.Raise(models.HealthCritical, "MULTIPLE_PRIMARIES", "")
+		case prod.role != "PRIMARY" && dr.role != "PRIMARY":
+			overall.Raise(models.HealthCritical, "NO_PRIMARY", "")
+		}
+	}
+	if status.LoadBalancerTarget == models.LBTargetOffline && status.LoadBalancerAlive {
+		overall.Raise(models.HealthCritical, "NO_LB_TARGET", models.MemberLoadBalancer)
+	}
+	evaluateLoadBalancerRoute(status, prod, dr, &overall)
+	evaluateBackups(status.Backup, &overall)
+	evaluateDrift(status.Drift, &overall)
+	evaluateNologging(status.Nologging, &overall)
+	evaluatePDBs(status.PDBs, &overall)
+
+	status.Health = overall
+	now := time.Now()
+	ApplyMaintenance(status, activeMaintenance(status.Name, now))
+	applyOperatorState(status, now)
+}
+
+// evaluateMember judges a single production or disaster recovery member.
+// A member that cannot be reached at all is critical; one that answers on the
+// network but cannot be queried is a warning, as the database may still be serving.
+func evaluateMember(m memberState, member string, cfg models.HealthConfig) models.HealthVerdict {
+	verdict := newVerdict()
+
+	switch {
+	case m.status == "CHECKING":
+		verdict.Raise(models.HealthUnknown, "NOT_CHECKED", member)
+		return verdict
+	case !m.alive && !m.portOpen:
+		verdict.Raise(models.HealthCritical, "HOST_UNREACHABLE", member)
+		return verdict
+	case !m.portOpen:
+		verdict.Raise(models.HealthWarning, "PORT_CLOSED", member)
+		return verdict
+	case m.status == ListenerServiceNotRegistered, m.status == ListenerBlocked:
+		verdict.Raise(models.HealthCritical, m.status, member)
+		return verdict
+	case !m.dbConnect:
+		verdict.Raise(errorLevel(m.err), errorReason(m.err, "DB_CONNECT_FAILED"), member)
+		return verdict
+	}
+
+	if m.status == "INFO_FETCH_FAILED" {
+		verdict.Raise(models.HealthWarning, errorReason(m.err, "INFO_FETCH_FAILED"), member)
+		return verdict
+	}
+	if m.role == "UNKNOWN" || m.role == "" {
+		verdict.Raise(models.HealthUnknown, "ROLE_UNKNOWN", member)
+	}
+
+	if m.role != "PRIMARY" && m.role != "UNKNOWN" && m.role != "" {
+		// The heartbeat lag is judged alongside the native lag, which Oracle may
+		// report as stale or missing; the larger of the two counts.
+		delay := m.delay
+		if hb := m.heartbeat; hb != nil {
+			if hb.Error != nil {
+				verdict.Raise(models.HealthWarning, "HEARTBEAT_FAILED", member)
+			} else if lag := int(hb.LagSeconds); lag > delay {
+				delay = lag
+			}
+		}
+		switch {
+		case delay < 0:
+			verdict.Raise(models.HealthWarning, "LAG_UNKNOWN", member)
+		case delay >= cfg.LagCriticalSeconds:
+			verdict.Raise(models.HealthCritical, "LAG_CRITICAL", member)
+		case delay >= cfg.LagWarningSeconds:
+			verdict.Raise(models.HealthWarning, "LAG_WARNING", member)
+		}
+	}
+	evaluateSpace(m.space, member, cfg, &verdict)
+	return verdict
+}
+
+// evaluateLoadBalancerRoute checks the member that actually answered through the VIP.
+// Being routed to a standby, or to a former primary after a role transition, is critical.
+func evaluateLoadBalancerRoute(status *models.DatabaseStatus, prod, dr memberState, overall *models.HealthVerdict) {
+	if !status.LoadBalancerDbConnect || status.LoadBalancerRoutedRole == "" {
+		return
+	}
+	if status.LoadBalancerRoutedTo == models.RoutedUnknown {
+		overall.Raise(models.HealthWarning, "LB_ROUTE_UNIDENTIFIED", models.MemberLoadBalancer)
+	}
+	if status.LoadBalancerRoutedRole != "PRIMARY" {
+		overall.Raise(models.HealthCritical, "LB_ROUTES_TO_STANDBY", models.MemberLoadBalancer)
+		return
+	}
+
+	// The VIP answered as PRIMARY; it is still wrong if the other member is the
+	// current primary. When both claim PRIMARY after a failover, the new primary
+	// is the one opened with the higher RESETLOGS_CHANGE#.
+	var routed, other memberState
+	var routedResetlogs, otherResetlogs int64
+	switch status.LoadBalancerRoutedTo {
+	case models.MemberProduction:
+		routed, other = prod, dr
+		routedResetlogs, otherResetlogs = status.ProductionResetlogsChange, status.DisasterResetlogsChange
+	case models.MemberDisaster:
+		routed, other = dr, prod
+		routedResetlogs, otherResetlogs = status.DisasterResetlogsChange, status.ProductionResetlogsChange
+	default:
+		return
+	}
+	if other.role != "PRIMARY" {
+		return
+	}
+	if routed.role != "PRIMARY" || routedResetlogs < otherResetlogs {
+		overall.Raise(models.HealthCritical, "LB_ROUTES_TO_OLD_PRIMARY", models.MemberLoadBalancer)
+	}
+}
+
+// errorReason returns the classified error category as reason code, or fallback
+// when the error could not be classified.
+func errorReason(err *models.MemberError, fallback string) string {
+	if err == nil || err.Category == models.ErrOther {
+		return fallback
+	}
+	return err.Category
+}
+
+// errorLevel rates a failed login. A database that is down or going down is
+// critical; everything else, such as a rejected monitoring account, is a warning
+// because the database may still be serving its applications.
+func errorLevel(err *models.MemberError) string {
+	if err != nil && (err.Category == models.ErrShutdownInProgress || err.Category == models.ErrDatabaseUnavailable) {
+		return models.HealthCritical
+	}
+	return models.HealthWarning
+}
+
+// determineLoadBalancerTarget decides which site the load balancer effectively
+// points at. The member identified by the VIP probe wins; without it, the
+// reachable member currently holding the PRIMARY role is assumed.
+func determineLoadBalancerTarget(status *models.DatabaseStatus) string {
+	if !status.LoadBalancerAlive {
+		return models.LBTargetOffline
+	}
+	switch status.LoadBalancerRoutedTo {
+	case models.MemberProduction:
+		return models.LBTargetProduction
+	case models.MemberDisaster:
+		return models.LBTargetDisaster
+	}
+	if status.ProductionAlive && status.ProductionRole == "PRIMARY" {
+		return models.LBTargetProduction
+	}
+	if status.DisasterAlive && status.DisasterRole == "PRIMARY" {
+		return models.LBTargetDisaster
+	}
+	return models.LBTargetOffline
+}
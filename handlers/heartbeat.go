@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"log"
+	"math"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/util"
+)
+
+// heartbeatRetryDelay is how long to wait before reading the standby a second time
+// when it has not yet applied the heartbeat written in the current cycle.
+const heartbeatRetryDelay = time.Second
+
+// measureHeartbeatLag writes a heartbeat on the primary member and reads it back on
+// the standby. Both database clocks are related to the local clock, so the lag is
+// the local time of the read minus the heartbeat converted to local time, which
+// cancels out any skew between the primary and standby clocks.
+func measureHeartbeatLag(db models.DatabaseConfig, status *models.DatabaseStatus) {
+	var primaryIP, standbyIP, standbyLabel string
+	var target **models.HeartbeatLag
+	switch {
+	case status.ProductionStatus == "READ WRITE" && status.DisasterDbConnect && status.DisasterRole != "PRIMARY":
+		primaryIP, standbyIP, standbyLabel, target = db.ProdIP, db.DRIP, "Disaster Recovery", &status.DisasterHeartbeat
+	case status.DisasterStatus == "READ WRITE" && status.ProductionDbConnect && status.ProductionRole != "PRIMARY":
+		primaryIP, standbyIP, standbyLabel, target = db.DRIP, db.ProdIP, "Production", &status.ProductionHeartbeat
+	default:
+		// Without one writable primary and one reachable standby there is nothing to measure.
+		return
+	}
+	result := &models.HeartbeatLag{}
+	*target = result
+
+	primaryDB, err := util.NewOracleDB(util.CreateOraUtilConfig(primaryIP, db))
+	if err != nil {
+		log.Printf("Warning: Heartbeat could not connect to primary of %s (%s:%d): %v", db.Name, primaryIP, db.Port, err)
+		result.Error = util.ClassifyOracleError(err, models.StageHeartbeat)
+		return
+	}
+	defer primaryDB.Close()
+
+	standbyDB, err := util.NewOracleDB(util.CreateOraUtilConfig(standbyIP, db))
+	if err != nil {
+		log.Printf("Warning: Heartbeat could not connect to %s standby of %s (%s:%d): %v", standbyLabel, db.Name, standbyIP, db.Port, err)
+		result.Error = util.ClassifyOracleError(err, models.StageHeartbeat)
+		return
+	}
+	defer standbyDB.Close()
+
+	written, err := primaryDB.WriteHeartbeat(db.HeartbeatTable)
+	if err != nil {
+		log.Printf("Warning: Failed to write heartbeat for %s (%s:%d): %v", db.Name, primaryIP, db.Port, err)
+		result.Error = util.ClassifyOracleError(err, models.StageHeartbeat)
+		return
+	}
+
+	reading, err := standbyDB.ReadHeartbeat(db.HeartbeatTable)
+	if err == nil && reading.Heartbeat.Before(written.DBTime) {
+		// Give real-time apply a moment; otherwise the lag is bounded by the previous cycle.
+		time.Sleep(heartbeatRetryDelay)
+		reading, err = standbyDB.ReadHeartbeat(db.HeartbeatTable)
+	}
+	if err != nil {
+		log.Printf("Warning: Failed to read heartbeat on %s standby of %s (%s:%d): %v", standbyLabel, db.Name, standbyIP, db.Port, err)
+		result.Error = util.ClassifyOracleError(err, models.StageHeartbeat)
+		return
+	}
+
+	heartbeatLocal := reading.Heartbeat.Add(-written.Offset)
+	lag := reading.Clock.Local.Sub(heartbeatLocal)
+	if lag < 0 {
+		lag = 0
+	}
+	result.LagSeconds = roundMillis(lag)
+	result.ClockSkewSeconds = roundMillis(reading.Clock.Offset - written.Offset)
+	result.Heartbeat = &reading.Heartbeat
+}
+
+// roundMillis converts a duration to seconds rounded to the millisecond.
+func roundMillis(d time.Duration) float64 {
+	return math.Round(d.Seconds()*1000) / 1000
+}
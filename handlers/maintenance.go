@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/history"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+// maintenanceStateName is the history state document holding maintenanceState.
+const maintenanceStateName = "maintenance"
+
+// Event types recorded for maintenance windows.
+const (
+	EventMaintenanceStarted = "MAINTENANCE_STARTED"
+	EventMaintenanceEnded   = "MAINTENANCE_ENDED"
+)
+
+var (
+	// ErrMaintenanceNotFound is returned when no maintenance window has the given ID.
+	ErrMaintenanceNotFound = errors.New("no such maintenance window")
+	// ErrMaintenanceFromConfig is returned when ending a window that is defined in the configuration.
+	ErrMaintenanceFromConfig = errors.New("maintenance window is defined in the configuration file")
+)
+
+// maintenanceState is what is remembered about maintenance windows between restarts.
+type maintenanceState struct {
+	Windows []models.MaintenanceWindow `json:"windows"` // Added through the API
+	Active  map[string][]string        `json:"active"`  // IDs of the windows active in the last cycle, per database
+}
+
+var (
+	maintenanceLock sync.Mutex
+	maintenance     *maintenanceState // Loaded on first use
+)
+
+// MaintenanceWindows returns the configured windows followed by those added
+// through the API, leaving out one-off windows that are over.
+func MaintenanceWindows() []models.MaintenanceWindow {
+	now := time.Now()
+	windows := append([]models.MaintenanceWindow{}, models.GetConfig().Maintenance...)
+	maintenanceLock.Lock()
+	defer maintenanceLock.Unlock()
+	loadMaintenance()
+	for _, w := range maintenance.Windows {
+		if !w.Expired(now) {
+			windows = append(windows, w)
+		}
+	}
+	return windows
+}
+
+// AddMaintenance validates and stores a window created through the API. A
+// one-off window starts now unless a start is given, and may be given a
+// duration instead of an end.
+func AddMaintenance(w models.MaintenanceWindow) (models.MaintenanceWindow, error) {
+	now := time.Now().Truncate(time.Second)
+	w.Source = models.MaintenanceFromAPI
+	w.Created = &now
+	w.ID = newID("mw")
+	if w.Schedule == "" {
+		if w.Start == nil {
+			w.Start = &now
+		}
+		if w.End == nil && w.DurationMinutes > 0 {
+			end := w.Start.Add(time.Duration(w.DurationMinutes) * time.Minute)
+			w.End = &end
+		}
+		w.DurationMinutes = 0
+	}
+	if err := w.Validate(); err != nil {
+		return w, err
+	}
+	if _, ok := models.GetConfig().Database(w.Database); !ok {
+		return w, fmt.Errorf("unknown database '%s'", w.Database)
+	}
+	if w.Expired(now) {
+		return w, errors.New("maintenance window is already over")
+	}
+
+	maintenanceLock.Lock()
+	loadMaintenance()
+	maintenance.Windows = append(maintenance.Windows, w)
+	saveMaintenance()
+	maintenanceLock.Unlock()
+
+	updateSnapshot(w.Database, func(*models.DatabaseStatus) {})
+	return w, nil
+}
+
+// EndMaintenance removes a window added through the API, ending it early if it is active.
+func EndMaintenance(id string) (models.MaintenanceWindow, error) {
+	for _, w := range models.GetConfig().Maintenance {
+		if w.ID == id {
+			return w, ErrMaintenanceFromConfig
+		}
+	}
+	maintenanceLock.Lock()
+	loadMaintenance()
+	var removed *models.MaintenanceWindow
+	for i, w := range maintenance.Windows {
+		if w.ID == id {
+			removed = &w
+			maintenance.Windows = append(maintenance.Windows[:i], maintenance.Windows[i+1:]...)
+			break
+		}
+	}
+	if removed != nil {
+		saveMaintenance()
+	}
+	maintenanceLock.Unlock()
+
+	if removed == nil {
+		return models.MaintenanceWindow{}, ErrMaintenanceNotFound
+	}
+	updateSnapshot(removed.Database, func(*models.DatabaseStatus) {})
+	return *removed, nil
+}
+
+// activeMaintenance returns the windows of a database that cover t.
+func activeMaintenance(dbName string, t time.Time) []models.MaintenancePeriod {
+	var periods []models.MaintenancePeriod
+	collectPeriods := func(windows []models.MaintenanceWindow) {
+		for i := range windows {
+			if windows[i].Database != dbName {
+				continue
+			}
+			if start, end, ok := windows[i].ActiveAt(t); ok {
+				periods = append(periods, windows[i].Period(start, end))
+			}
+		}
+	}
+	collectPeriods(models.GetConfig().Maintenance)
+	maintenanceLock.Lock()
+	loadMaintenance()
+	collectPeriods(maintenance.Windows)
+	maintenanceLock.Unlock()
+	return periods
+}
+
+// ApplyMaintenance stores the active maintenance periods on a status whose
+// health has been judged, and turns the verdicts of the members under
+// maintenance into MAINTENANCE. Their reasons are kept for display. The
+// overall verdict is re-judged on the remaining reasons, and is MAINTENANCE
+// rather than OK when nothing else is wrong.
+func ApplyMaintenance(status *models.DatabaseStatus, periods []models.MaintenancePeriod) {
+	status.Maintenance = periods
+	if len(periods) == 0 {
+		return
+	}
+	members := make(map[string]bool)
+	whole := false
+	for _, period := range periods {
+		if period.Member == "" {
+			whole = true
+		}
+		members[period.Member] = true
+	}
+	if whole || members[models.MemberProduction] {
+		status.ProductionHealth.Level = models.HealthMaintenance
+	}
+	if whole || members[models.MemberDisaster] {
+		status.DisasterHealth.Level = models.HealthMaintenance
+	}
+
+	level := models.HealthOK
+	for _, reason := range status.Health.Reasons {
+		if whole || members[reason.Member] {
+			continue
+		}
+		if models.HealthSeverity(reason.Level) > models.HealthSeverity(level) {
+			level = reason.Level
+		}
+	}
+	if level == models.HealthOK {
+		level = models.HealthMaintenance
+	}
+	status.Health.Level = level
+}
+
+// trackMaintenance records an event whenever a window starts or ends on a
+// database, and forgets one-off windows that are over.
+func trackMaintenance(statuses []models.DatabaseStatus, now time.Time) {
+	maintenanceLock.Lock()
+	defer maintenanceLock.Unlock()
+	loadMaintenance()
+	changed := false
+
+	for _, status := range statuses {
+		previous := make(map[string]bool)
+		for _, id := range maintenance.Active[status.Name] {
+			previous[id] = true
+		}
+		var active []string
+		for _, period := range status.Maintenance {
+			active = append(active, period.ID)
+			if previous[period.ID] {
+				delete(previous, period.ID)
+				continue
+			}
+			recordEvent(history.Event{
+				Time:     now,
+				Database: status.Name,
+				Member:   period.Member,
+				Type:     EventMaintenanceStarted,
+				Message:  fmt.Sprintf("Maintenance window %s started until %s: %s (%s)", period.ID, period.End.Format(time.RFC3339), period.Reason, period.Owner),
+				Details:  map[string]string{"id": period.ID, "reason": period.Reason, "owner": period.Owner, "end": period.End.Format(time.RFC3339)},
+			})
+		}
+		ended := make([]string, 0, len(previous))
+		for id := range previous {
+			ended = append(ended, id)
+		}
+		sort.Strings(ended)
+		for _, id := range ended {
+			recordEvent(history.Event{
+				Time:     now,
+				Database: status.Name,
+				Type:     EventMaintenanceEnded,
+				Message:  fmt.Sprintf("Maintenance window %s ended", id),
+				Details:  map[string]string{"id": id},
+			})
+		}
+		if len(ended) > 0 || len(active) != len(maintenance.Active[status.Name]) {
+			changed = true
+		}
+		if len(active) > 0 {
+			maintenance.Active[status.Name] = active
+		} else {
+			delete(maintenance.Active, status.Name)
+		}
+	}
+
+	windows := maintenance.Windows[:0]
+	for _, w := range maintenance.Windows {
+		if w.Expired(now) {
+			changed = true
+			continue
+		}
+		windows = append(windows, w)
+	}
+	maintenance.Windows = windows
+	if changed {
+		saveMaintenance()
+	}
+}
+
+// newID returns a short random ID for something added through the API, e.g. "mw-1f2e3d4c".
+func newID(prefix string) string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return prefix + "-" + hex.EncodeToString(b)
+}
+
+// loadMaintenance reads the persisted state once. The caller must hold maintenanceLock.
+func loadMaintenance() {
+	if maintenance != nil {
+		return
+	}
+	maintenance = &maintenanceState{}
+	if historyStore != nil {
+		if err := historyStore.LoadState(maintenanceStateName, maintenance); err != nil {
+			log.Printf("Warning: Failed to load maintenance state: %v", err)
+		}
+	}
+	if maintenance.Active == nil {
+		maintenance.Active = make(map[string][]string)
+	}
+}
+
+// saveMaintenance persists the state. The caller must hold maintenanceLock.
+func saveMaintenance() {
+	if historyStore == nil {
+		return
+	}
+	if err := historyStore.SaveState(maintenanceStateName, maintenance); err != nil {
+		log.Printf("Warning: Failed to save maintenance state: %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+// degradedStatus is a database whose standby lags badly and whose primary
+// is short of recovery area space.
+func degradedStatus() models.DatabaseStatus {
+	status := models.DatabaseStatus{Name: "ERP_DB"}
+	status.ProductionHealth = models.HealthVerdict{Level: models.HealthWarning}
+	status.ProductionHealth.Raise(models.HealthWarning, "FRA_USAGE_HIGH", models.MemberProduction)
+	status.DisasterHealth = models.HealthVerdict{Level: models.HealthCritical}
+	status.DisasterHealth.Raise(models.HealthCritical, "LAG_CRITICAL", models.MemberDisaster)
+	status.Health = models.HealthVerdict{Level: models.HealthOK}
+	status.Health.Raise(models.HealthWarning, "FRA_USAGE_HIGH", models.MemberProduction)
+	status.Health.Raise(models.HealthCritical, "LAG_CRITICAL", models.MemberDisaster)
+	return status
+}
+
+func TestApplyMaintenance(t *testing.T) {
+	tests := []struct {
+		name           string
+		periods        []models.MaintenancePeriod
+		healthy        bool // Start from a status without reasons
+		wantLevel      string
+		wantProduction string
+		wantDisaster   string
+	}{
+		{name: "no window", wantLevel: models.HealthCritical, wantProduction: models.HealthWarning, wantDisaster: models.HealthCritical},
+		{name: "whole database", periods: []models.MaintenancePeriod{{ID: "mw-1"}},
+			wantLevel: models.HealthMaintenance, wantProduction: models.HealthMaintenance, wantDisaster: models.HealthMaintenance},
+		{name: "standby only", periods: []models.MaintenancePeriod{{ID: "mw-1", Member: models.MemberDisaster}},
+			wantLevel: models.HealthWarning, wantProduction: models.HealthWarning, wantDisaster: models.HealthMaintenance},
+		{name: "primary only", periods: []models.MaintenancePeriod{{ID: "mw-1", Member: models.MemberProduction}},
+			wantLevel: models.HealthCritical, wantProduction: models.HealthMaintenance, wantDisaster: models.HealthCritical},
+		{name: "both members", periods: []models.MaintenancePeriod{{ID: "mw-1", Member: models.MemberProduction}, {ID: "mw-2", Member: models.MemberDisaster}},
+			wantLevel: models.HealthMaintenance, wantProduction: models.HealthMaintenance, wantDisaster: models.HealthMaintenance},
+		{name: "healthy database", healthy: true, periods: []models.MaintenancePeriod{{ID: "mw-1", Member: models.MemberDisaster}},
+			wantLevel: models.HealthMaintenance, wantProduction: models.HealthOK, wantDisaster: models.HealthMaintenance},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := degradedStatus()
+			if tt.healthy {
+				status = models.DatabaseStatus{Name: "ERP_DB"}
+				status.Health.Level, status.ProductionHealth.Level, status.DisasterHealth.Level = models.HealthOK, models.HealthOK, models.HealthOK
+			}
+			reasons := len(status.Health.Reasons)
+			ApplyMaintenance(&status, tt.periods)
+			if status.Health.Level != tt.wantLevel || status.ProductionHealth.Level != tt.wantProduction || status.DisasterHealth.Level != tt.wantDisaster {
+				t.Errorf("got %s (production %s, disaster %s), want %s (production %s, disaster %s)",
+					status.Health.Level, status.ProductionHealth.Level, status.DisasterHealth.Level, tt.wantLevel, tt.wantProduction, tt.wantDisaster)
+			}
+			if len(status.Health.Reasons) != reasons {
+				t.Errorf("got %d reasons, want the %d found kept for display", len(status.Health.Reasons), reasons)
+			}
+			if len(status.Maintenance) != len(tt.periods) {
+				t.Errorf("got %d maintenance periods on the status, want %d", len(status.Maintenance), len(tt.periods))
+			}
+		})
+	}
+}
+
+func TestMaintenanceWindowSuppressesFindings(t *testing.T) {
+	dir := t.TempDir()
+	store := useTestHistory(t, dir)
+	now := time.Now()
+	start, end := now.Add(-time.Hour), now.Add(time.Hour)
+	window := models.MaintenanceWindow{ID: "mw-1", Database: "ERP_DB", Member: models.MemberDisaster, Start: &start, End: &end,
+		Reason: "standby patching", Owner: "dba", Source: models.MaintenanceFromAPI}
+	recurring := models.MaintenanceWindow{ID: "mw-2", Database: "CRM_DB", Schedule: "0 2 * * *", DurationMinutes: 60, Source: models.MaintenanceFromAPI}
+	maintenanceLock.Lock()
+	loadMaintenance()
+	maintenance.Windows = []models.MaintenanceWindow{window, recurring}
+	saveMaintenance()
+	maintenanceLock.Unlock()
+
+	check := func(at time.Time) models.DatabaseStatus {
+		status := degradedStatus()
+		ApplyMaintenance(&status, activeMaintenance("ERP_DB", at))
+		trackMaintenance([]models.DatabaseStatus{status}, at)
+		return status
+	}
+
+	if status := check(now.Add(-2 * time.Hour)); status.Health.Level != models.HealthCritical || len(status.Maintenance) != 0 {
+		t.Fatalf("before the window: got %s with %d periods, want CRITICAL without maintenance", status.Health.Level, len(status.Maintenance))
+	}
+	if status := check(now); status.Health.Level != models.HealthWarning || status.DisasterHealth.Level != models.HealthMaintenance {
+		t.Fatalf("window active: got %s with disaster %s, want WARNING with the standby in maintenance", status.Health.Level, status.DisasterHealth.Level)
+	}
+	if status := check(now.Add(2 * time.Hour)); status.Health.Level != models.HealthCritical || len(status.Maintenance) != 0 {
+		t.Fatalf("after the window: got %s with %d periods, want CRITICAL without maintenance", status.Health.Level, len(status.Maintenance))
+	}
+
+	// The expired one-off window is forgotten, also after a restart; the recurring one stays.
+	useTestHistory(t, dir)
+	windows := MaintenanceWindows()
+	if len(windows) != 1 || windows[0].ID != "mw-2" {
+		t.Errorf("got windows %+v after the one-off window ended, want only mw-2", windows)
+	}
+
+	if types := eventTypes(t, store); len(types) != 2 || types[0] != EventMaintenanceStarted || types[1] != EventMaintenanceEnded {
+		t.Errorf("got events %v, want %s and %s", types, EventMaintenanceStarted, EventMaintenanceEnded)
+	}
+}
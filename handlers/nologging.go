@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/history"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/util"
+)
+
+// nologgingStateName is the history state document holding nologgingRecords.
+const nologgingStateName = "nologging"
+
+// Event types recorded for unrecoverable operations.
+const (
+	EventNologgingDetected     = "NOLOGGING_DETECTED"
+	EventNologgingAcknowledged = "NOLOGGING_ACKNOWLEDGED"
+	EventNonloggedBlocks       = "NONLOGGED_BLOCKS"
+)
+
+// ErrNothingToAcknowledge is returned when a database has no unacknowledged NOLOGGING operation.
+var ErrNothingToAcknowledge = errors.New("no unacknowledged NOLOGGING operation")
+
+// nologgingRecord is what is remembered about a database between check cycles.
+type nologgingRecord struct {
+	LatestChange    int64                `json:"latest_change"`
+	Baseline        *int64               `json:"baseline,omitempty"` // Highest change that predates monitoring; nil until the primary was first queried
+	DetectedAt      *time.Time           `json:"detected_at,omitempty"`
+	Acknowledgement *models.NologgingAck `json:"acknowledgement,omitempty"`
+	NonloggedBlocks int64                `json:"nonlogged_blocks"`
+}
+
+// baselinePending reports whether the record was never compared against the
+// primary. Records written before baselines existed and holding a change keep
+// alerting on it.
+func (r *nologgingRecord) baselinePending() bool {
+	return r.Baseline == nil && r.LatestChange == 0
+}
+
+// handledChange returns the change up to which operations need no attention:
+// the baseline or the acknowledged change, whichever is higher.
+func (r *nologgingRecord) handledChange() int64 {
+	var handled int64
+	if r.Baseline != nil {
+		handled = *r.Baseline
+	}
+	if r.Acknowledgement != nil && r.Acknowledgement.Change > handled {
+		handled = r.Acknowledgement.Change
+	}
+	return handled
+}
+
+func (r *nologgingRecord) unacknowledged() bool {
+	return r.LatestChange > r.handledChange()
+}
+
+var (
+	nologgingLock    sync.Mutex
+	nologgingRecords map[string]*nologgingRecord // Per database; loaded on first use
+)
+
+// collectNologging reads the unrecoverable datafiles on a primary and the
+// non-logged blocks on a physical standby.
+func collectNologging(oraDB *util.OracleDB, dbConfig models.DatabaseConfig, ip, instanceType, role string) *models.MemberNologging {
+	switch role {
+	case "PRIMARY":
+		result := &models.MemberNologging{Primary: true}
+		forceLogging, files, err := oraDB.GetUnrecoverableFiles()
+		if err != nil {
+			log.Printf("Warning: Failed to get unrecoverable datafiles for %s %s (%s:%d): %v", instanceType, dbConfig.Name, ip, dbConfig.Port, err)
+			result.Error = util.ClassifyOracleError(err, models.StageNologging)
+			return result
+		}
+		result.ForceLogging, result.Files = forceLogging, files
+		return result
+	case "PHYSICAL STANDBY":
+		result := &models.MemberNologging{}
+		blocks, err := oraDB.GetNonloggedBlocks()
+		if err != nil {
+			log.Printf("Warning: Failed to get non-logged blocks for %s %s (%s:%d): %v", instanceType, dbConfig.Name, ip, dbConfig.Port, err)
+			result.Error = util.ClassifyOracleError(err, models.StageNologging)
+			return result
+		}
+		result.NonloggedBlocks = blocks
+		return result
+	}
+	return nil
+}
+
+// trackNologging combines what the members report with the persisted record of
+// the database. A higher UNRECOVERABLE_CHANGE# than seen before is a new
+// operation: it raises an event and stays flagged until acknowledged, even in
+// cycles where the primary cannot be queried. The first time a database's
+// primary is queried its current UNRECOVERABLE_CHANGE# becomes the baseline,
+// so operations from before monitoring started do not alert.
+func trackNologging(dbName string, prod, dr *models.MemberNologging) *models.NologgingStatus {
+	if prod == nil && dr == nil {
+		return nil
+	}
+	status := &models.NologgingStatus{Files: []models.UnrecoverableFile{}}
+	var primary *models.MemberNologging
+	for _, m := range []struct {
+		member string
+		report *models.MemberNologging
+	}{{models.MemberProduction, prod}, {models.MemberDisaster, dr}} {
+		switch {
+		case m.report == nil:
+		case m.report.Primary:
+			primary = m.report
+			status.PrimaryMember = m.member
+			status.ForceLogging = m.report.ForceLogging
+			status.PrimaryError = m.report.Error
+		default:
+			status.StandbyMember = m.member
+			status.NonloggedBlocks = m.report.NonloggedBlocks
+			status.StandbyError = m.report.Error
+		}
+	}
+
+	nologgingLock.Lock()
+	defer nologgingLock.Unlock()
+	loadNologgingRecords()
+	record := nologgingRecords[dbName]
+	if record == nil {
+		record = &nologgingRecord{}
+		nologgingRecords[dbName] = record
+	}
+	changed := false
+	now := time.Now()
+
+	if primary != nil && primary.Error == nil {
+		var latest *models.UnrecoverableFile
+		var newFiles []string
+		for i, file := range primary.Files {
+			if latest == nil || file.Change > latest.Change {
+				latest = &primary.Files[i]
+			}
+			if file.Change > record.LatestChange {
+				newFiles = append(newFiles, file.Name)
+			}
+		}
+		if record.baselinePending() {
+			var baseline int64
+			if latest != nil {
+				baseline = latest.Change
+			}
+			record.Baseline = &baseline
+			record.LatestChange = baseline
+			changed = true
+			if baseline > 0 {
+				log.Printf("NOLOGGING baseline for %s: unrecoverable operations up to change %d predate monitoring and are not reported", dbName, baseline)
+			}
+		} else if latest != nil && latest.Change > record.LatestChange {
+			record.LatestChange = latest.Change
+			record.DetectedAt = &now
+			changed = true
+			details := map[string]string{
+				"change": strconv.FormatInt(latest.Change, 10),
+				"files":  strings.Join(newFiles, ","),
+			}
+			if latest.Time != nil {
+				details["time"] = latest.Time.Format(time.RFC3339)
+			}
+			recordEvent(history.Event{
+				Time:     now,
+				Database: dbName,
+				Member:   status.PrimaryMember,
+				Type:     EventNologgingDetected,
+				Message:  fmt.Sprintf("Unrecoverable operation up to change %d on %d datafile(s)", latest.Change, len(newFiles)),
+				Details:  details,
+			})
+		}
+	}
+	if blocks := status.NonloggedBlocks; blocks != nil && blocks.Blocks != record.NonloggedBlocks {
+		if blocks.Blocks > record.NonloggedBlocks {
+			recordEvent(history.Event{
+				Time:     now,
+				Database: dbName,
+				Member:   status.StandbyMember,
+				Type:     EventNonloggedBlocks,
+				Message:  fmt.Sprintf("%d non-logged block(s) in %d datafile(s) on the standby", blocks.Blocks, blocks.Files),
+				Details:  map[string]string{"blocks": strconv.FormatInt(blocks.Blocks, 10)},
+			})
+		}
+		record.NonloggedBlocks = blocks.Blocks
+		changed = true
+	}
+	if changed {
+		saveNologgingRecords()
+	}
+
+	status.LatestChange = record.LatestChange
+	status.DetectedAt = record.DetectedAt
+	status.Acknowledgement = record.Acknowledgement
+	status.Unacknowledged = record.unacknowledged()
+	if primary != nil && status.Unacknowledged {
+		for _, file := range primary.Files {
+			if file.Change > record.handledChange() {
+				status.Files = append(status.Files, file)
+			}
+		}
+	}
+	return status
+}
+
+// AcknowledgeNologging marks all unrecoverable operations seen so far on a
+// database as handled, e.g. after the affected datafiles were restored on the standby.
+func AcknowledgeNologging(dbName, by, comment string) (*models.NologgingAck, error) {
+	nologgingLock.Lock()
+	loadNologgingRecords()
+	record := nologgingRecords[dbName]
+	if record == nil || !record.unacknowledged() {
+		nologgingLock.Unlock()
+		return nil, ErrNothingToAcknowledge
+	}
+	ack := &models.NologgingAck{Change: record.LatestChange, By: by, Comment: comment, Time: time.Now()}
+	record.Acknowledgement = ack
+	saveNologgingRecords()
+	nologgingLock.Unlock()
+
+	recordEvent(history.Event{
+		Time:     ack.Time,
+		Database: dbName,
+		Type:     EventNologgingAcknowledged,
+		Message:  fmt.Sprintf("Unrecoverable operations up to change %d acknowledged by %s", ack.Change, by),
+		Details:  map[string]string{"change": strconv.FormatInt(ack.Change, 10), "by": by, "comment": comment},
+	})
+	updateSnapshot(dbName, func(status *models.DatabaseStatus) {
+		if status.Nologging != nil {
+			status.Nologging.Acknowledgement = ack
+			status.Nologging.Unacknowledged = false
+			status.Nologging.Files = []models.UnrecoverableFile{}
+		}
+	})
+	return ack, nil
+}
+
+// evaluateNologging raises findings for unacknowledged NOLOGGING operations,
+// non-logged blocks on the standby and a primary without FORCE LOGGING.
+func evaluateNologging(nologging *models.NologgingStatus, verdict *models.HealthVerdict) {
+	if nologging == nil {
+		return
+	}
+	if nologging.Unacknowledged {
+		verdict.Raise(models.HealthCritical, "NOLOGGING_OPERATION", nologging.PrimaryMember)
+	}
+	if nologging.NonloggedBlocks != nil && nologging.NonloggedBlocks.Blocks > 0 {
+		verdict.Raise(models.HealthCritical, "NONLOGGED_BLOCKS", nologging.StandbyMember)
+	}
+	if nologging.PrimaryError == nil && nologging.ForceLogging == "NO" {
+		verdict.Raise(models.HealthWarning, "FORCE_LOGGING_DISABLED", nologging.PrimaryMember)
+	}
+}
+
+// loadNologgingRecords reads the persisted records once. The caller must hold nologgingLock.
+func loadNologgingRecords() {
+	if nologgingRecords != nil {
+		return
+	}
+	nologgingRecords = make(map[string]*nologgingRecord)
+	if historyStore == nil {
+		return
+	}
+	if err := historyStore.LoadState(nologgingStateName, &nologgingRecords); err != nil {
+		log.Printf("Warning: Failed to load NOLOGGING state: %v", err)
+	}
+}
+
+// saveNologgingRecords persists the records. The caller must hold nologgingLock.
+func saveNologgingRecords() {
+	if historyStore == nil {
+		return
+	}
+	if err := historyStore.SaveState(nologgingStateName, nologgingRecords); err != nil {
+		log.Printf("Warning: Failed to save NOLOGGING state: %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/history"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// useTestHistory points the handlers at a history store in dir and forgets
+// the state loaded from the previous one, as after a restart.
+func useTestHistory(t *testing.T, dir string) *history.Store {
+	t.Helper()
+	store, err := history.Open(dir, 1)
+	if err != nil {
+		t.Fatalf("open history: %v", err)
+	}
+	historyStore = store
+	nologgingRecords, maintenance, operator = nil, nil, nil
+	t.Cleanup(func() {
+		store.Close()
+		historyStore = nil
+		nologgingRecords, maintenance, operator = nil, nil, nil
+	})
+	return store
+}
+
+// eventTypes returns the types of the events recorded in the store within a day of now, oldest first.
+func eventTypes(t *testing.T, store *history.Store) []string {
+	t.Helper()
+	var types []string
+	err := store.Events(time.Now().Add(-24*time.Hour), time.Now().Add(24*time.Hour), func(e history.Event) error {
+		types = append(types, e.Type)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("read events: %v", err)
+	}
+	return types
+}
+
+// primaryReport is what a primary reports with the given UNRECOVERABLE_CHANGE# per datafile.
+func primaryReport(changes map[string]int64) *models.MemberNologging {
+	report := &models.MemberNologging{Primary: true, ForceLogging: "YES"}
+	for name, change := range changes {
+		report.Files = append(report.Files, models.UnrecoverableFile{Name: name, Change: change})
+	}
+	return report
+}
+
+func TestTrackNologging(t *testing.T) {
+	dir := t.TempDir()
+	store := useTestHistory(t, dir)
+
+	// Operations from before monitoring started are the baseline.
+	status := trackNologging("ERP_DB", primaryReport(map[string]int64{"users01.dbf": 100}), nil)
+	if status.Unacknowledged || status.LatestChange != 100 || len(status.Files) != 0 {
+		t.Fatalf("first sighting: got %+v, want change 100 as the baseline without alerting", status)
+	}
+	if types := eventTypes(t, store); len(types) != 0 {
+		t.Fatalf("first sighting recorded events %v", types)
+	}
+
+	// A later operation alerts and lists only the datafiles it touched.
+	status = trackNologging("ERP_DB", primaryReport(map[string]int64{"users01.dbf": 100, "users02.dbf": 250}), nil)
+	if !status.Unacknowledged || status.LatestChange != 250 {
+		t.Fatalf("new change: got %+v, want change 250 unacknowledged", status)
+	}
+	if len(status.Files) != 1 || status.Files[0].Name != "users02.dbf" {
+		t.Errorf("new change: got files %+v, want only users02.dbf", status.Files)
+	}
+	if types := eventTypes(t, store); len(types) != 1 || types[0] != EventNologgingDetected {
+		t.Errorf("new change: got events %v, want one %s", types, EventNologgingDetected)
+	}
+
+	// The operation stays flagged while the primary cannot be queried and across restarts.
+	unreachable := &models.MemberNologging{Primary: true, Error: &models.MemberError{Category: "NETWORK"}}
+	if status = trackNologging("ERP_DB", unreachable, nil); !status.Unacknowledged {
+		t.Error("the operation was forgotten while the primary could not be queried")
+	}
+	useTestHistory(t, dir)
+	status = trackNologging("ERP_DB", primaryReport(map[string]int64{"users01.dbf": 100, "users02.dbf": 250}), nil)
+	if !status.Unacknowledged || status.LatestChange != 250 {
+		t.Fatalf("after restart: got %+v, want change 250 still unacknowledged", status)
+	}
+
+	ack, err := AcknowledgeNologging("ERP_DB", "alice", "users02 restored on standby")
+	if err != nil || ack.Change != 250 {
+		t.Fatalf("acknowledge: got %+v, %v", ack, err)
+	}
+	if _, err := AcknowledgeNologging("ERP_DB", "alice", "again"); !errors.Is(err, ErrNothingToAcknowledge) {
+		t.Errorf("second acknowledgement: got %v, want ErrNothingToAcknowledge", err)
+	}
+
+	// The acknowledgement and the baseline survive a restart.
+	useTestHistory(t, dir)
+	status = trackNologging("ERP_DB", primaryReport(map[string]int64{"users01.dbf": 100, "users02.dbf": 250}), nil)
+	if status.Unacknowledged || status.Acknowledgement == nil || status.Acknowledgement.By != "alice" {
+		t.Fatalf("after acknowledging and restarting: got %+v, want change 250 acknowledged by alice", status)
+	}
+	status = trackNologging("ERP_DB", primaryReport(map[string]int64{"users01.dbf": 300, "users02.dbf": 250}), nil)
+	if !status.Unacknowledged || len(status.Files) != 1 || status.Files[0].Name != "users01.dbf" {
+		t.Errorf("change after the acknowledgement: got %+v, want users01.dbf unacknowledged", status)
+	}
+}
+
+func TestTrackNologgingBaselineNeedsPrimary(t *testing.T) {
+	useTestHistory(t, t.TempDir())
+
+	// No baseline is taken while the primary cannot be queried.
+	unreachable := &models.MemberNologging{Primary: true, Error: &models.MemberError{Category: "NETWORK"}}
+	if status := trackNologging("ERP_DB", unreachable, nil); status.Unacknowledged || status.LatestChange != 0 {
+		t.Fatalf("unreachable primary: got %+v", status)
+	}
+	if status := trackNologging("ERP_DB", primaryReport(map[string]int64{"users01.dbf": 100}), nil); status.Unacknowledged {
+		t.Errorf("first successful query: got %+v, want the baseline without alerting", status)
+	}
+
+	// Records written before baselines existed keep alerting on their change.
+	nologgingRecords["CRM_DB"] = &nologgingRecord{LatestChange: 50}
+	if status := trackNologging("CRM_DB", primaryReport(map[string]int64{"crm01.dbf": 50}), nil); !status.Unacknowledged {
+		t.Errorf("record without a baseline: got %+v, want change 50 unacknowledged", status)
+	}
+}
+
+func TestEvaluateNologging(t *testing.T) {
+	tests := []struct {
+		name      string
+		nologging models.NologgingStatus
+		want      []string
+	}{
+		{name: "clean", nologging: models.NologgingStatus{ForceLogging: "YES"}},
+		{name: "unacknowledged", nologging: models.NologgingStatus{ForceLogging: "YES", Unacknowledged: true}, want: []string{"NOLOGGING_OPERATION"}},
+		{name: "non-logged blocks", nologging: models.NologgingStatus{ForceLogging: "YES", NonloggedBlocks: &models.NonloggedBlocks{Blocks: 8}}, want: []string{"NONLOGGED_BLOCKS"}},
+		{name: "force logging off", nologging: models.NologgingStatus{ForceLogging: "NO"}, want: []string{"FORCE_LOGGING_DISABLED"}},
+		{name: "force logging unknown", nologging: models.NologgingStatus{ForceLogging: "NO", PrimaryError: &models.MemberError{Category: "NETWORK"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict := models.HealthVerdict{Level: models.HealthOK}
+			evaluateNologging(&tt.nologging, &verdict)
+			var codes []string
+			for _, reason := range verdict.Reasons {
+				codes = append(codes, reason.Code)
+			}
+			if len(codes) != len(tt.want) || (len(codes) > 0 && codes[0] != tt.want[0]) {
+				t.Errorf("got reasons %v, want %v", codes, tt.want)
+			}
+		})
+	}
+}
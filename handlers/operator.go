@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/history"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/notify"
+)
+
+// operatorStateName is the history state document holding operatorState.
+const operatorStateName = "operator"
+
+// Event types recorded for acknowledgements and notes.
+const (
+	EventAlertAcknowledged = "ALERT_ACKNOWLEDGED"
+	EventAckCleared        = "ACK_CLEARED"
+	EventNotePinned        = "NOTE_PINNED"
+	EventNoteRemoved       = "NOTE_REMOVED"
+)
+
+var (
+	// ErrNotFiring is returned when acknowledging a reason the database does not currently report.
+	ErrNotFiring = errors.New("the alert is not firing")
+	// ErrAckNotFound is returned when a database has no acknowledgement with the given ID.
+	ErrAckNotFound = errors.New("no such acknowledgement")
+	// ErrNoteNotFound is returned when a database has no note with the given ID.
+	ErrNoteNotFound = errors.New("no such note")
+)
+
+// operatorState is what operators have recorded about the databases.
+type operatorState struct {
+	Acks  []models.AlertAck `json:"acks"`
+	Notes []models.Note     `json:"notes"`
+}
+
+var (
+	operatorLock sync.Mutex
+	operator     *operatorState // Loaded on first use
+
+	// sendEmail delivers operator event notifications; tests replace it.
+	sendEmail = notify.SendEmail
+)
+
+// maxSubjectRunes keeps notification subjects readable in a mail client.
+const maxSubjectRunes = 120
+
+// AcknowledgeAlert records that a reason the database currently reports is
+// being worked on. Acknowledging the same reason again replaces the comment and expiry.
+func AcknowledgeAlert(ack models.AlertAck) (models.AlertAck, error) {
+	if !firing(ack.Database, ack.Code, ack.Member) {
+		return ack, ErrNotFiring
+	}
+	ack.ID = newID("ack")
+	ack.Time = time.Now()
+	if ack.Expires != nil && !ack.Expires.After(ack.Time) {
+		return ack, errors.New("expiry is in the past")
+	}
+
+	operatorLock.Lock()
+	loadOperatorState()
+	acks := operator.Acks[:0]
+	for _, existing := range operator.Acks {
+		if existing.Database != ack.Database || existing.Code != ack.Code || existing.Member != ack.Member {
+			acks = append(acks, existing)
+		}
+	}
+	operator.Acks = append(acks, ack)
+	saveOperatorState()
+	operatorLock.Unlock()
+
+	details := map[string]string{"id": ack.ID, "code": ack.Code, "by": ack.By, "comment": ack.Comment}
+	if ack.Expires != nil {
+		details["expires"] = ack.Expires.Format(time.RFC3339)
+	}
+	recordOperatorEvent(history.Event{
+		Time:     ack.Time,
+		Database: ack.Database,
+		Member:   ack.Member,
+		Type:     EventAlertAcknowledged,
+		Message:  fmt.Sprintf("%s acknowledged by %s: %s", ack.Code, ack.By, ack.Comment),
+		Details:  details,
+	})
+	updateSnapshot(ack.Database, func(*models.DatabaseStatus) {})
+	return ack, nil
+}
+
+// RemoveAcknowledgement withdraws an acknowledgement before its reason clears.
+func RemoveAcknowledgement(dbName, id, by string) (models.AlertAck, error) {
+	operatorLock.Lock()
+	loadOperatorState()
+	var removed *models.AlertAck
+	for i, ack := range operator.Acks {
+		if ack.Database == dbName && ack.ID == id {
+			removed = &ack
+			operator.Acks = append(operator.Acks[:i], operator.Acks[i+1:]...)
+			saveOperatorState()
+			break
+		}
+	}
+	operatorLock.Unlock()
+	if removed == nil {
+		return models.AlertAck{}, ErrAckNotFound
+	}
+	recordAckCleared(*removed, time.Now(), "removed by "+by)
+	updateSnapshot(dbName, func(*models.DatabaseStatus) {})
+	return *removed, nil
+}
+
+// PinNote adds a note to a database.
+func PinNote(note models.Note) (models.Note, error) {
+	note.Text = strings.TrimSpace(note.Text)
+	if note.Text == "" {
+		return note, errors.New("note text is empty")
+	}
+	if _, ok := models.GetConfig().Database(note.Database); !ok {
+		return note, fmt.Errorf("unknown database '%s'", note.Database)
+	}
+	note.ID = newID("note")
+	note.Time = time.Now()
+	if note.Expires != nil && !note.Expires.After(note.Time) {
+		return note, errors.New("expiry is in the past")
+	}
+
+	operatorLock.Lock()
+	loadOperatorState()
+	operator.Notes = append(operator.Notes, note)
+	saveOperatorState()
+	operatorLock.Unlock()
+
+	recordOperatorEvent(history.Event{
+		Time:     note.Time,
+		Database: note.Database,
+		Type:     EventNotePinned,
+		Message:  fmt.Sprintf("Note by %s: %s", note.By, note.Text),
+		Details:  map[string]string{"id": note.ID, "by": note.By, "text": note.Text},
+	})
+	updateSnapshot(note.Database, func(*models.DatabaseStatus) {})
+	return note, nil
+}
+
+// RemoveNote unpins a note.
+func RemoveNote(dbName, id, by string) (models.Note, error) {
+	operatorLock.Lock()
+	loadOperatorState()
+	var removed *models.Note
+	for i, note := range operator.Notes {
+		if note.Database == dbName && note.ID == id {
+			removed = &note
+			operator.Notes = append(operator.Notes[:i], operator.Notes[i+1:]...)
+			saveOperatorState()
+			break
+		}
+	}
+	operatorLock.Unlock()
+	if removed == nil {
+		return models.Note{}, ErrNoteNotFound
+	}
+	recordNoteRemoved(*removed, time.Now(), "removed by "+by)
+	updateSnapshot(dbName, func(*models.DatabaseStatus) {})
+	return *removed, nil
+}
+
+// applyOperatorState stores the acknowledgements and notes of a database on its
+// status and flags the acknowledged reasons in its verdicts.
+func applyOperatorState(status *models.DatabaseStatus, t time.Time) {
+	operatorLock.Lock()
+	loadOperatorState()
+	status.Acknowledgements = []models.AlertAck{}
+	for _, ack := range operator.Acks {
+		if ack.Database == status.Name && !ack.Expired(t) {
+			status.Acknowledgements = append(status.Acknowledgements, ack)
+		}
+	}
+	status.Notes = []models.Note{}
+	for _, note := range operator.Notes {
+		if note.Database == status.Name && !note.Expired(t) {
+			status.Notes = append(status.Notes, note)
+		}
+	}
+	operatorLock.Unlock()
+
+	for _, verdict := range []*models.HealthVerdict{&status.ProductionHealth, &status.DisasterHealth, &status.Health} {
+		for i, reason := range verdict.Reasons {
+			for _, ack := range status.Acknowledgements {
+				if ack.Matches(reason) {
+					verdict.Reasons[i].Acknowledged = true
+				}
+			}
+		}
+	}
+}
+
+// trackOperatorState drops acknowledgements whose reason cleared or that
+// expired, and notes that expired, recording an event for each.
+func trackOperatorState(statuses []models.DatabaseStatus, now time.Time) {
+	checked := make(map[string]*models.DatabaseStatus, len(statuses))
+	for i := range statuses {
+		checked[statuses[i].Name] = &statuses[i]
+	}
+
+	operatorLock.Lock()
+	defer operatorLock.Unlock()
+	loadOperatorState()
+	changed := false
+
+	acks := operator.Acks[:0]
+	for _, ack := range operator.Acks {
+		status, ok := checked[ack.Database]
+		switch {
+		case ack.Expired(now):
+			recordAckCleared(ack, now, "expired")
+		case ok && !hasReason(status.Health, ack.Code, ack.Member):
+			recordAckCleared(ack, now, "resolved")
+		default:
+			acks = append(acks, ack)
+			continue
+		}
+		changed = true
+	}
+	operator.Acks = acks
+
+	notes := operator.Notes[:0]
+	for _, note := range operator.Notes {
+		if note.Expired(now) {
+			recordNoteRemoved(note, now, "expired")
+			changed = true
+			continue
+		}
+		notes = append(notes, note)
+	}
+	operator.Notes = notes
+	if changed {
+		saveOperatorState()
+	}
+}
+
+// firing reports whether the latest check of a database reports the reason.
+func firing(dbName, code, member string) bool {
+	snapshotLock.RLock()
+	defer snapshotLock.RUnlock()
+	for _, status := range snapshot {
+		if status.Name == dbName {
+			return hasReason(status.Health, code, member)
+		}
+	}
+	return false
+}
+
+func hasReason(verdict models.HealthVerdict, code, member string) bool {
+	for _, reason := range verdict.Reasons {
+		if reason.Code == code && reason.Member == member {
+			return true
+		}
+	}
+	return false
+}
+
+func recordAckCleared(ack models.AlertAck, t time.Time, why string) {
+	recordOperatorEvent(history.Event{
+		Time:     t,
+		Database: ack.Database,
+		Member:   ack.Member,
+		Type:     EventAckCleared,
+		Message:  fmt.Sprintf("Acknowledgement of %s by %s cleared: %s", ack.Code, ack.By, why),
+		Details:  map[string]string{"id": ack.ID, "code": ack.Code, "reason": why},
+	})
+}
+
+func recordNoteRemoved(note models.Note, t time.Time, why string) {
+	recordOperatorEvent(history.Event{
+		Time:     t,
+		Database: note.Database,
+		Type:     EventNoteRemoved,
+		Message:  fmt.Sprintf("Note by %s %s: %s", note.By, why, note.Text),
+		Details:  map[string]string{"id": note.ID, "reason": why},
+	})
+}
+
+// recordOperatorEvent records an acknowledgement or note event and emails it to
+// the notifier.operator_events recipients. The mail is sent in the background,
+// so a slow mail server holds up neither the operator nor the check cycle.
+func recordOperatorEvent(event history.Event) {
+	recordEvent(event)
+	cfg := models.GetConfig().Notifier
+	if len(cfg.OperatorEvents.Email) == 0 {
+		return
+	}
+	subject := fmt.Sprintf("%s: %s", event.Database, event.Message)
+	if runes := []rune(subject); len(runes) > maxSubjectRunes {
+		subject = string(runes[:maxSubjectRunes-1]) + "…"
+	}
+	msg := notify.Message{To: cfg.OperatorEvents.Email, Subject: subject, Text: operatorEventText(event)}
+	send := sendEmail
+	go func() {
+		if err := send(cfg.Email, msg); err != nil {
+			log.Printf("Warning: Failed to email %s event for %s: %v", event.Type, event.Database, err)
+		}
+	}()
+}
+
+// operatorEventText is the body of an operator event notification.
+func operatorEventText(event history.Event) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", event.Message)
+	fmt.Fprintf(&b, "Database: %s\n", event.Database)
+	if event.Member != "" {
+		fmt.Fprintf(&b, "Member: %s\n", event.Member)
+	}
+	fmt.Fprintf(&b, "Event: %s\n", event.Type)
+	fmt.Fprintf(&b, "Time: %s\n", event.Time.Format(time.RFC3339))
+	keys := make([]string, 0, len(event.Details))
+	for key := range event.Details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s: %s\n", key, event.Details[key])
+	}
+	return b.String()
+}
+
+// loadOperatorState reads the persisted state once. The caller must hold operatorLock.
+func loadOperatorState() {
+	if operator != nil {
+		return
+	}
+	operator = &operatorState{}
+	if historyStore == nil {
+		return
+	}
+	if err := historyStore.LoadState(operatorStateName, operator); err != nil {
+		log.Printf("Warning: Failed to load acknowledgements and notes: %v", err)
+	}
+}
+
+// saveOperatorState persists the state. The caller must hold operatorLock.
+func saveOperatorState() {
+	if historyStore == nil {
+		return
+	}
+	if err := historyStore.SaveState(operatorStateName, operator); err != nil {
+		log.Printf("Warning: Failed to save acknowledgements and notes: %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/notify"
+)
+
+// useTestConfig loads a configuration from content and restores an empty one afterwards.
+func useTestConfig(t *testing.T, content string) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := models.LoadConfig(path); err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	t.Cleanup(func() {
+		empty := filepath.Join(dir, "empty.yaml")
+		os.WriteFile(empty, nil, 0o600)
+		models.LoadConfig(empty)
+	})
+}
+
+// captureEmail replaces the mail delivery and returns the messages sent.
+func captureEmail(t *testing.T) <-chan notify.Message {
+	t.Helper()
+	sent := make(chan notify.Message, 10)
+	sendEmail = func(cfg models.EmailConfig, msg notify.Message) error {
+		sent <- msg
+		return nil
+	}
+	t.Cleanup(func() { sendEmail = notify.SendEmail })
+	return sent
+}
+
+// nextEmail waits for the next message sent in the background.
+func nextEmail(t *testing.T, sent <-chan notify.Message) notify.Message {
+	t.Helper()
+	select {
+	case msg := <-sent:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification was sent")
+	}
+	return notify.Message{}
+}
+
+func TestOperatorEventsAreEmailed(t *testing.T) {
+	useTestConfig(t, `
+notifier:
+  email:
+    smtp_host: "smtp.example.com"
+    from: "dashboard@example.com"
+  operator_events:
+    email: ["dba-oncall@example.com"]
+databases:
+  - name: ERP_DB
+`)
+	useTestHistory(t, t.TempDir())
+	sent := captureEmail(t)
+
+	snapshotLock.Lock()
+	snapshot = []models.DatabaseStatus{degradedStatus()}
+	snapshotLock.Unlock()
+	t.Cleanup(func() {
+		snapshotLock.Lock()
+		snapshot = nil
+		snapshotLock.Unlock()
+	})
+
+	tests := []struct {
+		name     string
+		action   func() error
+		subject  string
+		contains []string
+	}{
+		{
+			name: "acknowledged",
+			action: func() error {
+				_, err := AcknowledgeAlert(models.AlertAck{Database: "ERP_DB", Code: "LAG_CRITICAL", Member: models.MemberDisaster, By: "alice", Comment: "apply restarting"})
+				return err
+			},
+			subject:  "ERP_DB: LAG_CRITICAL acknowledged by alice: apply restarting",
+			contains: []string{"Member: disaster", "Event: " + EventAlertAcknowledged, "comment: apply restarting"},
+		},
+		{
+			name: "acknowledgement cleared",
+			action: func() error {
+				trackOperatorState([]models.DatabaseStatus{{Name: "ERP_DB"}}, time.Now())
+				return nil
+			},
+			subject:  "ERP_DB: Acknowledgement of LAG_CRITICAL by alice cleared: resolved",
+			contains: []string{"Event: " + EventAckCleared, "reason: resolved"},
+		},
+		{
+			name: "note pinned",
+			action: func() error {
+				expires := time.Now().Add(time.Hour)
+				_, err := PinNote(models.Note{Database: "ERP_DB", By: "bob", Text: "apply paused for index rebuild until 14:00", Expires: &expires})
+				return err
+			},
+			subject:  "ERP_DB: Note by bob: apply paused for index rebuild until 14:00",
+			contains: []string{"Event: " + EventNotePinned, "text: apply paused for index rebuild until 14:00"},
+		},
+		{
+			name: "note expired",
+			action: func() error {
+				trackOperatorState(nil, time.Now().Add(2*time.Hour))
+				return nil
+			},
+			subject:  "ERP_DB: Note by bob expired: apply paused for index rebuild until 14:00",
+			contains: []string{"Event: " + EventNoteRemoved, "reason: expired"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.action(); err != nil {
+				t.Fatalf("action: %v", err)
+			}
+			msg := nextEmail(t, sent)
+			if msg.Subject != tt.subject {
+				t.Errorf("got subject %q, want %q", msg.Subject, tt.subject)
+			}
+			if len(msg.To) != 1 || msg.To[0] != "dba-oncall@example.com" {
+				t.Errorf("got recipients %v", msg.To)
+			}
+			for _, text := range tt.contains {
+				if !strings.Contains(msg.Text, text) {
+					t.Errorf("body does not contain %q:\n%s", text, msg.Text)
+				}
+			}
+		})
+	}
+}
+
+func TestOperatorEventsWithoutRecipients(t *testing.T) {
+	useTestConfig(t, "databases:\n  - name: ERP_DB\n")
+	useTestHistory(t, t.TempDir())
+	sent := captureEmail(t)
+
+	if _, err := PinNote(models.Note{Database: "ERP_DB", By: "bob", Text: "failover test tonight"}); err != nil {
+		t.Fatalf("pin note: %v", err)
+	}
+	select {
+	case msg := <-sent:
+		t.Errorf("sent %q without configured recipients", msg.Subject)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/util"
+)
+
+// seedPDB is the template PDB; it is always read only and never opened read write.
+const seedPDB = "PDB$SEED"
+
+// Issue codes reported for pluggable databases, raised as health reasons as is.
+const (
+	PDBMissing          = "PDB_MISSING"
+	PDBNotOpen          = "PDB_NOT_OPEN"
+	PDBRestricted       = "PDB_RESTRICTED"
+	PDBRecoveryDisabled = "PDB_RECOVERY_DISABLED"
+)
+
+// collectPDBs reads V$PDBS on a member. The result is nil for a non-CDB.
+func collectPDBs(oraDB *util.OracleDB, dbConfig models.DatabaseConfig, ip, instanceType string) ([]models.PDBState, *models.MemberError) {
+	pdbs, err := oraDB.GetPDBs()
+	if err != nil {
+		log.Printf("Warning: Failed to get pluggable databases for %s %s (%s:%d): %v", instanceType, dbConfig.Name, ip, dbConfig.Port, err)
+		return nil, util.ClassifyOracleError(err, models.StagePDBs)
+	}
+	if len(pdbs) == 0 {
+		return nil, nil
+	}
+	return pdbs, nil
+}
+
+// pdbMember is one side of the PDB comparison.
+type pdbMember struct {
+	member   string
+	role     string
+	openMode string // Open mode of the CDB
+	pdbs     map[string]*models.PDBState
+	queried  bool // V$PDBS could be read, so a missing PDB is really missing
+}
+
+// comparePDBs pairs the PDBs of both members by name and checks each side
+// against what its role implies: read write on the primary, open read only on an
+// Active Data Guard standby, and never restricted or excluded from recovery.
+func comparePDBs(prod, dr pdbMember) *models.PDBReport {
+	report := &models.PDBReport{PDBs: []models.PDBComparison{}}
+	var names []string
+	seen := make(map[string]bool)
+	for _, side := range []pdbMember{prod, dr} {
+		for _, pdb := range orderedPDBs(side.pdbs) {
+			if !seen[pdb.Name] {
+				seen[pdb.Name] = true
+				names = append(names, pdb.Name)
+			}
+		}
+	}
+
+	for _, name := range names {
+		item := models.PDBComparison{Name: name, Production: prod.pdbs[name], Disaster: dr.pdbs[name], Issues: []models.PDBIssue{}}
+		raise := func(level, code, member string) {
+			item.Issues = append(item.Issues, models.PDBIssue{Code: code, Member: member, Level: level})
+		}
+		primaryOpen := false
+		for _, side := range []pdbMember{prod, dr} {
+			if pdb := side.pdbs[name]; pdb != nil && side.role == "PRIMARY" {
+				primaryOpen = pdb.OpenMode == "READ WRITE"
+			}
+		}
+		for _, side := range []pdbMember{prod, dr} {
+			pdb := side.pdbs[name]
+			if pdb == nil {
+				if side.queried {
+					level := models.HealthCritical // A PDB missing on the standby is not protected at all
+					if side.role == "PRIMARY" {
+						level = models.HealthWarning
+					}
+					raise(level, PDBMissing, side.member)
+				}
+				continue
+			}
+			if pdb.Restricted == "YES" {
+				raise(models.HealthWarning, PDBRestricted, side.member)
+			}
+			if name == seedPDB {
+				continue
+			}
+			switch side.role {
+			case "PRIMARY":
+				if pdb.OpenMode != "READ WRITE" {
+					raise(models.HealthWarning, PDBNotOpen, side.member)
+				}
+			case "PHYSICAL STANDBY":
+				if pdb.RecoveryStatus == "DISABLED" {
+					raise(models.HealthCritical, PDBRecoveryDisabled, side.member)
+				}
+				// On an open standby CDB the PDBs of open primary PDBs are expected to be open too.
+				if primaryOpen && strings.HasPrefix(side.openMode, "READ ONLY") && !strings.HasPrefix(pdb.OpenMode, "READ ONLY") {
+					raise(models.HealthWarning, PDBNotOpen, side.member)
+				}
+			}
+		}
+		if len(item.Issues) > 0 {
+			report.Mismatches++
+		}
+		report.PDBs = append(report.PDBs, item)
+	}
+	return report
+}
+
+// orderedPDBs returns the PDBs of a member by container ID.
+func orderedPDBs(pdbs map[string]*models.PDBState) []*models.PDBState {
+	list := make([]*models.PDBState, 0, len(pdbs))
+	for _, pdb := range pdbs {
+		list = append(list, pdb)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ConID < list[j].ConID })
+	return list
+}
+
+// pdbsByName indexes the PDBs of a member.
+func pdbsByName(pdbs []models.PDBState) map[string]*models.PDBState {
+	byName := make(map[string]*models.PDBState, len(pdbs))
+	for i := range pdbs {
+		byName[pdbs[i].Name] = &pdbs[i]
+	}
+	return byName
+}
+
+// evaluatePDBs raises each PDB issue once per member.
+func evaluatePDBs(report *models.PDBReport, verdict *models.HealthVerdict) {
+	if report == nil {
+		return
+	}
+	raised := make(map[models.PDBIssue]bool)
+	for _, item := range report.PDBs {
+		for _, issue := range item.Issues {
+			if !raised[issue] {
+				raised[issue] = true
+				verdict.Raise(issue.Level, issue.Code, issue.Member)
+			}
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/history"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+const (
+	// spaceRateWindow is the span of history used to estimate the archive generation rate.
+	spaceRateWindow = 6 * time.Hour
+	// minRateSpan is the least history needed before a rate is reported.
+	minRateSpan = 30 * time.Minute
+)
+
+// estimateSpaceTrend fills in the archive generation rate and the time until the
+// recovery area is full for both members, based on the recorded history.
+func estimateSpaceTrend(status *models.DatabaseStatus) {
+	if historyStore == nil {
+		return
+	}
+	now := time.Now()
+	samples := historyStore.Recent(status.Name, now.Add(-spaceRateWindow))
+	estimateMemberTrend(status.ProductionSpace, samples, func(s history.Sample) history.MemberSample { return s.Production }, now)
+	estimateMemberTrend(status.DisasterSpace, samples, func(s history.Sample) history.MemberSample { return s.Disaster }, now)
+}
+
+// estimateMemberTrend derives the generation rate from the growth of the archived
+// logs in the recovery area. Only increases are counted, as decreases are files
+// being deleted; the free space includes what the database can reclaim on its own.
+func estimateMemberTrend(space *models.SpaceUsage, samples []history.Sample, member func(history.Sample) history.MemberSample, now time.Time) {
+	if space == nil || space.RecoveryArea == nil {
+		return
+	}
+	fra := space.RecoveryArea
+
+	var first time.Time
+	var previous, generated int64
+	seen := false
+	for _, sample := range samples {
+		archived := member(sample).ArchivedLogBytes
+		if archived == nil {
+			continue
+		}
+		if !seen {
+			first, seen = sample.Time, true
+		} else if *archived > previous {
+			generated += *archived - previous
+		}
+		previous = *archived
+	}
+	if !seen || now.Sub(first) < minRateSpan {
+		return
+	}
+	if current := fra.ArchivedLogBytes(); current > previous {
+		generated += current - previous
+	}
+
+	rate := float64(generated) / now.Sub(first).Hours()
+	fra.ArchiveBytesPerHour = &rate
+	if rate > 0 {
+		hours := float64(fra.LimitBytes-fra.UsedBytes+fra.ReclaimableBytes) / rate
+		fra.HoursToFull = &hours
+	}
+}
+
+// evaluateSpace judges the recovery area and the local archive destinations of a member.
+// The level of these findings alone is stored on the space usage for the card gauge.
+func evaluateSpace(space *models.SpaceUsage, member string, cfg models.HealthConfig, memberVerdict *models.HealthVerdict) {
+	if space == nil {
+		return
+	}
+	verdict := &models.HealthVerdict{Level: models.HealthOK}
+	defer func() {
+		space.Level = verdict.Level
+		mergeVerdict(memberVerdict, *verdict)
+	}()
+
+	if fra := space.RecoveryArea; fra != nil {
+		switch {
+		case fra.UsedPercent >= cfg.SpaceCriticalPercent:
+			verdict.Raise(models.HealthCritical, "FRA_CRITICAL", member)
+		case fra.UsedPercent >= cfg.SpaceWarningPercent:
+			verdict.Raise(models.HealthWarning, "FRA_WARNING", member)
+		}
+		if fra.HoursToFull != nil {
+			switch {
+			case *fra.HoursToFull < cfg.FullCriticalHours:
+				verdict.Raise(models.HealthCritical, "FRA_FULL_SOON", member)
+			case *fra.HoursToFull < cfg.FullWarningHours:
+				verdict.Raise(models.HealthWarning, "FRA_FULL_SOON", member)
+			}
+		}
+	}
+
+	destLevel := models.HealthOK
+	spaceLevel := models.HealthOK
+	for _, dest := range space.ArchiveDests {
+		switch dest.Status {
+		case "ERROR", "FULL", "BAD PARAM":
+			destLevel = models.HealthCritical
+		}
+		// The recovery area destination was judged above.
+		if dest.UsedPercent == nil || dest.Destination == "USE_DB_RECOVERY_FILE_DEST" {
+			continue
+		}
+		switch {
+		case *dest.UsedPercent >= cfg.SpaceCriticalPercent:
+			spaceLevel = models.HealthCritical
+		case *dest.UsedPercent >= cfg.SpaceWarningPercent && spaceLevel == models.HealthOK:
+			spaceLevel = models.HealthWarning
+		}
+	}
+	if destLevel != models.HealthOK {
+		verdict.Raise(destLevel, "ARCHIVE_DEST_ERROR", member)
+	}
+	if spaceLevel != models.HealthOK {
+		verdict.Raise(spaceLevel, "ARCHIVE_DEST_SPACE", member)
+	}
+}
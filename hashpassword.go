@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/auth"
+)
+
+// runHashPassword implements the "hash-password" subcommand: it reads a
+// password from standard input and prints the bcrypt hash for auth.users.
+func runHashPassword() int {
+	fmt.Fprint(os.Stderr, "Password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\nFailed to read password: %v\n", err)
+		} else {
+			fmt.Fprintln(os.Stderr, "Password must not be empty")
+		}
+		return 1
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to hash password: %v\n", err)
+		return 1
+	}
+	fmt.Println(hash)
+	return 0
+}
@@ -0,0 +1,72 @@
+package history
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const eventPrefix = "events-"
+
+// maxRecentEvents is how many events are kept in memory for the API.
+const maxRecentEvents = 500
+
+// Event is something that happened to a database and is worth keeping beyond
+// the current state, e.g. a detected NOLOGGING operation or its acknowledgement.
+type Event struct {
+	Time     time.Time         `json:"time"`
+	Database string            `json:"database"`
+	Member   string            `json:"member,omitempty"`
+	Type     string            `json:"type"`
+	Message  string            `json:"message"`
+	Details  map[string]string `json:"details,omitempty"`
+}
+
+// RecordEvent stores an event.
+func (s *Store) RecordEvent(event Event) error {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	if len(s.events) > maxRecentEvents {
+		s.events = s.events[len(s.events)-maxRecentEvents:]
+	}
+	if s.dir == "" || s.readOnly {
+		return nil
+	}
+	return s.appendRecords(eventPrefix, event.Time, []interface{}{event})
+}
+
+// Events calls fn for every event recorded in [from, to), oldest first.
+func (s *Store) Events(from, to time.Time, fn func(Event) error) error {
+	if s.dir == "" {
+		s.mu.RLock()
+		events := make([]Event, len(s.events))
+		copy(events, s.events)
+		s.mu.RUnlock()
+		for _, event := range events {
+			if !event.Time.Before(from) && event.Time.Before(to) {
+				if err := fn(event); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	for _, path := range s.files(eventPrefix, from, to) {
+		if err := readFile(path, func(line []byte) error {
+			var event Event
+			if err := json.Unmarshal(line, &event); err != nil {
+				return nil // Skip lines damaged by a crash mid-write
+			}
+			if event.Time.Before(from) || !event.Time.Before(to) {
+				return nil
+			}
+			return fn(event)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
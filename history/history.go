@@ -0,0 +1,299 @@
+// Package history records the result of every check cycle so that trends,
+// events and reports can be computed over time. Samples are kept in memory for
+// a short window and appended to one JSON-lines file per day.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memoryWindow is how much history is kept in memory for trend estimates.
+const memoryWindow = 24 * time.Hour
+
+const (
+	samplePrefix = "samples-"
+	fileSuffix   = ".jsonl"
+	dayLayout    = "2006-01-02"
+)
+
+// Store keeps recent samples in memory and persists all samples to disk.
+type Store struct {
+	mu        sync.RWMutex
+	dir       string
+	readOnly  bool // Nothing is written to dir
+	retention time.Duration
+	recent    map[string][]Sample // Per database, oldest first
+	open      map[string]*dayFile // Open file per prefix
+	events    []Event             // Recent events, oldest first
+	pruned    string              // Day of the last retention run
+}
+
+// dayFile is the file currently appended to for one kind of record.
+type dayFile struct {
+	file *os.File
+	day  string
+}
+
+// Open creates a store. With an empty dir, history is only kept in memory.
+// Samples of the last day found on disk are loaded so that trend estimates
+// survive a restart.
+func Open(dir string, retentionDays int) (*Store, error) {
+	s := &Store{
+		dir:       dir,
+		retention: time.Duration(retentionDays) * 24 * time.Hour,
+		recent:    make(map[string][]Sample),
+		open:      make(map[string]*dayFile),
+	}
+	if dir == "" {
+		return s, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create history directory '%s': %w", dir, err)
+	}
+	return s, s.loadRecent()
+}
+
+// OpenReadOnly opens the history in dir for a process that shares it with a
+// running dashboard, such as a one-shot check: the state documents and recent
+// samples are read, but samples, events and state changes are only kept in
+// memory. A missing dir reads as empty.
+func OpenReadOnly(dir string) (*Store, error) {
+	s := &Store{
+		dir:      dir,
+		readOnly: true,
+		recent:   make(map[string][]Sample),
+		open:     make(map[string]*dayFile),
+	}
+	if dir == "" {
+		return s, nil
+	}
+	return s, s.loadRecent()
+}
+
+// loadRecent reads the samples of the last memoryWindow from disk.
+func (s *Store) loadRecent() error {
+	since := time.Now().Add(-memoryWindow)
+	return s.Samples(since, time.Now(), func(sample Sample) error {
+		s.recent[sample.Database] = append(s.recent[sample.Database], sample)
+		return nil
+	})
+}
+
+// Record stores the samples of one check cycle.
+func (s *Store) Record(samples []Sample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := time.Now().Add(-memoryWindow)
+	for _, sample := range samples {
+		list := append(s.recent[sample.Database], sample)
+		idx := sort.Search(len(list), func(i int) bool { return list[i].Time.After(cutoff) })
+		s.recent[sample.Database] = list[idx:]
+	}
+
+	if s.dir == "" || s.readOnly {
+		return nil
+	}
+	records := make([]interface{}, len(samples))
+	for i, sample := range samples {
+		records[i] = sample
+	}
+	return s.appendRecords(samplePrefix, samples[0].Time, records)
+}
+
+// appendRecords writes records to the file of the given prefix for the day of t.
+// The caller must hold the lock.
+func (s *Store) appendRecords(prefix string, t time.Time, records []interface{}) error {
+	day := t.UTC().Format(dayLayout)
+	file, err := s.openDay(prefix, day)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	enc := json.NewEncoder(w)
+	for _, record := range records {
+		if err := enc.Encode(record); err != nil {
+			return fmt.Errorf("failed to encode history record: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	if s.pruned != day {
+		s.pruned = day
+		s.prune()
+	}
+	return nil
+}
+
+// Horizon returns the earliest time the store can still hold samples for:
+// the memory window without a directory, the retention period otherwise. It
+// is zero when nothing is pruned, as for a read-only store.
+func (s *Store) Horizon(now time.Time) time.Time {
+	switch {
+	case s.dir == "":
+		return now.Add(-memoryWindow)
+	case s.retention > 0:
+		return now.Add(-s.retention)
+	}
+	return time.Time{}
+}
+
+// Persistent reports whether samples are read from and written to disk.
+func (s *Store) Persistent() bool {
+	return s.dir != ""
+}
+
+// Recent returns the in-memory samples of a database recorded after since, oldest first.
+func (s *Store) Recent(database string, since time.Time) []Sample {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := s.recent[database]
+	idx := sort.Search(len(list), func(i int) bool { return list[i].Time.After(since) })
+	result := make([]Sample, len(list)-idx)
+	copy(result, list[idx:])
+	return result
+}
+
+// Samples calls fn for every persisted sample recorded in [from, to), oldest first.
+// Files are streamed, so long ranges do not have to fit into memory.
+func (s *Store) Samples(from, to time.Time, fn func(Sample) error) error {
+	if s.dir == "" {
+		return s.recentSamples(from, to, fn)
+	}
+	for _, path := range s.files(samplePrefix, from, to) {
+		if err := readFile(path, func(line []byte) error {
+			var sample Sample
+			if err := json.Unmarshal(line, &sample); err != nil {
+				return nil // Skip lines damaged by a crash mid-write
+			}
+			if sample.Time.Before(from) || !sample.Time.Before(to) {
+				return nil
+			}
+			return fn(sample)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the current history files.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var firstErr error
+	for prefix, current := range s.open {
+		if err := current.file.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(s.open, prefix)
+	}
+	return firstErr
+}
+
+// recentSamples serves Samples from memory when nothing is persisted.
+func (s *Store) recentSamples(from, to time.Time, fn func(Sample) error) error {
+	s.mu.RLock()
+	var samples []Sample
+	for _, list := range s.recent {
+		for _, sample := range list {
+			if !sample.Time.Before(from) && sample.Time.Before(to) {
+				samples = append(samples, sample)
+			}
+		}
+	}
+	s.mu.RUnlock()
+	sort.SliceStable(samples, func(i, j int) bool { return samples[i].Time.Before(samples[j].Time) })
+	for _, sample := range samples {
+		if err := fn(sample); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openDay returns the file of the given prefix and day, opened for appending.
+func (s *Store) openDay(prefix, day string) (*os.File, error) {
+	current := s.open[prefix]
+	if current != nil && current.day == day {
+		return current.file, nil
+	}
+	if current != nil {
+		current.file.Close()
+		delete(s.open, prefix)
+	}
+	path := filepath.Join(s.dir, prefix+day+fileSuffix)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file '%s': %w", path, err)
+	}
+	s.open[prefix] = &dayFile{file: file, day: day}
+	return file, nil
+}
+
+// files returns the daily files with the given prefix covering [from, to), oldest first.
+func (s *Store) files(prefix string, from, to time.Time) []string {
+	matches, _ := filepath.Glob(filepath.Join(s.dir, prefix+"*"+fileSuffix))
+	sort.Strings(matches)
+	fromDay, toDay := from.UTC().Format(dayLayout), to.UTC().Format(dayLayout)
+	var result []string
+	for _, path := range matches {
+		day := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), prefix), fileSuffix)
+		if day >= fromDay && day <= toDay {
+			result = append(result, path)
+		}
+	}
+	return result
+}
+
+// prune deletes files older than the retention period.
+func (s *Store) prune() {
+	cutoff := time.Now().Add(-s.retention).UTC().Format(dayLayout)
+	matches, _ := filepath.Glob(filepath.Join(s.dir, "*"+fileSuffix))
+	for _, path := range matches {
+		base := strings.TrimSuffix(filepath.Base(path), fileSuffix)
+		if len(base) < len(dayLayout) {
+			continue
+		}
+		day := base[len(base)-len(dayLayout):]
+		if _, err := time.Parse(dayLayout, day); err == nil && day < cutoff {
+			os.Remove(path)
+		}
+	}
+}
+
+// readFile calls fn for each non-empty line of a file.
+func readFile(path string, fn func([]byte) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to open history file '%s': %w", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		if err := fn(scanner.Bytes()); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,74 @@
+package history
+
+import (
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+// Sample is the recorded state of one database at the end of a check cycle.
+type Sample struct {
+	Time               time.Time    `json:"time"`
+	Database           string       `json:"database"`
+	Level              string       `json:"level"`
+	Reasons            []string     `json:"reasons,omitempty"`
+	LoadBalancerTarget string       `json:"lb_target"`
+	Production         MemberSample `json:"production"`
+	Disaster           MemberSample `json:"disaster"`
+	Maintenance        bool         `json:"maintenance,omitempty"` // The whole database was under maintenance; excluded from SLA
+}
+
+// MemberSample is the recorded state of one member.
+type MemberSample struct {
+	Role      string  `json:"role"`
+	Status    string  `json:"status"`
+	Connected bool    `json:"connected"`
+	Lag       float64 `json:"lag"` // Larger of the native and heartbeat lag in seconds; -1 when unknown
+
+	Maintenance bool `json:"maintenance,omitempty"` // The member was under maintenance; excluded from SLA
+
+	FRAUsedPercent   *float64 `json:"fra_used_percent,omitempty"`
+	ArchivedLogBytes *int64   `json:"archived_log_bytes,omitempty"` // Archived logs in the recovery area
+}
+
+// NewSample builds a sample from a checked database status.
+func NewSample(status models.DatabaseStatus, t time.Time) Sample {
+	sample := Sample{
+		Time:               t,
+		Database:           status.Name,
+		Level:              status.Health.Level,
+		LoadBalancerTarget: status.LoadBalancerTarget,
+		Production:         newMemberSample(status.ProductionRole, status.ProductionStatus, status.ProductionDbConnect, status.ProductionDgDelay, status.ProductionHeartbeat, status.ProductionSpace),
+		Disaster:           newMemberSample(status.DisasterRole, status.DisasterStatus, status.DisasterDbConnect, status.DisasterDgDelay, status.DisasterHeartbeat, status.DisasterSpace),
+	}
+	for _, reason := range status.Health.Reasons {
+		sample.Reasons = append(sample.Reasons, reason.Code)
+	}
+	for _, period := range status.Maintenance {
+		switch period.Member {
+		case models.MemberProduction:
+			sample.Production.Maintenance = true
+		case models.MemberDisaster:
+			sample.Disaster.Maintenance = true
+		default:
+			sample.Maintenance = true
+			sample.Production.Maintenance = true
+			sample.Disaster.Maintenance = true
+		}
+	}
+	return sample
+}
+
+func newMemberSample(role, status string, connected bool, delay int, heartbeat *models.HeartbeatLag, space *models.SpaceUsage) MemberSample {
+	member := MemberSample{Role: role, Status: status, Connected: connected, Lag: float64(delay)}
+	if heartbeat != nil && heartbeat.Error == nil && heartbeat.LagSeconds > member.Lag {
+		member.Lag = heartbeat.LagSeconds
+	}
+	if space != nil && space.RecoveryArea != nil {
+		used := space.RecoveryArea.UsedPercent
+		archived := space.RecoveryArea.ArchivedLogBytes()
+		member.FRAUsedPercent = &used
+		member.ArchivedLogBytes = &archived
+	}
+	return member
+}
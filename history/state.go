@@ -0,0 +1,53 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const stateSuffix = ".state.json"
+
+// LoadState reads the state document of the given name into v. Nothing is
+// read when history is only kept in memory or the document does not exist yet.
+func (s *Store) LoadState(name string, v interface{}) error {
+	if s.dir == "" {
+		return nil
+	}
+	path := filepath.Join(s.dir, name+stateSuffix)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read state file '%s': %w", path, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse state file '%s': %w", path, err)
+	}
+	return nil
+}
+
+// SaveState replaces the state document of the given name with v. The file is
+// written next to its final path and renamed, so a crash never leaves it half
+// written. A read-only store keeps the document unchanged.
+func (s *Store) SaveState(name string, v interface{}) error {
+	if s.dir == "" || s.readOnly {
+		return nil
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state '%s': %w", name, err)
+	}
+	path := filepath.Join(s.dir, name+stateSuffix)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write state file '%s': %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace state file '%s': %w", path, err)
+	}
+	return nil
+}
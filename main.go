@@ -29,6 +29,10 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "Go Oracle DR Dashboard - A web-based monitoring tool for Oracle Data Guard.\n\n")
 		flag.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nCommands:\n")
+		fmt.Fprintf(os.Stderr, "  check          Check the databases once, print table, json, yaml or nagios output and exit with 0-3\n")
+		fmt.Fprintf(os.Stderr, "  diagnose       Check the monitoring account's privileges and password expiry, then exit\n")
+		fmt.Fprintf(os.Stderr, "  hash-password  Read a password from standard input and print its bcrypt hash for auth.users\n")
 		fmt.Fprintf(os.Stderr, "\nFor more information, visit: https://github.com/goodwaysIT/go-oracle-dr-dashboard\n")
 	}
 
@@ -44,6 +48,16 @@ func main() {
 		return
 	}
 
+	if flag.Arg(0) == "check" {
+		os.Exit(runCheck(*configFile, flag.Args()[1:]))
+	}
+	if flag.Arg(0) == "diagnose" {
+		os.Exit(runDiagnose(*configFile, flag.Args()[1:]))
+	}
+	if flag.Arg(0) == "hash-password" {
+		os.Exit(runHashPassword())
+	}
+
 	// Create sub-filesystems to avoid path issues in the server package
 	staticFS, err := fs.Sub(staticFiles, "static")
 	if err != nil {
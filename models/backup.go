@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Backup types reported in BackupStatus.
+const (
+	BackupFull        = "FULL"
+	BackupIncremental = "INCREMENTAL"
+	BackupArchivelog  = "ARCHIVELOG"
+)
+
+// BackupJob is one row of V$RMAN_BACKUP_JOB_DETAILS.
+type BackupJob struct {
+	Member          string     `json:"member"` // "production" or "disaster"
+	InputType       string     `json:"input_type"`
+	Status          string     `json:"status"`
+	StartTime       time.Time  `json:"start_time"`
+	EndTime         *time.Time `json:"end_time,omitempty"`
+	DurationSeconds int64      `json:"duration_seconds"`
+	OutputBytes     int64      `json:"output_bytes"`
+	DeviceType      string     `json:"device_type"`
+}
+
+// BackupStatus summarizes the RMAN backups of a database across both members,
+// since backups are often taken on the standby.
+type BackupStatus struct {
+	Types           []BackupTypeStatus `json:"types"`
+	ProductionError *MemberError       `json:"production_error,omitempty"`
+	DisasterError   *MemberError       `json:"disaster_error,omitempty"`
+}
+
+// BackupTypeStatus is the latest backup of one type and whether it meets its SLA.
+type BackupTypeStatus struct {
+	Type        string     `json:"type"` // FULL, INCREMENTAL or ARCHIVELOG
+	LastSuccess *BackupJob `json:"last_success"`
+	LastFailure *BackupJob `json:"last_failure"` // Only set when it is more recent than the last success
+	AgeHours    *float64   `json:"age_hours"`    // Time since the last successful backup ended
+	SLAHours    float64    `json:"sla_hours"`
+	Overdue     bool       `json:"overdue"`
+}
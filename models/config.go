@@ -3,14 +3,20 @@ package models
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 	"sync"
 
 	"gopkg.in/yaml.v3"
 )
 
 var (
-	appConfig Config
-	configLock = &sync.RWMutex{}
+	appConfig      Config
+	appConfigFiles []string
+	configLock     = &sync.RWMutex{}
 )
 
 // GetConfig returns a thread-safe copy of the current application configuration.
@@ -20,48 +26,545 @@ func GetConfig() Config {
 	return appConfig
 }
 
+// GetConfigFiles returns the main configuration file followed by every file
+// that was pulled in through "include" during the last successful load.
+func GetConfigFiles() []string {
+	configLock.RLock()
+	defer configLock.RUnlock()
+	files := make([]string, len(appConfigFiles))
+	copy(files, appConfigFiles)
+	return files
+}
+
 // LoadConfig reads the configuration from the specified file and updates the global config.
 func LoadConfig(configFile string) error {
+	_, _, err := ReloadConfig(configFile)
+	return err
+}
+
+// ReloadConfig loads the configuration like LoadConfig and returns the
+// configuration it replaced together with the new one.
+func ReloadConfig(configFile string) (previous, current Config, err error) {
+	newConfig, files, err := ParseConfig(configFile)
+	if err != nil {
+		return Config{}, Config{}, err
+	}
+
+	configLock.Lock()
+	previous = appConfig
+	appConfig = newConfig
+	appConfigFiles = files
+	configLock.Unlock()
+
+	return previous, newConfig, nil
+}
+
+// ParseConfig reads the main configuration file together with its included
+// files and returns the merged result without touching the global config.
+// The returned file list starts with configFile and is followed by the
+// included files in the order they were loaded.
+func ParseConfig(configFile string) (Config, []string, error) {
 	data, err := ioutil.ReadFile(configFile)
 	if err != nil {
-		return fmt.Errorf("failed to read config file: %v", err)
+		return Config{}, nil, fmt.Errorf("failed to read config file: %v", err)
 	}
 
 	var newConfig Config
 	err = yaml.Unmarshal(data, &newConfig)
 	if err != nil {
-		return fmt.Errorf("failed to parse config file: %v", err)
+		return Config{}, nil, fmt.Errorf("failed to parse config file: %v", err)
+	}
+
+	files := []string{configFile}
+	sources := make([]string, len(newConfig.DBs))
+	for i := range sources {
+		sources[i] = configFile
+	}
+
+	// A plain file name that matches nothing is most likely a typo, while a
+	// glob may legitimately match no files yet.
+	for _, pattern := range IncludePatterns(configFile, newConfig.Include) {
+		if strings.ContainsAny(pattern, "*?[") {
+			continue
+		}
+		if _, err := os.Stat(pattern); err != nil {
+			return Config{}, nil, fmt.Errorf("failed to read included config file '%s': %v", pattern, err)
+		}
+	}
+
+	// Pull in databases from included files. Glob patterns are resolved
+	// relative to the directory of the main config file.
+	for _, includeFile := range ResolveIncludes(configFile, newConfig.Include) {
+		includeData, err := ioutil.ReadFile(includeFile)
+		if err != nil {
+			return Config{}, nil, fmt.Errorf("failed to read included config file '%s': %v", includeFile, err)
+		}
+		var fragment IncludedConfig
+		if err := yaml.Unmarshal(includeData, &fragment); err != nil {
+			return Config{}, nil, fmt.Errorf("failed to parse included config file '%s': %v", includeFile, err)
+		}
+		newConfig.DBs = append(newConfig.DBs, fragment.DBs...)
+		for range fragment.DBs {
+			sources = append(sources, includeFile)
+		}
+		files = append(files, includeFile)
+	}
+
+	// Reject duplicate database names, reporting both files involved.
+	seen := make(map[string]string, len(newConfig.DBs))
+	for i, db := range newConfig.DBs {
+		if db.Name == "" {
+			return Config{}, nil, fmt.Errorf("database entry #%d in '%s' has no name", i+1, sources[i])
+		}
+		if firstSource, ok := seen[db.Name]; ok {
+			return Config{}, nil, fmt.Errorf("duplicate database name '%s' in '%s' (first defined in '%s')", db.Name, sources[i], firstSource)
+		}
+		seen[db.Name] = sources[i]
+	}
+
+	for i := range newConfig.DBs {
+		newConfig.DBs[i].applyDefaults(newConfig.Defaults)
+		if mode := newConfig.DBs[i].CheckMode; mode != CheckModeICMP && mode != CheckModeTCPOnly {
+			return Config{}, nil, fmt.Errorf("database '%s' has invalid check_mode '%s' (expected '%s' or '%s')", newConfig.DBs[i].Name, mode, CheckModeICMP, CheckModeTCPOnly)
+		}
+		if table := newConfig.DBs[i].HeartbeatTable; table != "" && !tableNameRegex.MatchString(table) {
+			return Config{}, nil, fmt.Errorf("database '%s' has invalid heartbeat_table '%s' (expected [SCHEMA.]TABLE)", newConfig.DBs[i].Name, table)
+		}
 	}
 
 	// Set default values
 	if newConfig.Server.RefreshInterval <= 0 {
 		newConfig.Server.RefreshInterval = 30 // Default to 30 seconds refresh
 	}
+	if newConfig.Server.TLS.MinVersion == "" {
+		newConfig.Server.TLS.MinVersion = "1.2"
+	}
+	if err := newConfig.Server.TLS.validate(); err != nil {
+		return Config{}, nil, err
+	}
 	if newConfig.Frontend.DefaultIntervalMs <= 0 {
 		newConfig.Frontend.DefaultIntervalMs = 600000 // Default to 10 minutes
 	}
+	if newConfig.Health.LagWarningSeconds <= 0 {
+		newConfig.Health.LagWarningSeconds = 60
+	}
+	if newConfig.Health.LagCriticalSeconds <= 0 {
+		newConfig.Health.LagCriticalSeconds = 300
+	}
+	if newConfig.Health.SpaceWarningPercent <= 0 {
+		newConfig.Health.SpaceWarningPercent = 80
+	}
+	if newConfig.Health.SpaceCriticalPercent <= 0 {
+		newConfig.Health.SpaceCriticalPercent = 90
+	}
+	if newConfig.Health.FullWarningHours <= 0 {
+		newConfig.Health.FullWarningHours = 24
+	}
+	if newConfig.Health.FullCriticalHours <= 0 {
+		newConfig.Health.FullCriticalHours = 4
+	}
+	if newConfig.Backup.FullSLAHours == 0 {
+		newConfig.Backup.FullSLAHours = 192 // Weekly full backup plus a day of slack
+	}
+	if newConfig.Backup.IncrementalSLAHours == 0 {
+		newConfig.Backup.IncrementalSLAHours = 36
+	}
+	if newConfig.Backup.ArchivelogSLAHours == 0 {
+		newConfig.Backup.ArchivelogSLAHours = 4
+	}
+	if newConfig.Backup.LookbackDays <= 0 {
+		newConfig.Backup.LookbackDays = 14
+	}
+	if newConfig.Backup.CheckIntervalMinutes <= 0 {
+		newConfig.Backup.CheckIntervalMinutes = 15
+	}
+	if newConfig.Drift.CheckIntervalMinutes <= 0 {
+		newConfig.Drift.CheckIntervalMinutes = 60
+	}
+	if newConfig.History.RetentionDays <= 0 {
+		newConfig.History.RetentionDays = 90
+	}
+	if newConfig.Auth.SessionHours <= 0 {
+		newConfig.Auth.SessionHours = 12
+	}
+	if len(newConfig.Auth.OIDC.Scopes) == 0 {
+		newConfig.Auth.OIDC.Scopes = []string{"openid", "profile", "email"}
+	}
+	if newConfig.Auth.OIDC.UsernameClaim == "" {
+		newConfig.Auth.OIDC.UsernameClaim = "preferred_username"
+	}
+	if newConfig.Auth.OIDC.DisplayName == "" {
+		newConfig.Auth.OIDC.DisplayName = "SSO"
+	}
+	if newConfig.Auth.OIDC.GroupsClaim == "" {
+		newConfig.Auth.OIDC.GroupsClaim = "groups"
+	}
+	if newConfig.Auth.DefaultRoles == nil {
+		newConfig.Auth.DefaultRoles = []string{"viewer"}
+	}
+	newConfig.Auth.normalizeRoles()
+	if err := newConfig.Auth.validate(); err != nil {
+		return Config{}, nil, err
+	}
+	if err := newConfig.validateMaintenance(); err != nil {
+		return Config{}, nil, err
+	}
+	if newConfig.Audit.MaxSizeMB <= 0 {
+		newConfig.Audit.MaxSizeMB = 10
+	}
+	if newConfig.Audit.MaxBackups <= 0 {
+		newConfig.Audit.MaxBackups = 10
+	}
+	if newConfig.Notifier.Email.SMTPHost != "" {
+		if newConfig.Notifier.Email.SMTPPort == 0 {
+			newConfig.Notifier.Email.SMTPPort = 587
+		}
+		switch newConfig.Notifier.Email.TLS {
+		case "":
+			newConfig.Notifier.Email.TLS = EmailTLSStartTLS
+		case EmailTLSStartTLS, EmailTLSImplicit, EmailTLSNone:
+		default:
+			return Config{}, nil, fmt.Errorf("invalid notifier.email.tls '%s' (expected '%s', '%s' or '%s')", newConfig.Notifier.Email.TLS, EmailTLSStartTLS, EmailTLSImplicit, EmailTLSNone)
+		}
+		if newConfig.Notifier.Email.From == "" {
+			return Config{}, nil, fmt.Errorf("notifier.email.from is required when notifier.email.smtp_host is set")
+		}
+	}
+	if len(newConfig.Notifier.OperatorEvents.Email) > 0 && newConfig.Notifier.Email.SMTPHost == "" {
+		return Config{}, nil, fmt.Errorf("notifier.operator_events has email recipients but notifier.email.smtp_host is not set")
+	}
+	if err := newConfig.validateReports(); err != nil {
+		return Config{}, nil, err
+	}
+	if newConfig.Diagnostics.ExpiryWarningDays <= 0 {
+		newConfig.Diagnostics.ExpiryWarningDays = 14
+	}
+	if newConfig.Layout.Kiosk.PageSize <= 0 {
+		newConfig.Layout.Kiosk.PageSize = 8
+	}
+	if newConfig.Layout.Kiosk.PageBy == "" {
+		newConfig.Layout.Kiosk.PageBy = "count"
+	}
+	if newConfig.Layout.Kiosk.RotateSeconds <= 0 {
+		newConfig.Layout.Kiosk.RotateSeconds = 30
+	}
+	if newConfig.Layout.Kiosk.ProblemPages == "" {
+		newConfig.Layout.Kiosk.ProblemPages = "first"
+	}
 
-	configLock.Lock()
-	appConfig = newConfig
-	configLock.Unlock()
+	return newConfig, files, nil
+}
 
-	return nil
+// ResolveIncludes expands the include glob patterns into a sorted,
+// de-duplicated list of files. Relative patterns are resolved against the
+// directory of the main config file. Invalid patterns are skipped.
+func ResolveIncludes(configFile string, patterns []string) []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, pattern := range IncludePatterns(configFile, patterns) {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			continue
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			if !seen[match] {
+				seen[match] = true
+				files = append(files, match)
+			}
+		}
+	}
+	return files
 }
 
+// IncludePatterns returns the include patterns made absolute relative to the
+// directory of the main config file.
+func IncludePatterns(configFile string, patterns []string) []string {
+	baseDir := filepath.Dir(configFile)
+	resolved := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		if pattern == "" {
+			continue
+		}
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(baseDir, pattern)
+		}
+		resolved = append(resolved, pattern)
+	}
+	return resolved
+}
+
+// applyDefaults fills in any field left empty on the database entry from the
+// global defaults section, then falls back to built-in values for timeouts.
+func (d *DatabaseConfig) applyDefaults(defaults DatabaseDefaults) {
+	if d.Port == 0 {
+		d.Port = defaults.Port
+	}
+	if d.ServiceName == "" {
+		d.ServiceName = defaults.ServiceName
+	}
+	if d.Username == "" {
+		d.Username = defaults.Username
+	}
+	if d.Password == "" {
+		d.Password = defaults.Password
+	}
+	if d.CheckTimeout <= 0 {
+		d.CheckTimeout = defaults.CheckTimeout
+	}
+	if d.ConnTimeout <= 0 {
+		d.ConnTimeout = defaults.ConnTimeout
+	}
+	if d.CheckMode == "" {
+		d.CheckMode = defaults.CheckMode
+	}
+	if d.PingCount <= 0 {
+		d.PingCount = defaults.PingCount
+	}
+	if d.TNSProbe == nil {
+		d.TNSProbe = defaults.TNSProbe
+	}
+	if d.HeartbeatTable == "" {
+		d.HeartbeatTable = defaults.HeartbeatTable
+	}
+	if d.RPOSeconds <= 0 {
+		d.RPOSeconds = defaults.RPOSeconds
+	}
+	if d.RTOMinutes <= 0 {
+		d.RTOMinutes = defaults.RTOMinutes
+	}
+	if d.SLATargetPercent <= 0 {
+		d.SLATargetPercent = defaults.SLATargetPercent
+	}
+
+	if d.Port == 0 {
+		d.Port = 1521
+	}
+	if d.CheckTimeout <= 0 {
+		d.CheckTimeout = 3 // Default ping/port check timeout in seconds
+	}
+	if d.ConnTimeout <= 0 {
+		d.ConnTimeout = 5 // Default DB connection timeout in seconds
+	}
+	if d.CheckMode == "" {
+		d.CheckMode = CheckModeICMP
+	}
+	if d.PingCount <= 0 {
+		d.PingCount = 3
+	}
+	if d.RPOSeconds <= 0 {
+		d.RPOSeconds = 300
+	}
+	if d.RTOMinutes <= 0 {
+		d.RTOMinutes = 60
+	}
+	if d.SLATargetPercent <= 0 {
+		d.SLATargetPercent = 99.9
+	}
+}
+
+// TNSProbeEnabled reports whether the listener should be probed before logging in.
+func (d DatabaseConfig) TNSProbeEnabled() bool {
+	return d.TNSProbe == nil || *d.TNSProbe
+}
+
+// tableNameRegex matches an unquoted Oracle table name with an optional schema.
+// The heartbeat table name is interpolated into SQL, so nothing else is accepted.
+var tableNameRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_$#]*(\.[A-Za-z][A-Za-z0-9_$#]*)?$`)
+
+// Check modes for DatabaseConfig.CheckMode.
+const (
+	CheckModeICMP    = "icmp"     // ICMP ping, then TCP port check
+	CheckModeTCPOnly = "tcp-only" // Reachability is judged by the TCP port check alone
+)
 
 // Config defines the overall application configuration structure.
 type Config struct {
-	Server   ServerConfig     `yaml:"server"`
-	Logging  LoggingConfig    `yaml:"logging"`
-	DBs      []DatabaseConfig `yaml:"databases"`
-	Titles   TitlesConfig     `yaml:"titles"`
-	Layout   LayoutConfig     `yaml:"layout"`
-	Frontend FrontendSettings `yaml:"frontend"`
+	Server      ServerConfig        `yaml:"server"`
+	Logging     LoggingConfig       `yaml:"logging"`
+	Include     []string            `yaml:"include"`
+	Defaults    DatabaseDefaults    `yaml:"defaults"`
+	DBs         []DatabaseConfig    `yaml:"databases"`
+	Titles      TitlesConfig        `yaml:"titles"`
+	Layout      LayoutConfig        `yaml:"layout"`
+	Frontend    FrontendSettings    `yaml:"frontend"`
+	Health      HealthConfig        `yaml:"health"`
+	Diagnostics DiagnosticsConfig   `yaml:"diagnostics"`
+	History     HistoryConfig       `yaml:"history"`
+	Backup      BackupConfig        `yaml:"backup"`
+	Drift       DriftConfig         `yaml:"drift"`
+	Auth        AuthConfig          `yaml:"auth"`
+	Audit       AuditConfig         `yaml:"audit"`
+	Maintenance []MaintenanceWindow `yaml:"maintenance"`
+	Notifier    NotifierConfig      `yaml:"notifier"`
+	Reports     ReportsConfig       `yaml:"reports"`
+}
+
+// How the notifier secures the connection to the mail server.
+const (
+	EmailTLSStartTLS = "starttls" // Upgrade a plain connection, usually on port 587
+	EmailTLSImplicit = "tls"      // TLS from the start, usually on port 465
+	EmailTLSNone     = "none"
+)
+
+// NotifierConfig configures how the dashboard sends messages to people.
+type NotifierConfig struct {
+	Email          EmailConfig          `yaml:"email"`
+	OperatorEvents OperatorEventsConfig `yaml:"operator_events"`
+}
+
+// OperatorEventsConfig selects who is told when acknowledgements and notes
+// are created, cleared or removed.
+type OperatorEventsConfig struct {
+	Email []string `yaml:"email"` // Recipients; requires notifier.email
+}
+
+// EmailConfig holds the mail server used to send email; disabled without a host.
+type EmailConfig struct {
+	SMTPHost string `yaml:"smtp_host"`
+	SMTPPort int    `yaml:"smtp_port"`
+	TLS      string `yaml:"tls"`      // "starttls", "tls" or "none"
+	Username string `yaml:"username"` // Empty to send without authentication
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
+
+// AuditConfig configures the audit log of logins, operator actions and configuration reloads.
+type AuditConfig struct {
+	File       string `yaml:"file"`        // JSON-lines file; empty keeps the latest records in memory only
+	MaxSizeMB  int    `yaml:"max_size_mb"` // The file is rotated when it would grow beyond this size
+	MaxBackups int    `yaml:"max_backups"` // Rotated files to keep
+}
+
+// AuthConfig enables authentication for the dashboard and the API. Browsers
+// sign in with a local user or through OIDC and get a session cookie; machine
+// clients send an API token; wall screens carry a read-only kiosk token in the URL.
+type AuthConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	SessionSecret string `yaml:"session_secret"` // Key signing session cookies; a random key per start when empty
+	SessionHours  int    `yaml:"session_hours"`  // Lifetime of a login
+	// SecureCookies marks cookies Secure even on plain HTTP requests, e.g. behind a
+	// TLS-terminating proxy that does not send X-Forwarded-Proto.
+	SecureCookies bool `yaml:"secure_cookies"`
+	// DefaultRoles apply to users, tokens and OIDC logins without roles of their own.
+	DefaultRoles []string            `yaml:"default_roles"`
+	Roles        map[string][]string `yaml:"roles"` // Custom roles: name -> permissions
+	Users        []LocalUser         `yaml:"users"`
+	APITokens    []TokenConfig       `yaml:"api_tokens"`
+	KioskTokens  []TokenConfig       `yaml:"kiosk_tokens"` // Always read-only; roles are ignored
+	OIDC         OIDCConfig          `yaml:"oidc"`
+}
+
+// LocalUser is a user with a bcrypt password hash, see the "hash-password" command.
+type LocalUser struct {
+	Username     string `yaml:"username"`
+	PasswordHash string `yaml:"password_hash"`
+	Grant        `yaml:",inline"`
+}
+
+// TokenConfig is a static bearer token; the name identifies the client in logs.
+type TokenConfig struct {
+	Name  string `yaml:"name"`
+	Token string `yaml:"token"`
+	Grant `yaml:",inline"`
+}
+
+// OIDCConfig configures login through an OpenID Connect provider using the
+// authorization code flow. It is enabled when Issuer is set.
+type OIDCConfig struct {
+	Issuer        string   `yaml:"issuer"`
+	ClientID      string   `yaml:"client_id"`
+	ClientSecret  string   `yaml:"client_secret"`
+	RedirectURL   string   `yaml:"redirect_url"` // Must end in /auth/oidc/callback
+	Scopes        []string `yaml:"scopes"`
+	UsernameClaim string   `yaml:"username_claim"` // ID token claim used as the user name
+	DisplayName   string   `yaml:"display_name"`   // Label of the login button
+	// GroupsClaim is the ID token claim listing the user's groups, matched against GroupRoles.
+	GroupsClaim string       `yaml:"groups_claim"`
+	GroupRoles  []GroupGrant `yaml:"group_roles"`
+}
+
+// minTokenLength guards against guessable API and kiosk tokens.
+const minTokenLength = 16
+
+// validate checks that an enabled configuration can authenticate anyone at all.
+func (a AuthConfig) validate() error {
+	if !a.Enabled {
+		return nil
+	}
+	if len(a.Users) == 0 && len(a.APITokens) == 0 && len(a.KioskTokens) == 0 && a.OIDC.Issuer == "" {
+		return fmt.Errorf("auth is enabled but no users, tokens or OIDC issuer are configured")
+	}
+	for _, user := range a.Users {
+		if user.Username == "" || !strings.HasPrefix(user.PasswordHash, "$2") {
+			return fmt.Errorf("auth user '%s' needs a username and a bcrypt password_hash", user.Username)
+		}
+	}
+	for _, token := range append(append([]TokenConfig{}, a.APITokens...), a.KioskTokens...) {
+		if token.Name == "" || len(token.Token) < minTokenLength {
+			return fmt.Errorf("auth token '%s' needs a name and at least %d characters", token.Name, minTokenLength)
+		}
+	}
+	if a.OIDC.Issuer != "" && (a.OIDC.ClientID == "" || a.OIDC.RedirectURL == "") {
+		return fmt.Errorf("auth.oidc needs client_id and redirect_url")
+	}
+	return a.validateRoles()
+}
+
+// DriftConfig holds settings for the primary/standby configuration comparison.
+type DriftConfig struct {
+	// Ignore lists additional setting keys (glob patterns such as "parameter.db_recovery_file_dest*")
+	// whose differences are expected; role-specific parameters are always ignored.
+	Ignore               []string `yaml:"ignore"`
+	CheckIntervalMinutes int      `yaml:"check_interval_minutes"` // Settings are re-read at most this often
+}
+
+// BackupConfig holds the RMAN backup SLAs. A backup type whose SLA is negative is not judged.
+type BackupConfig struct {
+	FullSLAHours         float64 `yaml:"full_sla_hours"`
+	IncrementalSLAHours  float64 `yaml:"incremental_sla_hours"`
+	ArchivelogSLAHours   float64 `yaml:"archivelog_sla_hours"`
+	LookbackDays         int     `yaml:"lookback_days"`          // How far back backup jobs are read
+	CheckIntervalMinutes int     `yaml:"check_interval_minutes"` // Backup jobs are re-read at most this often
+}
+
+// HistoryConfig holds settings for the recorded check history.
+type HistoryConfig struct {
+	Dir           string `yaml:"dir"`            // Directory for the JSON-lines files; empty keeps history in memory only
+	RetentionDays int    `yaml:"retention_days"` // Files older than this are deleted
+}
+
+// DiagnosticsConfig holds settings for the monitoring account self-check.
+type DiagnosticsConfig struct {
+	ExpiryWarningDays int `yaml:"expiry_warning_days"` // Warn this many days before the password expires
+}
+
+// HealthConfig holds the thresholds used to compute health verdicts.
+type HealthConfig struct {
+	LagWarningSeconds  int `yaml:"lag_warning_seconds"`
+	LagCriticalSeconds int `yaml:"lag_critical_seconds"`
+
+	// Recovery area and archive destination usage, and the estimated time until it is full.
+	SpaceWarningPercent  float64 `yaml:"space_warning_percent"`
+	SpaceCriticalPercent float64 `yaml:"space_critical_percent"`
+	FullWarningHours     float64 `yaml:"full_warning_hours"`
+	FullCriticalHours    float64 `yaml:"full_critical_hours"`
 }
 
 // LayoutConfig defines layout settings like the number of columns.
 type LayoutConfig struct {
-	Columns int `yaml:"columns" json:"columns"`
+	Columns int         `yaml:"columns" json:"columns"`
+	Kiosk   KioskConfig `yaml:"kiosk" json:"kiosk"`
+}
+
+// KioskConfig defines the paginated, rotating wall display mode.
+// Every setting can be overridden per screen through URL parameters.
+type KioskConfig struct {
+	Enabled       bool   `yaml:"enabled" json:"enabled"`
+	PageSize      int    `yaml:"page_size" json:"page_size"`           // Databases per page
+	PageBy        string `yaml:"page_by" json:"page_by"`               // "count" or "group"
+	RotateSeconds int    `yaml:"rotate_seconds" json:"rotate_seconds"` // Seconds each page is shown
+	ProblemPages  string `yaml:"problem_pages" json:"problem_pages"`   // "first", "pin" or "none"
 }
 
 // RefreshSlot defines a time period and its corresponding refresh interval.
@@ -91,6 +594,43 @@ type ServerConfig struct {
 	StaticDir       string `yaml:"static_dir"`
 	RefreshInterval int    `yaml:"refresh_interval"`
 	PublicBasePath  string `yaml:"public_base_path"` // Public base path for reverse proxy setups
+	// UnixSocket makes the server listen on this socket path instead of the TCP
+	// port, e.g. for a reverse proxy sidecar. TLS settings do not apply to it.
+	UnixSocket string    `yaml:"unix_socket"`
+	TLS        TLSConfig `yaml:"tls"`
+}
+
+// TLSConfig enables HTTPS on the server port. The certificate, key and client
+// CA bundle are reloaded when their files change, so renewals need no restart.
+type TLSConfig struct {
+	CertFile     string `yaml:"cert_file"`
+	KeyFile      string `yaml:"key_file"`
+	MinVersion   string `yaml:"min_version"`    // "1.2" or "1.3"
+	ClientCAFile string `yaml:"client_ca_file"` // When set, clients must present a certificate signed by one of these CAs
+	// RedirectHTTPPort, when set, serves plain HTTP on this port and redirects everything to HTTPS.
+	RedirectHTTPPort string `yaml:"redirect_http_port"`
+}
+
+// Enabled reports whether the server should serve HTTPS.
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != ""
+}
+
+// validate checks that the TLS settings are complete and consistent.
+func (t TLSConfig) validate() error {
+	if t.CertFile == "" && t.KeyFile == "" {
+		if t.ClientCAFile != "" || t.RedirectHTTPPort != "" {
+			return fmt.Errorf("server.tls: client_ca_file and redirect_http_port need cert_file and key_file")
+		}
+		return nil
+	}
+	if t.CertFile == "" || t.KeyFile == "" {
+		return fmt.Errorf("server.tls: both cert_file and key_file are required")
+	}
+	if t.MinVersion != "1.2" && t.MinVersion != "1.3" {
+		return fmt.Errorf("server.tls: invalid min_version '%s' (expected '1.2' or '1.3')", t.MinVersion)
+	}
+	return nil
 }
 
 // LoggingConfig holds logging settings.
@@ -104,12 +644,143 @@ type LoggingConfig struct {
 
 // DatabaseConfig holds the configuration for a single database to monitor.
 type DatabaseConfig struct {
-	Name        string `yaml:"name"`
-	LBIP        string `yaml:"lb_ip"`
-	ProdIP      string `yaml:"prod_ip"`
-	DRIP        string `yaml:"dr_ip"`
-	Port        int    `yaml:"port"`
-	ServiceName string `yaml:"service_name"`
-	Username    string `yaml:"username"`
-	Password    string `yaml:"password"`
-} 
\ No newline at end of file
+	Name         string `yaml:"name"`
+	LBIP         string `yaml:"lb_ip"`
+	ProdIP       string `yaml:"prod_ip"`
+	DRIP         string `yaml:"dr_ip"`
+	Port         int    `yaml:"port"`
+	ServiceName  string `yaml:"service_name"`
+	Username     string `yaml:"username"`
+	Password     string `yaml:"password"`
+	CheckTimeout int    `yaml:"check_timeout"` // Ping/port check timeout in seconds
+	ConnTimeout  int    `yaml:"conn_timeout"`  // DB connection timeout in seconds
+	CheckMode    string `yaml:"check_mode"`    // "icmp" (default) or "tcp-only" for networks that block ICMP
+	PingCount    int    `yaml:"ping_count"`    // ICMP echo requests per check
+	TNSProbe     *bool  `yaml:"tns_probe"`     // Probe the TNS listener before logging in (default true)
+
+	// HeartbeatTable enables synthetic lag measurement: the dashboard writes a
+	// timestamp into this table on the primary and reads it back on the standby.
+	HeartbeatTable string `yaml:"heartbeat_table"`
+
+	// Disaster recovery targets used by the SLA report: the lag the standby may
+	// have, the outage the service may have, and the share of time the RPO must hold.
+	RPOSeconds       int     `yaml:"rpo_seconds"`
+	RTOMinutes       int     `yaml:"rto_minutes"`
+	SLATargetPercent float64 `yaml:"sla_target_percent"`
+
+	Group string   `yaml:"group"` // Owning team or application, e.g. "ERP"
+	Tier  string   `yaml:"tier"`  // Business tier, e.g. "tier-1"
+	Tags  []string `yaml:"tags"`  // Arbitrary labels used for filtering
+}
+
+// DatabaseDefaults holds values inherited by every database entry that does not set them itself.
+type DatabaseDefaults struct {
+	Port         int    `yaml:"port"`
+	ServiceName  string `yaml:"service_name"`
+	Username     string `yaml:"username"`
+	Password     string `yaml:"password"`
+	CheckTimeout int    `yaml:"check_timeout"`
+	ConnTimeout  int    `yaml:"conn_timeout"`
+	CheckMode    string `yaml:"check_mode"`
+	PingCount    int    `yaml:"ping_count"`
+	TNSProbe     *bool  `yaml:"tns_probe"`
+
+	HeartbeatTable string `yaml:"heartbeat_table"`
+
+	RPOSeconds       int     `yaml:"rpo_seconds"`
+	RTOMinutes       int     `yaml:"rto_minutes"`
+	SLATargetPercent float64 `yaml:"sla_target_percent"`
+}
+
+// DatabaseFilter selects databases by name, group and tags. Empty fields match
+// everything; when several tags are given, a database must carry all of them.
+type DatabaseFilter struct {
+	Names []string
+	Group string
+	Tags  []string
+	// Scope limits the databases a user may see: when set, a database must
+	// carry at least one of these tags.
+	Scope []string
+}
+
+// IsEmpty reports whether the filter matches every database.
+func (f DatabaseFilter) IsEmpty() bool {
+	return len(f.Names) == 0 && f.Group == "" && len(f.Tags) == 0 && len(f.Scope) == 0
+}
+
+// Matches reports whether a database with the given name, group and tags passes the filter.
+// Comparisons are case-insensitive.
+func (f DatabaseFilter) Matches(name, group string, tags []string) bool {
+	if len(f.Names) > 0 && !containsFold(f.Names, name) {
+		return false
+	}
+	if f.Group != "" && !strings.EqualFold(f.Group, group) {
+		return false
+	}
+	for _, want := range f.Tags {
+		if !containsFold(tags, want) {
+			return false
+		}
+	}
+	return len(f.Scope) == 0 || containsAnyFold(tags, f.Scope)
+}
+
+// MatchesDatabase reports whether the configured database passes the filter.
+func (f DatabaseFilter) MatchesDatabase(db DatabaseConfig) bool {
+	return f.Matches(db.Name, db.Group, db.Tags)
+}
+
+// containsFold reports whether list contains value, ignoring case.
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAnyFold reports whether list contains any of values, ignoring case.
+func containsAnyFold(list, values []string) bool {
+	for _, value := range values {
+		if containsFold(list, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Groups returns the sorted, de-duplicated list of database groups in the configuration.
+func (c Config) Groups() []string {
+	return c.GroupsOf(DatabaseFilter{})
+}
+
+// GroupsOf returns the sorted, de-duplicated groups of the databases passing the filter.
+func (c Config) GroupsOf(filter DatabaseFilter) []string {
+	seen := make(map[string]bool)
+	groups := []string{}
+	for _, db := range c.DBs {
+		if db.Group != "" && !seen[db.Group] && filter.MatchesDatabase(db) {
+			seen[db.Group] = true
+			groups = append(groups, db.Group)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// Database returns the configuration of the named database.
+func (c Config) Database(name string) (DatabaseConfig, bool) {
+	for _, db := range c.DBs {
+		if db.Name == name {
+			return db, true
+		}
+	}
+	return DatabaseConfig{}, false
+}
+
+// IncludedConfig is the structure of a file pulled in through "include".
+// Only the databases section is read from included files.
+type IncludedConfig struct {
+	DBs []DatabaseConfig `yaml:"databases"`
+}
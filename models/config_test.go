@@ -0,0 +1,207 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeConfigFiles writes files, keyed by their path relative to a temporary
+// directory, and returns the path of the main config.yaml in it.
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	return filepath.Join(dir, "config.yaml")
+}
+
+func TestParseConfigIncludes(t *testing.T) {
+	const defaults = `
+defaults:
+  port: 1522
+  service_name: "ORCLPDB1"
+  username: "monitor_user"
+  password: "secret"
+  check_mode: "tcp-only"
+  heartbeat_table: "monitor_user.dr_heartbeat"
+`
+	tests := []struct {
+		name      string
+		files     map[string]string
+		wantNames []string // Databases in load order
+		wantFiles []string // Loaded files relative to the config directory
+		wantErr   string
+	}{
+		{
+			name: "no include",
+			files: map[string]string{"config.yaml": defaults + `
+databases:
+  - name: ERP_DB
+`},
+			wantNames: []string{"ERP_DB"},
+			wantFiles: []string{"config.yaml"},
+		},
+		{
+			name: "plain include",
+			files: map[string]string{
+				"config.yaml": defaults + `
+include: ["teams/crm.yaml"]
+databases:
+  - name: ERP_DB
+`,
+				"teams/crm.yaml": `
+databases:
+  - name: CRM_DB
+`},
+			wantNames: []string{"ERP_DB", "CRM_DB"},
+			wantFiles: []string{"config.yaml", "teams/crm.yaml"},
+		},
+		{
+			name: "glob include in name order",
+			files: map[string]string{
+				"config.yaml": defaults + `
+include: ["conf.d/*.yaml", "conf.d/a.yaml"]
+`,
+				"conf.d/b.yaml": "databases:\n  - name: B_DB\n",
+				"conf.d/a.yaml": "databases:\n  - name: A1_DB\n  - name: A2_DB\n",
+				"conf.d/c.txt":  "databases:\n  - name: C_DB\n",
+			},
+			wantNames: []string{"A1_DB", "A2_DB", "B_DB"},
+			wantFiles: []string{"config.yaml", "conf.d/a.yaml", "conf.d/b.yaml"},
+		},
+		{
+			name:      "glob matching nothing",
+			files:     map[string]string{"config.yaml": defaults + "include: [\"conf.d/*.yaml\"]\n"},
+			wantFiles: []string{"config.yaml"},
+		},
+		{
+			name:    "missing include",
+			files:   map[string]string{"config.yaml": defaults + "include: [\"teams/crm.yaml\"]\n"},
+			wantErr: "failed to read included config file",
+		},
+		{
+			name: "duplicate name across files",
+			files: map[string]string{
+				"config.yaml": defaults + `
+include: ["conf.d/*.yaml"]
+databases:
+  - name: ERP_DB
+`,
+				"conf.d/erp.yaml": "databases:\n  - name: ERP_DB\n",
+			},
+			wantErr: filepath.Join("conf.d", "erp.yaml") + "' (first defined in",
+		},
+		{
+			name: "unnamed database in include",
+			files: map[string]string{
+				"config.yaml":     defaults + "include: [\"conf.d/*.yaml\"]\n",
+				"conf.d/bad.yaml": "databases:\n  - prod_ip: 10.0.0.1\n",
+			},
+			wantErr: "database entry #1 in",
+		},
+		{
+			name: "invalid include",
+			files: map[string]string{
+				"config.yaml":     defaults + "include: [\"conf.d/*.yaml\"]\n",
+				"conf.d/bad.yaml": "databases: [\n",
+			},
+			wantErr: "failed to parse included config file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile := writeConfigFiles(t, tt.files)
+			cfg, files, err := ParseConfig(configFile)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+
+			var names []string
+			for _, db := range cfg.DBs {
+				names = append(names, db.Name)
+				// Included entries get the defaults of the main file too.
+				if db.Port != 1522 || db.ServiceName != "ORCLPDB1" || db.CheckMode != CheckModeTCPOnly || db.HeartbeatTable != "monitor_user.dr_heartbeat" {
+					t.Errorf("database %s did not get the defaults: %+v", db.Name, db)
+				}
+			}
+			if strings.Join(names, ",") != strings.Join(tt.wantNames, ",") {
+				t.Errorf("got databases %v, want %v", names, tt.wantNames)
+			}
+			dir := filepath.Dir(configFile)
+			var relative []string
+			for _, file := range files {
+				rel, _ := filepath.Rel(dir, file)
+				relative = append(relative, rel)
+			}
+			if strings.Join(relative, ",") != strings.Join(tt.wantFiles, ",") {
+				t.Errorf("got files %v, want %v", relative, tt.wantFiles)
+			}
+		})
+	}
+}
+
+func TestApplyDefaults(t *testing.T) {
+	disabled := false
+	tests := []struct {
+		name     string
+		db       DatabaseConfig
+		defaults DatabaseDefaults
+		want     DatabaseConfig
+	}{
+		{
+			name: "built-in values",
+			db:   DatabaseConfig{Name: "ERP_DB"},
+			want: DatabaseConfig{Name: "ERP_DB", Port: 1521, CheckTimeout: 3, ConnTimeout: 5, CheckMode: CheckModeICMP, PingCount: 3,
+				RPOSeconds: 300, RTOMinutes: 60, SLATargetPercent: 99.9},
+		},
+		{
+			name: "defaults section",
+			db:   DatabaseConfig{Name: "ERP_DB"},
+			defaults: DatabaseDefaults{Port: 1522, ServiceName: "ORCLPDB1", Username: "monitor_user", Password: "secret", CheckTimeout: 7,
+				ConnTimeout: 9, CheckMode: CheckModeTCPOnly, PingCount: 1, TNSProbe: &disabled, HeartbeatTable: "hb",
+				RPOSeconds: 60, RTOMinutes: 15, SLATargetPercent: 99.5},
+			want: DatabaseConfig{Name: "ERP_DB", Port: 1522, ServiceName: "ORCLPDB1", Username: "monitor_user", Password: "secret", CheckTimeout: 7,
+				ConnTimeout: 9, CheckMode: CheckModeTCPOnly, PingCount: 1, TNSProbe: &disabled, HeartbeatTable: "hb",
+				RPOSeconds: 60, RTOMinutes: 15, SLATargetPercent: 99.5},
+		},
+		{
+			name: "entry values win",
+			db: DatabaseConfig{Name: "ERP_DB", Port: 1600, ServiceName: "ERP", Username: "erp_monitor", CheckMode: CheckModeICMP,
+				RPOSeconds: 30},
+			defaults: DatabaseDefaults{Port: 1522, ServiceName: "ORCLPDB1", Username: "monitor_user", Password: "secret", CheckMode: CheckModeTCPOnly,
+				RPOSeconds: 60},
+			want: DatabaseConfig{Name: "ERP_DB", Port: 1600, ServiceName: "ERP", Username: "erp_monitor", Password: "secret", CheckTimeout: 3,
+				ConnTimeout: 5, CheckMode: CheckModeICMP, PingCount: 3, RPOSeconds: 30, RTOMinutes: 60, SLATargetPercent: 99.9},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := tt.db
+			db.applyDefaults(tt.defaults)
+			if db.TNSProbe != tt.want.TNSProbe {
+				t.Errorf("got tns_probe %v, want %v", db.TNSProbe, tt.want.TNSProbe)
+			}
+			db.TNSProbe, tt.want.TNSProbe = nil, nil
+			if !reflect.DeepEqual(db, tt.want) {
+				t.Errorf("got %+v, want %+v", db, tt.want)
+			}
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // DatabaseStatus represents the status of a single database system.
 type DatabaseStatus struct {
 	Name                  string `json:"name"`
@@ -22,16 +24,117 @@ type DatabaseStatus struct {
 	DisasterStatus        string `json:"disaster_status"`
 	DisasterRole          string `json:"disaster_role"`
 	DisasterDgDelay       int    `json:"disaster_dgdelay"` // DG Lag in seconds
+
+	// Identity of each member and of the member that answered through the load balancer VIP.
+	ProductionDbUniqueName         string `json:"production_db_unique_name"`
+	ProductionInstanceName         string `json:"production_instance_name"`
+	ProductionResetlogsChange      int64  `json:"production_resetlogs_change"`
+	DisasterDbUniqueName           string `json:"disaster_db_unique_name"`
+	DisasterInstanceName           string `json:"disaster_instance_name"`
+	DisasterResetlogsChange        int64  `json:"disaster_resetlogs_change"`
+	LoadBalancerRoutedTo           string `json:"load_balancer_routed_to"` // "production", "disaster", "unknown", or empty when the probe did not run
+	LoadBalancerRoutedRole         string `json:"load_balancer_routed_role"`
+	LoadBalancerRoutedDbUniqueName string `json:"load_balancer_routed_db_unique_name"`
+	LoadBalancerRoutedInstance     string `json:"load_balancer_routed_instance"`
+	LoadBalancerRoutedHost         string `json:"load_balancer_routed_host"`
+
+	// Outcome of the credential-free TNS listener probe; empty if it did not run.
+	ProductionListener string `json:"production_listener"`
+	DisasterListener   string `json:"disaster_listener"`
+
+	// Classified error of the last failed check step per member; nil when all steps succeeded.
+	LoadBalancerError *MemberError `json:"load_balancer_error"`
+	ProductionError   *MemberError `json:"production_error"`
+	DisasterError     *MemberError `json:"disaster_error"`
+
+	// ICMP measurements; nil when the database uses the tcp-only check mode or the probe could not run.
+	LoadBalancerPing *PingStats `json:"load_balancer_ping"`
+	ProductionPing   *PingStats `json:"production_ping"`
+	DisasterPing     *PingStats `json:"disaster_ping"`
+
+	// End-to-end lag measured through the heartbeat table on the standby member;
+	// nil when no heartbeat table is configured or the member is the primary.
+	ProductionHeartbeat *HeartbeatLag `json:"production_heartbeat"`
+	DisasterHeartbeat   *HeartbeatLag `json:"disaster_heartbeat"`
+
+	// Recovery area and archive destination space per member; nil when it could not be collected.
+	ProductionSpace *SpaceUsage `json:"production_space"`
+	DisasterSpace   *SpaceUsage `json:"disaster_space"`
+
+	// RMAN backups taken on either member; nil when neither member could be queried.
+	Backup *BackupStatus `json:"backup"`
+
+	// Configuration differences between the members; nil unless both members could be compared.
+	Drift *DriftReport `json:"drift"`
+
+	// NOLOGGING operations on the primary and non-logged blocks on the standby; nil if neither could be checked.
+	Nologging *NologgingStatus `json:"nologging"`
+
+	// Pluggable databases of both members; nil unless at least one member is a CDB.
+	PDBs *PDBReport `json:"pdbs"`
+
+	// Maintenance windows active during the check; their members' verdicts are MAINTENANCE.
+	Maintenance []MaintenancePeriod `json:"maintenance"`
+
+	// Operator acknowledgements of firing reasons and notes pinned to the database.
+	Acknowledgements []AlertAck `json:"acknowledgements"`
+	Notes            []Note     `json:"notes"`
+
+	// Normalized health verdicts and the site the load balancer points at,
+	// computed by the handlers package so that API consumers need not re-derive them.
+	ProductionHealth   HealthVerdict `json:"production_health"`
+	DisasterHealth     HealthVerdict `json:"disaster_health"`
+	Health             HealthVerdict `json:"health"`
+	LoadBalancerTarget string        `json:"load_balancer_target"`
+
+	// Metadata passed through from the database configuration.
+	Group string   `json:"group"`
+	Tier  string   `json:"tier"`
+	Tags  []string `json:"tags"`
+}
+
+// PingStats holds the ICMP round-trip measurements of one host.
+type PingStats struct {
+	RTTMs      float64 `json:"rtt_ms"`      // Average round-trip time
+	JitterMs   float64 `json:"jitter_ms"`   // Mean variation between consecutive round trips
+	PacketLoss float64 `json:"packet_loss"` // Percentage of unanswered requests
+}
+
+// HeartbeatLag holds the replication lag measured through the heartbeat table,
+// independent of the values Oracle reports in V$DATAGUARD_STATS.
+type HeartbeatLag struct {
+	LagSeconds       float64      `json:"lag_seconds"`        // Age of the heartbeat visible on the standby, skew-compensated
+	ClockSkewSeconds float64      `json:"clock_skew_seconds"` // Standby clock minus primary clock
+	Heartbeat        *time.Time   `json:"heartbeat,omitempty"`
+	Error            *MemberError `json:"error,omitempty"`
 }
 
 // OracleInstanceStatus holds the detailed status of a single Oracle instance.
 // This struct is used internally by checkOracleInstanceDetailed.
 type OracleInstanceStatus struct {
-	IsAlive       bool
-	PortOpen      bool
-	DbConnected   bool
-	CurrentStatus string
-	Role          string
-	DgDelay       int
-	Connections   int // Only relevant for Primary
-} 
\ No newline at end of file
+	IsAlive  bool
+	Ping     *PingStats
+	PortOpen bool
+	// ListenerStatus is the outcome of the credential-free TNS probe, e.g.
+	// "SERVICE_REGISTERED" or "SERVICE_NOT_REGISTERED"; empty if it did not run.
+	ListenerStatus string
+	Error          *MemberError // Classified error of the failed check step, if any
+	DbConnected    bool
+	CurrentStatus  string
+	Role           string
+	DgDelay        int
+	Connections    int // Only relevant for Primary
+	DbUniqueName   string
+	InstanceName   string
+	HostName       string
+	// ResetlogsChange is RESETLOGS_CHANGE#; after a failover the new primary has the higher value.
+	ResetlogsChange int64
+	Space           *SpaceUsage
+	BackupJobs      []BackupJob
+	BackupError     *MemberError
+	Settings        map[string]ConfigSetting
+	SettingsError   *MemberError
+	Nologging       *MemberNologging
+	PDBs            []PDBState // nil when the member is not a CDB or could not be queried
+	PDBError        *MemberError
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// AccountStatus describes the monitoring account as seen in USER_USERS.
+type AccountStatus struct {
+	Username        string     `json:"username"`
+	AccountStatus   string     `json:"account_status"`
+	ExpiryDate      *time.Time `json:"expiry_date"`       // nil when the password never expires
+	DaysUntilExpiry *int       `json:"days_until_expiry"` // nil when the password never expires
+}
+
+// PrivilegeCheck is the result of verifying access to one dictionary view.
+type PrivilegeCheck struct {
+	Object  string       `json:"object"`
+	Granted bool         `json:"granted"`
+	Error   *MemberError `json:"error,omitempty"`
+}
+
+// MemberDiagnostics holds the self-check results for one member of a database.
+type MemberDiagnostics struct {
+	Member     string           `json:"member"` // "production" or "disaster"
+	Address    string           `json:"address"`
+	Connected  bool             `json:"connected"`
+	Error      *MemberError     `json:"error,omitempty"`
+	Account    *AccountStatus   `json:"account,omitempty"`
+	Privileges []PrivilegeCheck `json:"privileges"`
+	Verdict    HealthVerdict    `json:"verdict"`
+}
+
+// DatabaseDiagnostics holds the self-check results for one database.
+type DatabaseDiagnostics struct {
+	Name    string              `json:"name"`
+	Members []MemberDiagnostics `json:"members"`
+	Verdict HealthVerdict       `json:"verdict"`
+}
+
+// DiagnosticsReport is the result of a monitoring account self-check run.
+type DiagnosticsReport struct {
+	GeneratedAt time.Time             `json:"generated_at"`
+	Databases   []DatabaseDiagnostics `json:"databases"`
+	Verdict     HealthVerdict         `json:"verdict"`
+}
@@ -0,0 +1,24 @@
+package models
+
+// ConfigSetting is one configuration value collected from a member, keyed by
+// names such as "parameter.db_block_size", "database.force_logging" or
+// "redo.standby_logs.thread_1".
+type ConfigSetting struct {
+	Value   string `json:"value"`
+	Default bool   `json:"default"` // Only parameters can be default; they are compared when non-default on either side
+}
+
+// DriftReport lists the settings that differ between the production and disaster recovery members.
+type DriftReport struct {
+	Differences     []DriftItem  `json:"differences"`
+	Compared        int          `json:"compared"` // Number of settings compared
+	ProductionError *MemberError `json:"production_error,omitempty"`
+	DisasterError   *MemberError `json:"disaster_error,omitempty"`
+}
+
+// DriftItem is one setting with different values on the two members.
+type DriftItem struct {
+	Key        string `json:"key"`
+	Production string `json:"production"`
+	Disaster   string `json:"disaster"`
+}
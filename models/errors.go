@@ -0,0 +1,40 @@
+package models
+
+// Error categories used to classify failures while checking a member.
+const (
+	ErrInvalidCredentials     = "INVALID_CREDENTIALS"     // ORA-01017
+	ErrAccountLocked          = "ACCOUNT_LOCKED"          // ORA-28000
+	ErrPasswordExpired        = "PASSWORD_EXPIRED"        // ORA-28001
+	ErrInsufficientPrivileges = "INSUFFICIENT_PRIVILEGES" // ORA-00942, ORA-01031
+	ErrServiceUnknown         = "SERVICE_UNKNOWN"         // ORA-12514, ORA-12505
+	ErrShutdownInProgress     = "SHUTDOWN_IN_PROGRESS"    // ORA-01033, ORA-01089, ORA-01090
+	ErrDatabaseUnavailable    = "DATABASE_UNAVAILABLE"    // ORA-01034, ORA-27101
+	ErrTimeout                = "TIMEOUT"                 // ORA-12170, ORA-12535, network timeouts
+	ErrTLS                    = "TLS_ERROR"               // ORA-288xx/29024/29106, TLS handshake failures
+	ErrConnectionRefused      = "CONNECTION_REFUSED"      // ORA-12541, TCP connection refused
+	ErrOther                  = "OTHER"
+)
+
+// Check steps reported in MemberError.Stage.
+const (
+	StageConnect     = "connect"     // Login to the member or through the load balancer
+	StageInfo        = "info"        // Role, status and identity of the database
+	StageLag         = "lag"         // Data Guard lag on the standby
+	StageConnections = "connections" // Session count on the primary
+	StageSpace       = "space"       // Recovery area and archive destinations
+	StageBackup      = "backup"      // RMAN backup jobs
+	StageDrift       = "drift"       // Configuration settings compared between the members
+	StageNologging   = "nologging"   // NOLOGGING operations and non-logged blocks
+	StagePDBs        = "pdbs"        // Pluggable databases
+	StageHeartbeat   = "heartbeat"   // Heartbeat table lag
+	StagePrivileges  = "privileges"  // Self-check of the monitoring account's grants
+)
+
+// MemberError is a classified error seen while checking a member. The message
+// is sanitized so that it can be shown in the API and the UI.
+type MemberError struct {
+	Category string `json:"category"`       // One of the Err* categories
+	Code     string `json:"code,omitempty"` // Oracle error code, e.g. "ORA-01017"
+	Message  string `json:"message"`
+	Stage    string `json:"stage"` // Check step that failed, one of the Stage* constants
+}
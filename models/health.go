@@ -0,0 +1,68 @@
+package models
+
+// Health levels, ordered from best to worst by HealthSeverity.
+const (
+	HealthOK       = "OK"
+	HealthUnknown  = "UNKNOWN"
+	HealthWarning  = "WARNING"
+	HealthCritical = "CRITICAL"
+)
+
+// Load balancer targets reported in DatabaseStatus.LoadBalancerTarget.
+const (
+	LBTargetProduction = "PRODUCTION"
+	LBTargetDisaster   = "DISASTER"
+	LBTargetOffline    = "OFFLINE"
+)
+
+// Health members identify which part of a database system a reason refers to.
+const (
+	MemberProduction   = "production"
+	MemberDisaster     = "disaster"
+	MemberLoadBalancer = "load_balancer"
+
+	// RoutedUnknown is reported in DatabaseStatus.LoadBalancerRoutedTo when the
+	// instance behind the load balancer matches neither member.
+	RoutedUnknown = "unknown"
+)
+
+// HealthReason explains why a verdict is not OK. Code is a stable, translatable
+// identifier such as "HOST_UNREACHABLE"; Member is empty for database-wide reasons.
+// Level is the level the reason raised the verdict to; Acknowledged is set when
+// an operator has acknowledged it.
+type HealthReason struct {
+	Code         string `json:"code"`
+	Member       string `json:"member,omitempty"`
+	Level        string `json:"level"`
+	Acknowledged bool   `json:"acknowledged,omitempty"`
+}
+
+// HealthVerdict is the normalized health of a member or of a whole database system.
+type HealthVerdict struct {
+	Level   string         `json:"level"`
+	Reasons []HealthReason `json:"reasons"`
+}
+
+// HealthSeverity returns the rank of a health level; higher is worse.
+func HealthSeverity(level string) int {
+	switch level {
+	case HealthOK, HealthMaintenance:
+		return 0
+	case HealthUnknown:
+		return 1
+	case HealthWarning:
+		return 2
+	case HealthCritical:
+		return 3
+	default:
+		return 1
+	}
+}
+
+// Raise adds a reason to the verdict and escalates its level if the new level is worse.
+func (v *HealthVerdict) Raise(level, code, member string) {
+	if HealthSeverity(level) > HealthSeverity(v.Level) {
+		v.Level = level
+	}
+	v.Reasons = append(v.Reasons, HealthReason{Code: code, Member: member, Level: level})
+}
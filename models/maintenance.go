@@ -0,0 +1,139 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/cron"
+)
+
+// HealthMaintenance is the level of a verdict while a maintenance window is
+// active. It ranks like OK, so that alerting on anything worse stays quiet,
+// while the reasons found during the window are still reported.
+const HealthMaintenance = "MAINTENANCE"
+
+// Where a maintenance window was defined.
+const (
+	MaintenanceFromConfig = "config"
+	MaintenanceFromAPI    = "api"
+)
+
+// MaintenanceWindow is a period in which a database, or one of its members, is
+// deliberately degraded, e.g. while the standby is patched. It is either a
+// one-off window from Start to End, or recurs on a cron Schedule and lasts
+// DurationMinutes each time.
+type MaintenanceWindow struct {
+	ID              string     `yaml:"id" json:"id"`
+	Database        string     `yaml:"database" json:"database"`
+	Member          string     `yaml:"member" json:"member,omitempty"` // "production", "disaster" or empty for the whole database
+	Start           *time.Time `yaml:"start" json:"start,omitempty"`
+	End             *time.Time `yaml:"end" json:"end,omitempty"`
+	Schedule        string     `yaml:"schedule" json:"schedule,omitempty"` // Cron expression in server local time, e.g. "0 2 * * SUN"
+	DurationMinutes int        `yaml:"duration_minutes" json:"duration_minutes,omitempty"`
+	Reason          string     `yaml:"reason" json:"reason"`
+	Owner           string     `yaml:"owner" json:"owner"`
+	Source          string     `yaml:"-" json:"source"`
+	Created         *time.Time `yaml:"-" json:"created,omitempty"` // When a window was added through the API
+
+	schedule *cron.Schedule
+}
+
+// MaintenancePeriod is an occurrence of a window that is active now, reported in DatabaseStatus.
+type MaintenancePeriod struct {
+	ID     string    `json:"id"`
+	Member string    `json:"member,omitempty"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Reason string    `json:"reason"`
+	Owner  string    `json:"owner"`
+	Source string    `json:"source"`
+}
+
+// Validate checks that the window is either one-off or recurring and prepares its schedule.
+func (w *MaintenanceWindow) Validate() error {
+	if w.Database == "" {
+		return fmt.Errorf("maintenance window '%s' has no database", w.ID)
+	}
+	if w.Member != "" && w.Member != MemberProduction && w.Member != MemberDisaster {
+		return fmt.Errorf("maintenance window '%s' has invalid member '%s' (expected '%s', '%s' or empty)", w.ID, w.Member, MemberProduction, MemberDisaster)
+	}
+	if w.Schedule != "" {
+		if w.Start != nil || w.End != nil {
+			return fmt.Errorf("maintenance window '%s' has both a schedule and start/end", w.ID)
+		}
+		if w.DurationMinutes <= 0 {
+			return fmt.Errorf("maintenance window '%s' has a schedule but no duration_minutes", w.ID)
+		}
+		schedule, err := cron.Parse(w.Schedule)
+		if err != nil {
+			return fmt.Errorf("maintenance window '%s': %w", w.ID, err)
+		}
+		w.schedule = schedule
+		return nil
+	}
+	if w.Start == nil || w.End == nil {
+		return fmt.Errorf("maintenance window '%s' needs either start and end or a schedule", w.ID)
+	}
+	if !w.End.After(*w.Start) {
+		return fmt.Errorf("maintenance window '%s' ends before it starts", w.ID)
+	}
+	return nil
+}
+
+// ActiveAt returns the occurrence of the window that covers t, if any.
+func (w *MaintenanceWindow) ActiveAt(t time.Time) (start, end time.Time, ok bool) {
+	if w.Schedule == "" {
+		if w.Start == nil || w.End == nil || t.Before(*w.Start) || !t.Before(*w.End) {
+			return time.Time{}, time.Time{}, false
+		}
+		return *w.Start, *w.End, true
+	}
+	if w.schedule == nil && w.Validate() != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	// The occurrence covering t is the first one after t - duration, if it started by t.
+	duration := time.Duration(w.DurationMinutes) * time.Minute
+	start = w.schedule.Next(t.Local().Add(-duration))
+	if start.IsZero() || start.After(t) {
+		return time.Time{}, time.Time{}, false
+	}
+	return start, start.Add(duration), true
+}
+
+// Expired reports whether a one-off window is over; recurring windows never expire.
+func (w *MaintenanceWindow) Expired(t time.Time) bool {
+	return w.Schedule == "" && w.End != nil && !t.Before(*w.End)
+}
+
+// Period describes the occurrence of the window from start to end.
+func (w *MaintenanceWindow) Period(start, end time.Time) MaintenancePeriod {
+	return MaintenancePeriod{ID: w.ID, Member: w.Member, Start: start, End: end, Reason: w.Reason, Owner: w.Owner, Source: w.Source}
+}
+
+// validateMaintenance checks the configured windows, names those without an
+// ID after their position and rejects windows for unknown databases.
+func (c *Config) validateMaintenance() error {
+	databases := make(map[string]bool, len(c.DBs))
+	for _, db := range c.DBs {
+		databases[db.Name] = true
+	}
+	ids := make(map[string]bool, len(c.Maintenance))
+	for i := range c.Maintenance {
+		w := &c.Maintenance[i]
+		if w.ID == "" {
+			w.ID = fmt.Sprintf("config-%d", i+1)
+		}
+		if ids[w.ID] {
+			return fmt.Errorf("duplicate maintenance window id '%s'", w.ID)
+		}
+		ids[w.ID] = true
+		w.Source = MaintenanceFromConfig
+		if err := w.Validate(); err != nil {
+			return err
+		}
+		if !databases[w.Database] {
+			return fmt.Errorf("maintenance window '%s' refers to unknown database '%s'", w.ID, w.Database)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func localTime(day, hour, minute int) time.Time {
+	return time.Date(2026, time.February, day, hour, minute, 0, 0, time.Local) // 2026-02-01 is a Sunday
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestMaintenanceWindowValidate(t *testing.T) {
+	start, end := localTime(1, 2, 0), localTime(1, 4, 0)
+	tests := []struct {
+		name    string
+		window  MaintenanceWindow
+		wantErr string
+	}{
+		{name: "one-off", window: MaintenanceWindow{Database: "ERP_DB", Start: &start, End: &end}},
+		{name: "recurring", window: MaintenanceWindow{Database: "ERP_DB", Member: MemberDisaster, Schedule: "0 2 * * SUN", DurationMinutes: 60}},
+		{name: "no database", window: MaintenanceWindow{Start: &start, End: &end}, wantErr: "has no database"},
+		{name: "invalid member", window: MaintenanceWindow{Database: "ERP_DB", Member: "standby", Start: &start, End: &end}, wantErr: "invalid member"},
+		{name: "schedule and start", window: MaintenanceWindow{Database: "ERP_DB", Schedule: "0 2 * * *", DurationMinutes: 60, Start: &start}, wantErr: "both a schedule and start/end"},
+		{name: "schedule without duration", window: MaintenanceWindow{Database: "ERP_DB", Schedule: "0 2 * * *"}, wantErr: "no duration_minutes"},
+		{name: "invalid schedule", window: MaintenanceWindow{Database: "ERP_DB", Schedule: "0 25 * * *", DurationMinutes: 60}, wantErr: "maintenance window"},
+		{name: "no end", window: MaintenanceWindow{Database: "ERP_DB", Start: &start}, wantErr: "needs either start and end or a schedule"},
+		{name: "ends before it starts", window: MaintenanceWindow{Database: "ERP_DB", Start: &end, End: &start}, wantErr: "ends before it starts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.window.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMaintenanceWindowActiveAt(t *testing.T) {
+	oneOff := MaintenanceWindow{Database: "ERP_DB", Start: timePtr(localTime(1, 2, 0)), End: timePtr(localTime(1, 4, 0))}
+	sunday := MaintenanceWindow{Database: "ERP_DB", Schedule: "0 2 * * SUN", DurationMinutes: 60}
+	overMidnight := MaintenanceWindow{Database: "ERP_DB", Schedule: "30 23 * * *", DurationMinutes: 90}
+
+	tests := []struct {
+		name      string
+		window    MaintenanceWindow
+		at        time.Time
+		wantStart time.Time // Zero when the window is not active
+	}{
+		{name: "one-off before start", window: oneOff, at: localTime(1, 1, 59)},
+		{name: "one-off at start", window: oneOff, at: localTime(1, 2, 0), wantStart: localTime(1, 2, 0)},
+		{name: "one-off inside", window: oneOff, at: localTime(1, 3, 30), wantStart: localTime(1, 2, 0)},
+		{name: "one-off at end", window: oneOff, at: localTime(1, 4, 0)},
+		{name: "recurring before the occurrence", window: sunday, at: localTime(1, 1, 30)},
+		{name: "recurring inside", window: sunday, at: localTime(1, 2, 30), wantStart: localTime(1, 2, 0)},
+		{name: "recurring at end", window: sunday, at: localTime(1, 3, 0)},
+		{name: "recurring on another day", window: sunday, at: localTime(2, 2, 30)},
+		{name: "recurring next week", window: sunday, at: localTime(8, 2, 59), wantStart: localTime(8, 2, 0)},
+		{name: "occurrence over midnight", window: overMidnight, at: localTime(2, 0, 30), wantStart: localTime(1, 23, 30)},
+		{name: "after the occurrence over midnight", window: overMidnight, at: localTime(2, 1, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window := tt.window
+			if err := window.Validate(); err != nil {
+				t.Fatalf("validate: %v", err)
+			}
+			start, end, ok := window.ActiveAt(tt.at)
+			if ok != !tt.wantStart.IsZero() {
+				t.Fatalf("got active %v at %s, want %v", ok, tt.at, !tt.wantStart.IsZero())
+			}
+			if ok && !start.Equal(tt.wantStart) {
+				t.Errorf("got occurrence starting %s, want %s", start, tt.wantStart)
+			}
+			if ok && !end.After(tt.at) {
+				t.Errorf("occurrence ends at %s, before %s", end, tt.at)
+			}
+		})
+	}
+}
+
+func TestMaintenanceWindowExpired(t *testing.T) {
+	oneOff := MaintenanceWindow{Database: "ERP_DB", Start: timePtr(localTime(1, 2, 0)), End: timePtr(localTime(1, 4, 0))}
+	if oneOff.Expired(localTime(1, 3, 59)) || !oneOff.Expired(localTime(1, 4, 0)) {
+		t.Error("a one-off window must expire exactly at its end")
+	}
+	recurring := MaintenanceWindow{Database: "ERP_DB", Schedule: "0 2 * * SUN", DurationMinutes: 60}
+	if recurring.Expired(localTime(28, 0, 0)) {
+		t.Error("a recurring window must never expire")
+	}
+}
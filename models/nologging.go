@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// UnrecoverableFile is a datafile of the primary that was changed by a
+// NOLOGGING operation, as reported by V$DATAFILE.
+type UnrecoverableFile struct {
+	File   int64      `json:"file"`
+	Name   string     `json:"name"`
+	Change int64      `json:"change"` // UNRECOVERABLE_CHANGE#
+	Time   *time.Time `json:"time,omitempty"`
+}
+
+// NonloggedBlocks summarizes V$NONLOGGED_BLOCK on a standby: blocks that were
+// not recovered because their changes were never written to redo.
+type NonloggedBlocks struct {
+	Files  int64 `json:"files"`
+	Ranges int64 `json:"ranges"`
+	Blocks int64 `json:"blocks"`
+}
+
+// MemberNologging is what one member reports about unrecoverable operations.
+// The primary reports its datafiles, a standby its non-logged blocks.
+type MemberNologging struct {
+	Primary      bool
+	ForceLogging string
+	Files        []UnrecoverableFile
+	// NonloggedBlocks is nil when V$NONLOGGED_BLOCK does not exist (before 12.2).
+	NonloggedBlocks *NonloggedBlocks
+	Error           *MemberError
+}
+
+// NologgingAck records who acknowledged the unrecoverable operations up to a change number.
+type NologgingAck struct {
+	Change  int64     `json:"change"`
+	By      string    `json:"by"`
+	Comment string    `json:"comment,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// NologgingStatus tells whether NOLOGGING operations on the primary left the
+// standby with blocks that cannot be recovered from redo. An operation stays
+// flagged across check cycles and restarts until it is acknowledged.
+type NologgingStatus struct {
+	PrimaryMember string `json:"primary_member,omitempty"` // "production" or "disaster"
+	ForceLogging  string `json:"force_logging,omitempty"`
+	// LatestChange is the highest UNRECOVERABLE_CHANGE# seen on the primary.
+	LatestChange    int64         `json:"latest_change"`
+	DetectedAt      *time.Time    `json:"detected_at,omitempty"`
+	Unacknowledged  bool          `json:"unacknowledged"`
+	Acknowledgement *NologgingAck `json:"acknowledgement,omitempty"`
+	// Files lists the datafiles with operations that are not acknowledged yet.
+	Files           []UnrecoverableFile `json:"files"`
+	StandbyMember   string              `json:"standby_member,omitempty"`
+	NonloggedBlocks *NonloggedBlocks    `json:"nonlogged_blocks,omitempty"`
+	PrimaryError    *MemberError        `json:"primary_error,omitempty"`
+	StandbyError    *MemberError        `json:"standby_error,omitempty"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// AlertAck marks a firing health reason as known and being worked on. It is
+// dropped when it expires or when the reason clears. Acknowledging does not
+// change the health level; the reason is flagged as acknowledged.
+type AlertAck struct {
+	ID       string     `json:"id"`
+	Database string     `json:"database"`
+	Code     string     `json:"code"`             // Health reason code, e.g. "LAG_CRITICAL"
+	Member   string     `json:"member,omitempty"` // Member of the reason; empty for database-wide reasons
+	Comment  string     `json:"comment,omitempty"`
+	By       string     `json:"by"`
+	Time     time.Time  `json:"time"`
+	Expires  *time.Time `json:"expires,omitempty"`
+}
+
+// Matches reports whether the acknowledgement covers a health reason.
+func (a AlertAck) Matches(reason HealthReason) bool {
+	return a.Code == reason.Code && a.Member == reason.Member
+}
+
+// Note is free-form operator text pinned to a database, e.g. "apply paused
+// for index rebuild until 14:00". It stays until removed or until it expires.
+type Note struct {
+	ID       string     `json:"id"`
+	Database string     `json:"database"`
+	Text     string     `json:"text"`
+	By       string     `json:"by"`
+	Time     time.Time  `json:"time"`
+	Expires  *time.Time `json:"expires,omitempty"`
+}
+
+// expiredAt reports whether an optional expiry has passed.
+func expiredAt(expires *time.Time, t time.Time) bool {
+	return expires != nil && !t.Before(*expires)
+}
+
+// Expired reports whether the acknowledgement has run out.
+func (a AlertAck) Expired(t time.Time) bool {
+	return expiredAt(a.Expires, t)
+}
+
+// Expired reports whether the note has run out.
+func (n Note) Expired(t time.Time) bool {
+	return expiredAt(n.Expires, t)
+}
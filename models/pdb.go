@@ -0,0 +1,35 @@
+package models
+
+// PDBState is one pluggable database as reported by V$PDBS on a member.
+type PDBState struct {
+	ConID          int64  `json:"con_id"`
+	Name           string `json:"name"`
+	OpenMode       string `json:"open_mode"`
+	Restricted     string `json:"restricted"`
+	RecoveryStatus string `json:"recovery_status"` // ENABLED or DISABLED; only meaningful on a standby
+}
+
+// PDBReport pairs the pluggable databases of both members by name.
+type PDBReport struct {
+	PDBs            []PDBComparison `json:"pdbs"`
+	Mismatches      int             `json:"mismatches"` // Number of PDBs with at least one issue
+	ProductionError *MemberError    `json:"production_error,omitempty"`
+	DisasterError   *MemberError    `json:"disaster_error,omitempty"`
+}
+
+// PDBComparison is a pluggable database on both members; a side is nil when
+// the PDB is missing there or the member could not be queried.
+type PDBComparison struct {
+	Name       string     `json:"name"`
+	Production *PDBState  `json:"production,omitempty"`
+	Disaster   *PDBState  `json:"disaster,omitempty"`
+	Issues     []PDBIssue `json:"issues"`
+}
+
+// PDBIssue is a problem with a PDB on one member, e.g. "PDB_RESTRICTED", and
+// the health level it raises.
+type PDBIssue struct {
+	Code   string `json:"code"`
+	Member string `json:"member"`
+	Level  string `json:"level"`
+}
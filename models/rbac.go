@@ -0,0 +1,114 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Permissions granted through roles. Each guards a group of routes.
+const (
+	PermView        = "view"        // Dashboard, status API and history
+	PermDiagnostics = "diagnostics" // SQL-derived details and the monitoring account self-check
+	PermAcknowledge = "acknowledge" // Acknowledge and silence alerts
+	PermRecheck     = "recheck"     // Trigger an immediate check cycle
+	PermReload      = "reload"      // Reload the configuration files
+	PermMaintenance = "maintenance" // Manage maintenance windows
+	PermAudit       = "audit"       // Read the audit log
+)
+
+// AllPermissions lists every known permission.
+var AllPermissions = []string{PermView, PermDiagnostics, PermAcknowledge, PermRecheck, PermReload, PermMaintenance, PermAudit}
+
+// BuiltinRoles are available without configuration; auth.roles may add more
+// but not redefine these.
+var BuiltinRoles = map[string][]string{
+	"viewer":   {PermView},
+	"operator": {PermView, PermAcknowledge},
+	"dba":      {PermView, PermDiagnostics, PermAcknowledge, PermRecheck, PermMaintenance},
+	"admin":    AllPermissions,
+}
+
+// Grant assigns roles to a user, token or OIDC group. When Tags is set, the
+// holder only sees and acts on databases carrying at least one of the tags.
+type Grant struct {
+	Roles []string `yaml:"roles"`
+	Tags  []string `yaml:"tags"`
+}
+
+// GroupGrant grants roles to members of an OIDC group.
+type GroupGrant struct {
+	Group string `yaml:"group"`
+	Grant `yaml:",inline"`
+}
+
+// RolePermissions returns the permissions of a built-in or configured role.
+func (a AuthConfig) RolePermissions(role string) ([]string, bool) {
+	if permissions, ok := BuiltinRoles[role]; ok {
+		return permissions, true
+	}
+	permissions, ok := a.Roles[role]
+	return permissions, ok
+}
+
+// normalizeRoles lower-cases the permission names of custom roles, which are
+// compared exactly when a request is authorized.
+func (a AuthConfig) normalizeRoles() {
+	for _, permissions := range a.Roles {
+		for i, permission := range permissions {
+			permissions[i] = strings.ToLower(permission)
+		}
+	}
+}
+
+// validateRoles checks that custom roles use known permissions and that every
+// grant refers to an existing role.
+func (a AuthConfig) validateRoles() error {
+	names := make([]string, 0, len(a.Roles))
+	for name := range a.Roles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := BuiltinRoles[name]; ok {
+			return fmt.Errorf("auth.roles: '%s' is a built-in role and cannot be redefined", name)
+		}
+		for _, permission := range a.Roles[name] {
+			if !contains(AllPermissions, permission) {
+				return fmt.Errorf("auth.roles: role '%s' has unknown permission '%s'", name, permission)
+			}
+		}
+	}
+
+	type holder struct {
+		name  string
+		roles []string
+	}
+	holders := []holder{{"auth.default_roles", a.DefaultRoles}}
+	for _, user := range a.Users {
+		holders = append(holders, holder{"user '" + user.Username + "'", user.Roles})
+	}
+	for _, token := range a.APITokens {
+		holders = append(holders, holder{"API token '" + token.Name + "'", token.Roles})
+	}
+	for _, group := range a.OIDC.GroupRoles {
+		holders = append(holders, holder{"OIDC group '" + group.Group + "'", group.Roles})
+	}
+	for _, h := range holders {
+		for _, role := range h.roles {
+			if _, ok := a.RolePermissions(role); !ok {
+				return fmt.Errorf("auth: %s has unknown role '%s'", h.name, role)
+			}
+		}
+	}
+	return nil
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
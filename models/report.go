@@ -0,0 +1,105 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/cron"
+)
+
+// Formats of the scheduled status report.
+const (
+	ReportFormatHTML = "html"
+	ReportFormatPDF  = "pdf"
+)
+
+// schedulePeriodRegex matches the periods a recurring report may cover; a
+// fixed month would produce the same report every time.
+var schedulePeriodRegex = regexp.MustCompile(`^(last-month|this-month|[1-9][0-9]*d)$`)
+
+// ReportsConfig configures status reports generated on a schedule, e.g. as
+// weekly evidence of the DR state for auditors.
+type ReportsConfig struct {
+	ArchiveDir string           `yaml:"archive_dir"` // Where generated reports are kept
+	Schedules  []ReportSchedule `yaml:"schedules"`
+}
+
+// ReportSchedule is one recurring report.
+type ReportSchedule struct {
+	Name     string   `yaml:"name"`     // Used in the file names, e.g. "weekly"
+	Schedule string   `yaml:"schedule"` // Cron expression in server local time, e.g. "0 6 * * MON"
+	Period   string   `yaml:"period"`   // Covered period ending at generation: "<days>d", "last-month" or "this-month"
+	Language string   `yaml:"language"` // "en", "zh" or "ja"
+	Formats  []string `yaml:"formats"`  // "html" and/or "pdf"
+	Email    []string `yaml:"email"`    // Recipients; the reports are attached
+
+	// Databases included, selected like the ?db, ?group and ?tag filters; all when empty.
+	Databases []string `yaml:"databases"`
+	Group     string   `yaml:"group"`
+	Tags      []string `yaml:"tags"`
+
+	schedule *cron.Schedule
+}
+
+// Next returns the first time after t at which the report is due.
+func (s ReportSchedule) Next(t time.Time) time.Time {
+	if s.schedule == nil {
+		return time.Time{}
+	}
+	return s.schedule.Next(t)
+}
+
+// Filter returns the filter selecting the databases of the report.
+func (s ReportSchedule) Filter() DatabaseFilter {
+	return DatabaseFilter{Names: s.Databases, Group: s.Group, Tags: s.Tags}
+}
+
+// validateReports checks the report schedules and fills in their defaults.
+func (c *Config) validateReports() error {
+	if c.Reports.ArchiveDir == "" {
+		c.Reports.ArchiveDir = "reports"
+	}
+	names := make(map[string]bool, len(c.Reports.Schedules))
+	for i := range c.Reports.Schedules {
+		s := &c.Reports.Schedules[i]
+		if s.Name == "" {
+			s.Name = fmt.Sprintf("report-%d", i+1)
+		}
+		if names[s.Name] || strings.ContainsAny(s.Name, `/\ `) {
+			return fmt.Errorf("report schedule name '%s' is duplicate or not usable in a file name", s.Name)
+		}
+		names[s.Name] = true
+		schedule, err := cron.Parse(s.Schedule)
+		if err != nil {
+			return fmt.Errorf("report schedule '%s': %w", s.Name, err)
+		}
+		s.schedule = schedule
+		if s.Period == "" {
+			s.Period = "7d"
+		}
+		if !schedulePeriodRegex.MatchString(s.Period) {
+			return fmt.Errorf("report schedule '%s' has invalid period '%s' (expected <days>d, last-month or this-month)", s.Name, s.Period)
+		}
+		switch s.Language {
+		case "":
+			s.Language = "en"
+		case "en", "zh", "ja":
+		default:
+			return fmt.Errorf("report schedule '%s' has unsupported language '%s' (expected en, zh or ja)", s.Name, s.Language)
+		}
+		if len(s.Formats) == 0 {
+			s.Formats = []string{ReportFormatHTML, ReportFormatPDF}
+		}
+		for _, format := range s.Formats {
+			if format != ReportFormatHTML && format != ReportFormatPDF {
+				return fmt.Errorf("report schedule '%s' has unsupported format '%s' (expected %s or %s)", s.Name, format, ReportFormatHTML, ReportFormatPDF)
+			}
+		}
+		if len(s.Email) > 0 && c.Notifier.Email.SMTPHost == "" {
+			return fmt.Errorf("report schedule '%s' has email recipients but notifier.email.smtp_host is not set", s.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package models
+
+// SpaceUsage describes the fast recovery area and the local archive
+// destinations of one member.
+type SpaceUsage struct {
+	RecoveryArea *RecoveryAreaUsage `json:"recovery_area"` // nil when no recovery area is configured
+	ArchiveDests []ArchiveDestSpace `json:"archive_dests"`
+	Error        *MemberError       `json:"error,omitempty"`
+	Level        string             `json:"level"` // Health level of the space findings alone, set with the verdicts
+}
+
+// RecoveryAreaUsage holds V$RECOVERY_FILE_DEST and V$RECOVERY_AREA_USAGE.
+type RecoveryAreaUsage struct {
+	Name             string                  `json:"name"`
+	LimitBytes       int64                   `json:"limit_bytes"`
+	UsedBytes        int64                   `json:"used_bytes"`
+	ReclaimableBytes int64                   `json:"reclaimable_bytes"`
+	UsedPercent      float64                 `json:"used_percent"` // Space that cannot be reclaimed, relative to the limit
+	FileTypes        []RecoveryFileTypeUsage `json:"file_types"`
+
+	// Estimated from the recorded history; nil while there is not enough of it.
+	ArchiveBytesPerHour *float64 `json:"archive_bytes_per_hour,omitempty"`
+	HoursToFull         *float64 `json:"hours_to_full,omitempty"`
+}
+
+// ArchivedLogBytes returns the space taken by archived logs in the recovery area.
+func (r *RecoveryAreaUsage) ArchivedLogBytes() int64 {
+	for _, fileType := range r.FileTypes {
+		if fileType.FileType == "ARCHIVED LOG" {
+			return int64(fileType.PercentUsed / 100 * float64(r.LimitBytes))
+		}
+	}
+	return 0
+}
+
+// RecoveryFileTypeUsage is one row of V$RECOVERY_AREA_USAGE.
+type RecoveryFileTypeUsage struct {
+	FileType           string  `json:"file_type"`
+	PercentUsed        float64 `json:"percent_used"`
+	PercentReclaimable float64 `json:"percent_reclaimable"`
+	NumberOfFiles      int     `json:"number_of_files"`
+}
+
+// ArchiveDestSpace describes a local archive destination. Free space is only
+// known for the recovery area and ASM disk groups; for a file system path the
+// size fields are omitted and only the status reveals a full destination.
+type ArchiveDestSpace struct {
+	DestName    string   `json:"dest_name"`
+	Destination string   `json:"destination"`
+	Status      string   `json:"status"`
+	Error       string   `json:"error,omitempty"`
+	TotalBytes  *int64   `json:"total_bytes,omitempty"`
+	FreeBytes   *int64   `json:"free_bytes,omitempty"`
+	UsedPercent *float64 `json:"used_percent,omitempty"`
+}
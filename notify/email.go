@@ -0,0 +1,147 @@
+// Package notify sends messages from the dashboard to people, currently by email.
+package notify
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+// dialTimeout limits how long connecting to the mail server may take.
+const dialTimeout = 30 * time.Second
+
+// Message is an email with a plain text body and optional attachments.
+type Message struct {
+	To          []string
+	Subject     string
+	Text        string
+	Attachments []Attachment
+}
+
+// Attachment is a file attached to a message.
+type Attachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// SendEmail delivers a message through the configured mail server.
+func SendEmail(cfg models.EmailConfig, msg Message) error {
+	if cfg.SMTPHost == "" {
+		return errors.New("no mail server configured (notifier.email.smtp_host)")
+	}
+	if len(msg.To) == 0 {
+		return errors.New("message has no recipients")
+	}
+	addr := net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort))
+	tlsConfig := &tls.Config{ServerName: cfg.SMTPHost}
+
+	var conn net.Conn
+	var err error
+	if cfg.TLS == models.EmailTLSImplicit {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: dialTimeout}, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = net.DialTimeout("tcp", addr, dialTimeout)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to mail server %s: %w", addr, err)
+	}
+	client, err := smtp.NewClient(conn, cfg.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to talk to mail server %s: %w", addr, err)
+	}
+	defer client.Close()
+
+	if cfg.TLS == models.EmailTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("mail server %s does not support STARTTLS (set notifier.email.tls to \"none\" to send unencrypted)", addr)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS with mail server %s failed: %w", addr, err)
+		}
+	}
+	if cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.SMTPHost)); err != nil {
+			return fmt.Errorf("mail server authentication failed: %w", err)
+		}
+	}
+	if err := client.Mail(cfg.From); err != nil {
+		return fmt.Errorf("mail server rejected sender %s: %w", cfg.From, err)
+	}
+	for _, to := range msg.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("mail server rejected recipient %s: %w", to, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(compose(cfg.From, msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("mail server did not accept the message: %w", err)
+	}
+	return client.Quit()
+}
+
+// compose builds a MIME message; attachments are base64-encoded parts of a multipart/mixed body.
+func compose(from string, msg Message) []byte {
+	var b bytes.Buffer
+	boundary := randomBoundary()
+	header := func(name, value string) { fmt.Fprintf(&b, "%s: %s\r\n", name, value) }
+	header("From", from)
+	header("To", strings.Join(msg.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", fmt.Sprintf(`multipart/mixed; boundary="%s"`, boundary))
+	b.WriteString("\r\n")
+
+	fmt.Fprintf(&b, "--%s\r\n", boundary)
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "base64")
+	b.WriteString("\r\n")
+	writeBase64(&b, []byte(msg.Text))
+
+	for _, a := range msg.Attachments {
+		fmt.Fprintf(&b, "--%s\r\n", boundary)
+		header("Content-Type", a.ContentType)
+		header("Content-Transfer-Encoding", "base64")
+		header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Name}))
+		b.WriteString("\r\n")
+		writeBase64(&b, a.Data)
+	}
+	fmt.Fprintf(&b, "--%s--\r\n", boundary)
+	return b.Bytes()
+}
+
+// writeBase64 writes data base64-encoded in lines of 76 characters, as MIME requires.
+func writeBase64(b *bytes.Buffer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+}
+
+func randomBoundary() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,161 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"strings"
+	"time"
+)
+
+// Colors of the lag charts, shared by the SVG and PDF renderings.
+const (
+	chartLagColor     = "#1677ff"
+	chartRPOColor     = "#cf1322"
+	chartUnknownColor = "#ffccc7"
+	chartGridColor    = "#d9d9d9"
+	chartTextColor    = "#595959"
+)
+
+// chartPoint is a position inside a chart, measured from its top left corner.
+type chartPoint struct{ X, Y float64 }
+
+// chartTick is a labelled position on an axis.
+type chartTick struct {
+	Pos   float64
+	Label string
+}
+
+// lagChart is the geometry of a lag chart, computed once and drawn as SVG or PDF.
+type lagChart struct {
+	Width, Height            float64
+	Left, Top, Right, Bottom float64        // Plot area
+	Lines                    [][]chartPoint // Lag polylines, split where the lag is unknown or missing
+	Unknown                  [][2]float64   // X ranges in which the standby could not be queried
+	RPOY                     float64        // Y of the RPO line
+	XTicks, YTicks           []chartTick
+}
+
+// newLagChart lays out the lag points of a period in a chart of the given size.
+// Points further apart than two intervals are not connected.
+func newLagChart(points []LagPoint, period Period, interval time.Duration, rpoSeconds int, width, height float64) lagChart {
+	c := lagChart{Width: width, Height: height, Left: 48, Top: 8, Right: width - 8, Bottom: height - 18}
+	maxLag := float64(rpoSeconds)
+	for _, p := range points {
+		maxLag = math.Max(maxLag, p.Lag)
+	}
+	yMax := axisMaximum(maxLag * 1.1)
+	span := period.To.Sub(period.From).Seconds()
+	x := func(t time.Time) float64 {
+		return c.Left + (c.Right-c.Left)*t.Sub(period.From).Seconds()/span
+	}
+	y := func(lag float64) float64 {
+		return c.Bottom - (c.Bottom-c.Top)*lag/yMax
+	}
+	c.RPOY = y(float64(rpoSeconds))
+
+	var line []chartPoint
+	var previous time.Time
+	for _, p := range points {
+		connected := !previous.IsZero() && p.Time.Sub(previous) <= 2*interval
+		if !connected && len(line) > 0 {
+			c.Lines = append(c.Lines, line)
+			line = nil
+		}
+		previous = p.Time
+		if p.Lag < 0 {
+			if len(line) > 0 {
+				c.Lines = append(c.Lines, line)
+				line = nil
+			}
+			start := x(p.Time)
+			end := math.Min(x(p.Time.Add(interval)), c.Right)
+			if n := len(c.Unknown); n > 0 && c.Unknown[n-1][1] >= start-0.01 {
+				c.Unknown[n-1][1] = end
+			} else {
+				c.Unknown = append(c.Unknown, [2]float64{start, end})
+			}
+			continue
+		}
+		line = append(line, chartPoint{x(p.Time), y(p.Lag)})
+	}
+	if len(line) > 0 {
+		c.Lines = append(c.Lines, line)
+	}
+
+	for i := 0; i <= 4; i++ {
+		lag := yMax * float64(i) / 4
+		c.YTicks = append(c.YTicks, chartTick{Pos: y(lag), Label: formatDuration(lag)})
+	}
+	c.XTicks = timeTicks(period, x)
+	return c
+}
+
+// timeTicks returns up to eight labels along the time axis: days for periods
+// of two days or more, otherwise hours.
+func timeTicks(period Period, x func(time.Time) float64) []chartTick {
+	span := period.To.Sub(period.From)
+	step, layout := time.Hour, "15:04"
+	if span >= 48*time.Hour {
+		step, layout = 24*time.Hour, "01-02"
+	}
+	for span/step > 8 {
+		step *= 2
+	}
+	t := period.From.Truncate(time.Hour)
+	if step >= 24*time.Hour {
+		t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+	var ticks []chartTick
+	for ; t.Before(period.To); t = t.Add(step) {
+		if !t.Before(period.From) {
+			ticks = append(ticks, chartTick{Pos: x(t), Label: t.Format(layout)})
+		}
+	}
+	return ticks
+}
+
+// durationSteps are the spacings of the lag axis ticks, in seconds.
+var durationSteps = []float64{5, 15, 30, 60, 120, 300, 600, 900, 1800, 3600, 7200, 14400, 21600, 43200, 86400}
+
+// axisMaximum returns the top of the lag axis: four ticks of a round duration covering v.
+func axisMaximum(v float64) float64 {
+	for _, step := range durationSteps {
+		if 4*step >= v {
+			return 4 * step
+		}
+	}
+	return 4 * 86400 * math.Ceil(v/(4*86400))
+}
+
+// SVG returns the chart as an inline SVG element.
+func (c lagChart) SVG() template.HTML {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="sans-serif" font-size="9">`, c.Width, c.Height, c.Width, c.Height)
+	for _, u := range c.Unknown {
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`, u[0], c.Top, math.Max(u[1]-u[0], 0.5), c.Bottom-c.Top, chartUnknownColor)
+	}
+	for _, tick := range c.YTicks {
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-width="0.5"/>`, c.Left, tick.Pos, c.Right, tick.Pos, chartGridColor)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="end" fill="%s">%s</text>`, c.Left-4, tick.Pos+3, chartTextColor, template.HTMLEscapeString(tick.Label))
+	}
+	for _, tick := range c.XTicks {
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-width="0.5"/>`, tick.Pos, c.Top, tick.Pos, c.Bottom, chartGridColor)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="middle" fill="%s">%s</text>`, tick.Pos, c.Height-5, chartTextColor, template.HTMLEscapeString(tick.Label))
+	}
+	fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-width="1" stroke-dasharray="4 3"/>`, c.Left, c.RPOY, c.Right, c.RPOY, chartRPOColor)
+	for _, line := range c.Lines {
+		points := make([]string, len(line))
+		for i, p := range line {
+			points[i] = fmt.Sprintf("%.1f,%.1f", p.X, p.Y)
+		}
+		if len(line) == 1 {
+			fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="1.5" fill="%s"/>`, line[0].X, line[0].Y, chartLagColor)
+			continue
+		}
+		fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="1.2"/>`, strings.Join(points, " "), chartLagColor)
+	}
+	fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="none" stroke="%s" stroke-width="0.8"/>`, c.Left, c.Top, c.Right-c.Left, c.Bottom-c.Top, chartTextColor)
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
@@ -0,0 +1,199 @@
+package report
+
+import (
+	"io"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/history"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+// Export datasets.
+const (
+	ExportStatusData  = "status"
+	ExportHistoryData = "history"
+	ExportEventsData  = "events"
+)
+
+// statusColumns flatten a DatabaseStatus. Health levels and reason codes are
+// exported as codes, so that the values do not depend on the language.
+var statusColumns = append([]column{
+	{Key: "name", Header: "reportDatabase"},
+	{Key: "group", Header: "reportGroup"},
+	{Key: "tier", Header: "exportTier"},
+	{Key: "tags", Header: "exportTags"},
+	{Key: "checked_at", Header: "reportCollected"},
+	{Key: "health", Header: "reportHealth"},
+	{Key: "reasons", Header: "reportReasons"},
+	{Key: "load_balancer_target", Header: "exportLoadBalancerTarget"},
+	{Key: "load_balancer_ip", Header: "lbIpLabel", Member: models.MemberLoadBalancer},
+	{Key: "load_balancer_alive", Header: "exportAlive", Member: models.MemberLoadBalancer},
+	{Key: "load_balancer_port_1521", Header: "exportPort", Member: models.MemberLoadBalancer},
+	{Key: "load_balancer_db_connect", Header: "exportDbConnect", Member: models.MemberLoadBalancer},
+	{Key: "load_balancer_routed_to", Header: "exportRoutedTo", Member: models.MemberLoadBalancer},
+	{Key: "connections", Header: "connectionsLabel"},
+}, memberColumns([]column{
+	{Key: "ip", Header: "lbIpLabel"},
+	{Key: "alive", Header: "exportAlive"},
+	{Key: "port_1521", Header: "exportPort"},
+	{Key: "listener", Header: "exportListener"},
+	{Key: "db_connect", Header: "exportDbConnect"},
+	{Key: "status", Header: "statusLabel"},
+	{Key: "role", Header: "roleLabel"},
+	{Key: "health", Header: "reportHealth"},
+	{Key: "dgdelay", Header: "exportDgDelay"},
+	{Key: "heartbeat_lag", Header: "exportHeartbeatLag"},
+	{Key: "fra_used_percent", Header: "exportFRAUsed"},
+	{Key: "db_unique_name", Header: "exportDbUniqueName"},
+	{Key: "instance_name", Header: "exportInstance"},
+	{Key: "error", Header: "exportError"},
+	{Key: "rtt_ms", Header: "exportRTT"},
+	{Key: "packet_loss", Header: "exportPacketLoss"},
+})...)
+
+// historyColumns flatten a history.Sample.
+var historyColumns = append([]column{
+	{Key: "time", Header: "reportTime"},
+	{Key: "database", Header: "reportDatabase"},
+	{Key: "health", Header: "reportHealth"},
+	{Key: "reasons", Header: "reportReasons"},
+	{Key: "load_balancer_target", Header: "exportLoadBalancerTarget"},
+	{Key: "maintenance", Header: "exportMaintenance"},
+}, memberColumns([]column{
+	{Key: "role", Header: "roleLabel"},
+	{Key: "status", Header: "statusLabel"},
+	{Key: "db_connect", Header: "exportDbConnect"},
+	{Key: "lag", Header: "exportLag"},
+	{Key: "maintenance", Header: "exportMaintenance"},
+	{Key: "fra_used_percent", Header: "exportFRAUsed"},
+	{Key: "archived_log_bytes", Header: "exportArchivedLogBytes"},
+})...)
+
+var eventColumns = []column{
+	{Key: "time", Header: "reportTime"},
+	{Key: "database", Header: "reportDatabase"},
+	{Key: "member", Header: "exportMember"},
+	{Key: "type", Header: "reportEventType"},
+	{Key: "message", Header: "reportMessage"},
+	{Key: "details", Header: "exportDetails"},
+}
+
+// ExportStatus writes one row per database of the latest check cycle.
+func ExportStatus(w io.Writer, format string, statuses []models.DatabaseStatus, collectedAt time.Time, t Translator) error {
+	table, err := newTableWriter(w, format, statusColumns, t, t("exportStatusSheet"))
+	if err != nil {
+		return err
+	}
+	for _, s := range statuses {
+		row := []interface{}{
+			s.Name, s.Group, s.Tier, s.Tags, collectedAt, s.Health.Level, reasonCodes(s.Health), s.LoadBalancerTarget,
+			s.LoadBalancerIP, s.LoadBalancerAlive, s.LoadBalancerPort1521, s.LoadBalancerDbConnect, s.LoadBalancerRoutedTo,
+			s.Connections,
+		}
+		row = append(row, statusMemberCells(s.ProductionIP, s.ProductionAlive, s.ProductionPort1521, s.ProductionListener, s.ProductionDbConnect,
+			s.ProductionStatus, s.ProductionRole, s.ProductionHealth, s.ProductionDgDelay, s.ProductionHeartbeat, s.ProductionSpace,
+			s.ProductionDbUniqueName, s.ProductionInstanceName, s.ProductionError, s.ProductionPing)...)
+		row = append(row, statusMemberCells(s.DisasterIP, s.DisasterAlive, s.DisasterPort1521, s.DisasterListener, s.DisasterDbConnect,
+			s.DisasterStatus, s.DisasterRole, s.DisasterHealth, s.DisasterDgDelay, s.DisasterHeartbeat, s.DisasterSpace,
+			s.DisasterDbUniqueName, s.DisasterInstanceName, s.DisasterError, s.DisasterPing)...)
+		if err := table.WriteRow(row); err != nil {
+			return err
+		}
+	}
+	return table.Close()
+}
+
+func statusMemberCells(ip string, alive, port bool, listener string, connected bool, status, role string, health models.HealthVerdict,
+	delay int, heartbeat *models.HeartbeatLag, space *models.SpaceUsage, uniqueName, instance string, memberErr *models.MemberError, ping *models.PingStats) []interface{} {
+	var heartbeatLag, fraUsed, rtt, loss interface{}
+	if heartbeat != nil && heartbeat.Error == nil {
+		heartbeatLag = heartbeat.LagSeconds
+	}
+	if space != nil && space.RecoveryArea != nil {
+		fraUsed = space.RecoveryArea.UsedPercent
+	}
+	if ping != nil {
+		rtt, loss = ping.RTTMs, ping.PacketLoss
+	}
+	errorText := ""
+	if memberErr != nil {
+		errorText = memberErr.Category
+		if memberErr.Code != "" {
+			errorText += " " + memberErr.Code
+		}
+	}
+	return []interface{}{ip, alive, port, listener, connected, status, role, health.Level, delay, heartbeatLag, fraUsed, uniqueName, instance, errorText, rtt, loss}
+}
+
+func reasonCodes(verdict models.HealthVerdict) []string {
+	codes := make([]string, len(verdict.Reasons))
+	for i, reason := range verdict.Reasons {
+		codes[i] = reason.Code
+	}
+	return codes
+}
+
+// ExportHistory writes the samples of the databases that pass the filter
+// recorded in [from, to), oldest first. Unknown lags are empty.
+func ExportHistory(w io.Writer, format string, store *history.Store, cfg models.Config, filter models.DatabaseFilter, from, to time.Time, t Translator) error {
+	table, err := newTableWriter(w, format, historyColumns, t, t("exportHistorySheet"))
+	if err != nil {
+		return err
+	}
+	databases := filteredDatabases(cfg, filter)
+	err = store.Samples(from, to, func(s history.Sample) error {
+		if !databases[s.Database] {
+			return nil
+		}
+		row := []interface{}{s.Time, s.Database, s.Level, s.Reasons, s.LoadBalancerTarget, s.Maintenance}
+		row = append(row, sampleMemberCells(s.Production)...)
+		row = append(row, sampleMemberCells(s.Disaster)...)
+		return table.WriteRow(row)
+	})
+	if err != nil {
+		return err
+	}
+	return table.Close()
+}
+
+func sampleMemberCells(m history.MemberSample) []interface{} {
+	var lag, archived interface{}
+	if m.Lag >= 0 {
+		lag = m.Lag
+	}
+	if m.ArchivedLogBytes != nil {
+		archived = *m.ArchivedLogBytes
+	}
+	return []interface{}{m.Role, m.Status, m.Connected, lag, m.Maintenance, m.FRAUsedPercent, archived}
+}
+
+// ExportEvents writes the events of the databases that pass the filter
+// recorded in [from, to), oldest first.
+func ExportEvents(w io.Writer, format string, store *history.Store, cfg models.Config, filter models.DatabaseFilter, from, to time.Time, t Translator) error {
+	table, err := newTableWriter(w, format, eventColumns, t, t("exportEventsSheet"))
+	if err != nil {
+		return err
+	}
+	databases := filteredDatabases(cfg, filter)
+	err = store.Events(from, to, func(e history.Event) error {
+		if !databases[e.Database] {
+			return nil
+		}
+		return table.WriteRow([]interface{}{e.Time, e.Database, e.Member, e.Type, e.Message, e.Details})
+	})
+	if err != nil {
+		return err
+	}
+	return table.Close()
+}
+
+// filteredDatabases returns the names of the configured databases that pass the filter.
+func filteredDatabases(cfg models.Config, filter models.DatabaseFilter) map[string]bool {
+	databases := make(map[string]bool)
+	for _, db := range cfg.DBs {
+		if filter.MatchesDatabase(db) {
+			databases[db.Name] = true
+		}
+	}
+	return databases
+}
@@ -0,0 +1,301 @@
+package report
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// A4 in points, and the page margin.
+const (
+	pageWidth  = 595.28
+	pageHeight = 841.89
+	pageMargin = 40.0
+)
+
+// cjkFonts are the Adobe CJK fonts used for Chinese and Japanese text. PDF
+// viewers provide them, so nothing has to be embedded.
+var cjkFonts = map[string]struct{ name, encoding, ordering string }{
+	"zh": {"STSong-Light", "UniGB-UCS2-H", "GB1"},
+	"ja": {"HeiseiKakuGo-W5", "UniJIS-UCS2-H", "Japan1"},
+}
+
+// helveticaWidths are the widths of the printable ASCII characters in
+// Helvetica, in thousandths of the font size.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// pdfDocument draws text, lines and rectangles on A4 pages and writes them
+// as a PDF file. Positions are in points from the top left corner of the page.
+type pdfDocument struct {
+	cjk     string // Language of the CJK font in use; empty for Helvetica
+	winAnsi *encoding.Encoder
+	pages   []*bytes.Buffer
+	page    *bytes.Buffer
+	y       float64 // Top of the free space on the current page
+}
+
+// newPDFDocument starts a document whose text is in the given language.
+func newPDFDocument(lang string) *pdfDocument {
+	d := &pdfDocument{winAnsi: encoding.ReplaceUnsupported(charmap.Windows1252.NewEncoder())}
+	if _, ok := cjkFonts[lang]; ok {
+		d.cjk = lang
+	}
+	d.newPage()
+	return d
+}
+
+func (d *pdfDocument) newPage() {
+	d.page = &bytes.Buffer{}
+	d.pages = append(d.pages, d.page)
+	d.y = pageMargin
+}
+
+// ensure starts a new page unless height points fit below the cursor.
+func (d *pdfDocument) ensure(height float64) {
+	if d.y+height > pageHeight-pageMargin {
+		d.newPage()
+	}
+}
+
+// text draws s with its baseline at y.
+func (d *pdfDocument) text(x, y, size float64, bold bool, color, s string) {
+	if s == "" {
+		return
+	}
+	fmt.Fprintf(d.page, "BT %s rg ", pdfColor(color))
+	font := "F1"
+	if bold {
+		if d.cjk != "" {
+			// The CJK fonts have no bold face; outline the glyphs instead.
+			fmt.Fprintf(d.page, "2 Tr %s RG 0.3 w ", pdfColor(color))
+		} else {
+			font = "F2"
+		}
+	}
+	fmt.Fprintf(d.page, "/%s %s Tf %s %s Td %s Tj ", font, num(size), num(x), num(pageHeight-y), d.encode(s))
+	if bold && d.cjk != "" {
+		d.page.WriteString("0 Tr ") // The rendering mode outlives ET
+	}
+	d.page.WriteString("ET\n")
+}
+
+// textRight draws s ending at x.
+func (d *pdfDocument) textRight(x, y, size float64, bold bool, color, s string) {
+	d.text(x-d.width(s, size, bold), y, size, bold, color, s)
+}
+
+// width returns the width of s in points.
+func (d *pdfDocument) width(s string, size float64, bold bool) float64 {
+	total := 0
+	for _, r := range s {
+		switch {
+		case d.cjk != "" && r >= 0x80:
+			total += 1000
+		case d.cjk != "":
+			total += 500
+		case r >= 32 && r < 127:
+			total += helveticaWidths[r-32]
+		default:
+			total += 556
+		}
+	}
+	w := float64(total) * size / 1000
+	if bold && d.cjk == "" {
+		w *= 1.08 // Helvetica-Bold is slightly wider
+	}
+	return w
+}
+
+// fit shortens s with an ellipsis until it is at most width points wide.
+func (d *pdfDocument) fit(s string, width, size float64, bold bool) string {
+	if d.width(s, size, bold) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && d.width(string(runes)+"…", size, bold) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// wrap splits s into lines of at most width points, breaking at spaces where possible.
+func (d *pdfDocument) wrap(s string, width, size float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		line := ""
+		for _, r := range paragraph {
+			candidate := line + string(r)
+			if d.width(candidate, size, false) <= width {
+				line = candidate
+				continue
+			}
+			if i := strings.LastIndex(line, " "); i > 0 && r != ' ' {
+				lines = append(lines, line[:i])
+				line = line[i+1:] + string(r)
+			} else {
+				lines = append(lines, line)
+				line = strings.TrimLeft(string(r), " ")
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// line draws a straight line; a non-empty dash such as "3 2" makes it dashed.
+func (d *pdfDocument) line(x1, y1, x2, y2, width float64, color, dash string) {
+	fmt.Fprintf(d.page, "%s RG %s w [%s] 0 d %s %s m %s %s l S [] 0 d\n", pdfColor(color), num(width), dash, num(x1), num(pageHeight-y1), num(x2), num(pageHeight-y2))
+}
+
+// polyline draws connected line segments.
+func (d *pdfDocument) polyline(points []chartPoint, offsetX, offsetY, width float64, color string) {
+	if len(points) == 0 {
+		return
+	}
+	fmt.Fprintf(d.page, "%s RG %s w 1 J 1 j ", pdfColor(color), num(width))
+	for i, p := range points {
+		op := "l"
+		if i == 0 {
+			op = "m"
+		}
+		fmt.Fprintf(d.page, "%s %s %s ", num(offsetX+p.X), num(pageHeight-offsetY-p.Y), op)
+	}
+	if len(points) == 1 {
+		fmt.Fprintf(d.page, "%s %s l ", num(offsetX+points[0].X+0.5), num(pageHeight-offsetY-points[0].Y))
+	}
+	d.page.WriteString("S 0 J 0 j\n")
+}
+
+// rect fills and/or strokes a rectangle; an empty color skips that part.
+func (d *pdfDocument) rect(x, y, w, h float64, fill, stroke string) {
+	fmt.Fprintf(d.page, "%s %s %s %s re ", num(x), num(pageHeight-y-h), num(w), num(h))
+	switch {
+	case fill != "" && stroke != "":
+		fmt.Fprintf(d.page, "%s rg %s RG 0.5 w B\n", pdfColor(fill), pdfColor(stroke))
+	case fill != "":
+		fmt.Fprintf(d.page, "%s rg f\n", pdfColor(fill))
+	default:
+		fmt.Fprintf(d.page, "%s RG 0.5 w S\n", pdfColor(stroke))
+	}
+}
+
+// encode returns s as a PDF string in the encoding of the font in use.
+func (d *pdfDocument) encode(s string) string {
+	if d.cjk != "" {
+		var b strings.Builder
+		b.WriteByte('<')
+		for _, r := range s {
+			if r > 0xFFFF || r == utf8.RuneError {
+				r = '?'
+			}
+			fmt.Fprintf(&b, "%04X", r)
+		}
+		b.WriteByte('>')
+		return b.String()
+	}
+	encoded, err := d.winAnsi.String(s)
+	if err != nil {
+		encoded = s
+	}
+	var b strings.Builder
+	b.WriteByte('(')
+	for i := 0; i < len(encoded); i++ {
+		switch c := encoded[i]; c {
+		case '(', ')', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			if c < 32 {
+				b.WriteByte(' ')
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// WriteTo writes the document as a PDF file with compressed page contents.
+func (d *pdfDocument) WriteTo(w io.Writer) (int64, error) {
+	var objects []string
+	add := func(object string) int {
+		objects = append(objects, object)
+		return len(objects)
+	}
+	catalog := add("") // Filled in once the page tree is known
+	pagesRef := add("")
+	var fonts string
+	if font, ok := cjkFonts[d.cjk]; ok {
+		descriptor := add(fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 6 /FontBBox [-25 -254 1000 880] /ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>", font.name))
+		cid := add(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (%s) /Supplement 2 >> /FontDescriptor %d 0 R /DW 1000 /W [1 95 500] >>", font.name, font.ordering, descriptor))
+		f1 := add(fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /%s /DescendantFonts [%d 0 R] >>", font.name, font.encoding, cid))
+		fonts = fmt.Sprintf("/F1 %d 0 R", f1)
+	} else {
+		f1 := add("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+		f2 := add("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+		fonts = fmt.Sprintf("/F1 %d 0 R /F2 %d 0 R", f1, f2)
+	}
+
+	var kids []string
+	for _, page := range d.pages {
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		zw.Write(page.Bytes())
+		zw.Close()
+		content := add(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", compressed.Len(), compressed.String()))
+		ref := add(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Font << %s >> >> /Contents %d 0 R >>", pagesRef, num(pageWidth), num(pageHeight), fonts, content))
+		kids = append(kids, fmt.Sprintf("%d 0 R", ref))
+	}
+	objects[catalog-1] = fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesRef)
+	objects[pagesRef-1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids))
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, catalog, xref)
+	n, err := w.Write(out.Bytes())
+	return int64(n), err
+}
+
+// pdfColor converts "#rrggbb" to PDF color components.
+func pdfColor(hex string) string {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) != 6 {
+		return "0 0 0"
+	}
+	components := make([]string, 3)
+	for i := range components {
+		v, _ := strconv.ParseUint(hex[2*i:2*i+2], 16, 8)
+		components[i] = num(float64(v) / 255)
+	}
+	return strings.Join(components, " ")
+}
+
+// num formats a number compactly for PDF operators.
+func num(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 32)
+}
@@ -0,0 +1,236 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+// Translator returns the text of a message ID in the reader's language.
+type Translator func(id string) string
+
+// slaColumns are the message IDs of the CSV columns, in order.
+var slaColumns = []string{
+	"reportDatabase", "reportGroup", "reportPeriodFrom", "reportPeriodTo",
+	"reportRpoSeconds", "reportRtoMinutes", "reportTargetPercent", "reportCoveragePercent",
+	"reportObservedSeconds", "reportMaintenanceSeconds",
+	"reportLagWithinRpoPercent", "reportRpoMet", "reportLongestExcursionStart", "reportLongestExcursionSeconds", "reportMaxLagSeconds",
+	"reportStandbyAvailabilityPercent", "reportServiceAvailabilityPercent",
+	"reportUnreachableCount", "reportUnreachableSeconds", "reportServiceOutageCount", "reportRtoBreaches", "reportRtoMet",
+	"reportRoleTransitions",
+}
+
+// WriteCSV writes one row per database with headers in the reader's language.
+// Durations are in seconds and times in RFC 3339, so that the file can be processed further.
+func WriteCSV(w io.Writer, r *SLAReport, t Translator) error {
+	out := csv.NewWriter(w)
+	header := make([]string, len(slaColumns))
+	for i, id := range slaColumns {
+		header[i] = t(id)
+	}
+	if err := out.Write(header); err != nil {
+		return err
+	}
+	for _, db := range r.Databases {
+		excursionStart, excursionSeconds := "", ""
+		if db.LongestExcursion != nil {
+			excursionStart = db.LongestExcursion.Start.Format(time.RFC3339)
+			excursionSeconds = formatSeconds(db.LongestExcursion.Seconds)
+		}
+		row := []string{
+			db.Database, db.Group, r.Period.From.Format(time.RFC3339), r.Period.To.Format(time.RFC3339),
+			strconv.Itoa(db.RPOSeconds), strconv.Itoa(db.RTOMinutes), formatFloat(db.SLATargetPercent), formatPercent(db.CoveragePercent),
+			formatSeconds(db.ObservedSeconds), formatSeconds(db.MaintenanceSeconds),
+			formatPercent(db.LagWithinRPOPercent), strconv.FormatBool(db.RPOMet), excursionStart, excursionSeconds, formatSeconds(db.MaxLagSeconds),
+			formatPercent(db.StandbyAvailabilityPercent), formatPercent(db.ServiceAvailabilityPercent),
+			strconv.Itoa(len(db.Unreachable)), formatSeconds(db.UnreachableSeconds), strconv.Itoa(len(db.ServiceOutages)), strconv.Itoa(db.RTOBreaches), strconv.FormatBool(db.RTOMet),
+			strconv.Itoa(len(db.RoleTransitions)),
+		}
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// WriteHTML writes a self-contained page meant to be printed or saved as PDF from the browser.
+func WriteHTML(w io.Writer, r *SLAReport, t Translator) error {
+	return templates.ExecuteTemplate(w, "sla", htmlData{Report: r, t: t})
+}
+
+// htmlData is what the templates render; T is a method so that they can translate.
+type htmlData struct {
+	Report interface{}
+	Lang   string
+	t      Translator
+}
+
+func (d htmlData) T(id string) string { return d.t(id) }
+
+// Warning returns the text of a report warning code.
+func (d htmlData) Warning(code string) string { return d.t("reportWarning_" + code) }
+
+// Member returns the display name of a DR member.
+func (d htmlData) Member(name string) string { return memberName(name, d.t) }
+
+// Health returns the display name of a health level or reason code.
+func (d htmlData) Health(code string) string { return d.t("health_" + code) }
+
+// Rows passes other data, e.g. the rows of a shared table, to a nested template.
+func (d htmlData) Rows(rows interface{}) htmlData {
+	return htmlData{Report: rows, Lang: d.Lang, t: d.t}
+}
+
+var templates = template.Must(template.New("report").Funcs(template.FuncMap{
+	"pct":      displayPercent,
+	"duration": formatDuration,
+	"time":     func(t time.Time) string { return t.Format("2006-01-02 15:04:05") },
+	"date":     func(t time.Time) string { return t.Format("2006-01-02 15:04") },
+	"lower":    strings.ToLower,
+}).Parse(sharedHTML + slaHTML + statusHTML))
+
+// sharedHTML holds the parts common to the report pages.
+const sharedHTML = `{{define "style"}}<style>
+body { font-family: -apple-system, "Segoe UI", "Noto Sans", sans-serif; font-size: 12px; color: #222; margin: 24px; }
+h1 { font-size: 20px; margin: 0 0 4px; }
+h2 { font-size: 15px; margin: 24px 0 6px; border-bottom: 1px solid #ccc; padding-bottom: 2px; }
+h3 { font-size: 12px; margin: 10px 0 4px; }
+.meta { color: #666; margin-bottom: 16px; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 8px; }
+th, td { border: 1px solid #ccc; padding: 3px 6px; text-align: left; vertical-align: top; }
+th { background: #f0f0f0; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+.met { color: #237804; font-weight: bold; }
+.missed { color: #cf1322; font-weight: bold; }
+.none { color: #888; }
+.warning { color: #d48806; margin-bottom: 8px; }
+.database { page-break-inside: avoid; }
+.level { font-weight: bold; }
+.level-ok { color: #237804; }
+.level-warning { color: #d48806; }
+.level-critical { color: #cf1322; }
+.level-unknown { color: #595959; }
+.level-maintenance { color: #9254de; }
+.legend { color: #666; font-size: 11px; margin-bottom: 6px; }
+.legend span { display: inline-block; width: 14px; height: 0; border-top: 2px solid; vertical-align: middle; margin: 0 4px 0 10px; }
+@media print { body { margin: 0; } h2 { page-break-after: avoid; } }
+</style>{{end}}
+{{define "slaTable"}}<table>
+<tr><th>{{.T "reportDatabase"}}</th><th>{{.T "reportRpoSeconds"}}</th><th>{{.T "reportLagWithinRpoPercent"}}</th><th>{{.T "reportLongestExcursion"}}</th><th>{{.T "reportStandbyAvailabilityPercent"}}</th><th>{{.T "reportServiceAvailabilityPercent"}}</th><th>{{.T "reportUnreachable"}}</th><th>{{.T "reportRtoBreaches"}}</th><th>{{.T "reportRoleTransitions"}}</th><th>{{.T "reportCoveragePercent"}}</th></tr>
+{{range .Report}}<tr>
+<td>{{.Database}}</td>
+<td class="num">{{.RPOSeconds}}</td>
+<td class="num {{if .RPOMet}}met{{else}}missed{{end}}">{{pct .LagWithinRPOPercent}}</td>
+<td class="num">{{if .LongestExcursion}}{{duration .LongestExcursion.Seconds}}{{else}}–{{end}}</td>
+<td class="num">{{pct .StandbyAvailabilityPercent}}</td>
+<td class="num">{{pct .ServiceAvailabilityPercent}}</td>
+<td class="num">{{len .Unreachable}} ({{duration .UnreachableSeconds}})</td>
+<td class="num {{if .RTOMet}}met{{else}}missed{{end}}">{{.RTOBreaches}}</td>
+<td class="num">{{len .RoleTransitions}}</td>
+<td class="num">{{pct .CoveragePercent}}</td>
+</tr>{{end}}
+</table>{{end}}
+`
+
+const slaHTML = `{{define "sla"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.T "reportSlaTitle"}} {{.Report.Period.Label}}</title>
+{{template "style"}}
+</head>
+<body>
+<h1>{{.T "reportSlaTitle"}}</h1>
+<div class="meta">{{.T "reportPeriod"}}: {{date .Report.Period.From}} – {{date .Report.Period.To}} · {{.T "reportGenerated"}}: {{time .Report.Generated}}</div>
+{{range .Report.Warnings}}<div class="warning">{{$.Warning .Code}}</div>
+{{end}}{{template "slaTable" (.Rows .Report.Databases)}}
+{{$d := .}}{{range .Report.Databases}}<div class="database">
+<h2>{{.Database}}{{if .Group}} · {{.Group}}{{end}}</h2>
+<table>
+<tr><th>{{$d.T "reportRpoSeconds"}}</th><td class="num">{{.RPOSeconds}}</td><th>{{$d.T "reportRtoMinutes"}}</th><td class="num">{{.RTOMinutes}}</td><th>{{$d.T "reportTargetPercent"}}</th><td class="num">{{.SLATargetPercent}} %</td></tr>
+<tr><th>{{$d.T "reportLagWithinRpoPercent"}}</th><td class="num {{if .RPOMet}}met{{else}}missed{{end}}">{{pct .LagWithinRPOPercent}}</td><th>{{$d.T "reportMaxLag"}}</th><td class="num">{{duration .MaxLagSeconds}}</td><th>{{$d.T "reportLongestExcursion"}}</th><td class="num">{{if .LongestExcursion}}{{duration .LongestExcursion.Seconds}} ({{date .LongestExcursion.Start}}){{else}}–{{end}}</td></tr>
+<tr><th>{{$d.T "reportStandbyAvailabilityPercent"}}</th><td class="num">{{pct .StandbyAvailabilityPercent}}</td><th>{{$d.T "reportServiceAvailabilityPercent"}}</th><td class="num">{{pct .ServiceAvailabilityPercent}}</td><th>{{$d.T "reportMaintenance"}}</th><td class="num">{{duration .MaintenanceSeconds}}</td></tr>
+</table>
+<h3>{{$d.T "reportUnreachablePeriods"}}</h3>
+{{if .Unreachable}}<table>
+<tr><th>{{$d.T "reportMember"}}</th><th>{{$d.T "reportStart"}}</th><th>{{$d.T "reportEnd"}}</th><th>{{$d.T "reportDuration"}}</th></tr>
+{{range .Unreachable}}<tr><td>{{$d.Member .Member}}</td><td>{{time .Start}}</td><td>{{time .End}}</td><td class="num">{{duration .Seconds}}</td></tr>
+{{end}}</table>{{else}}<div class="none">{{$d.T "reportNone"}}</div>{{end}}
+<h3>{{$d.T "reportServiceOutages"}}</h3>
+{{if .ServiceOutages}}<table>
+<tr><th>{{$d.T "reportStart"}}</th><th>{{$d.T "reportEnd"}}</th><th>{{$d.T "reportDuration"}}</th></tr>
+{{range .ServiceOutages}}<tr><td>{{time .Start}}</td><td>{{time .End}}</td><td class="num">{{duration .Seconds}}</td></tr>
+{{end}}</table>{{else}}<div class="none">{{$d.T "reportNone"}}</div>{{end}}
+<h3>{{$d.T "reportRoleTransitions"}}</h3>
+{{if .RoleTransitions}}<table>
+<tr><th>{{$d.T "reportTime"}}</th><th>{{$d.T "reportFrom"}}</th><th>{{$d.T "reportTo"}}</th></tr>
+{{range .RoleTransitions}}<tr><td>{{time .Time}}</td><td>{{$d.Member .From}}</td><td>{{$d.Member .To}}</td></tr>
+{{end}}</table>{{else}}<div class="none">{{$d.T "reportNone"}}</div>{{end}}
+</div>
+{{end}}</body>
+</html>{{end}}
+`
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// formatSeconds returns a duration in whole seconds; samples are not taken more precisely.
+func formatSeconds(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', 0, 64)
+}
+
+// formatPercent returns a percentage with three decimals, enough to tell 99.9 from 99.95; empty when unknown.
+func formatPercent(p *float64) string {
+	if p == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*p, 'f', 3, 64)
+}
+
+// memberName returns the display name of a DR member or the load balancer.
+func memberName(name string, t Translator) string {
+	switch name {
+	case models.MemberProduction:
+		return t("targetProd")
+	case models.MemberDisaster:
+		return t("targetDR")
+	case models.MemberLoadBalancer:
+		return t("loadBalancer")
+	}
+	return name
+}
+
+// displayPercent returns a percentage for the HTML page, or a dash when unknown.
+func displayPercent(p *float64) string {
+	if p == nil {
+		return "–"
+	}
+	return formatPercent(p) + " %"
+}
+
+// formatDuration returns seconds as e.g. "45s", "12m 5s", "5m" or "3h 20m".
+func formatDuration(seconds float64) string {
+	d := time.Duration(seconds) * time.Second
+	switch {
+	case d < 0:
+		return "–"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour && d%time.Minute == 0:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm %ds", int(d.Minutes()), int(d.Seconds())%60)
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
@@ -0,0 +1,115 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/history"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/notify"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/util"
+)
+
+// StatusSource returns the latest statuses of the databases that pass a
+// filter and when they were checked; ok is false before the first check.
+type StatusSource func(filter models.DatabaseFilter) (statuses []models.DatabaseStatus, collectedAt time.Time, ok bool)
+
+// StartScheduler generates the reports configured under reports.schedules
+// when they are due. The schedules are re-read every minute, so that
+// configuration reloads apply. translator returns the translator of a language.
+func StartScheduler(store *history.Store, latest StatusSource, translator func(lang string) Translator) {
+	go func() {
+		last := time.Now()
+		for {
+			time.Sleep(time.Until(last.Truncate(time.Minute).Add(time.Minute)))
+			now := time.Now()
+			cfg := models.GetConfig()
+			for _, schedule := range cfg.Reports.Schedules {
+				if due := schedule.Next(last); due.IsZero() || due.After(now) {
+					continue
+				}
+				files, err := Generate(store, cfg, schedule, latest, translator(schedule.Language), now)
+				if err != nil {
+					util.Logger.Printf("Failed to generate report '%s': %v", schedule.Name, err)
+					continue
+				}
+				util.Logger.Printf("Report '%s' written to %s", schedule.Name, strings.Join(files, ", "))
+			}
+			last = now
+		}
+	}()
+}
+
+// Generate renders the status report of a schedule in its formats, writes
+// the files to the archive directory and emails them to the schedule's
+// recipients. It returns the paths of the files written.
+func Generate(store *history.Store, cfg models.Config, schedule models.ReportSchedule, latest StatusSource, t Translator, now time.Time) ([]string, error) {
+	period, err := ParsePeriod(schedule.Period, now)
+	if err != nil {
+		return nil, err
+	}
+	filter := schedule.Filter()
+	statuses, collectedAt, ok := latest(filter)
+	if !ok {
+		return nil, errors.New("no check cycle has completed yet")
+	}
+	r, err := BuildStatus(store, cfg, filter, statuses, collectedAt, period, now)
+	if err != nil {
+		return nil, err
+	}
+	r.Name = schedule.Name
+
+	if err := os.MkdirAll(cfg.Reports.ArchiveDir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create report archive: %w", err)
+	}
+	base := fmt.Sprintf("%s-%s", schedule.Name, now.Format("20060102-1504"))
+	var files []string
+	var attachments []notify.Attachment
+	for _, format := range schedule.Formats {
+		var buf bytes.Buffer
+		contentType := "text/html; charset=utf-8"
+		if format == models.ReportFormatPDF {
+			contentType = "application/pdf"
+			err = WriteStatusPDF(&buf, r, t, schedule.Language)
+		} else {
+			err = WriteStatusHTML(&buf, r, t, schedule.Language)
+		}
+		if err != nil {
+			return files, fmt.Errorf("failed to render %s: %w", format, err)
+		}
+		path := filepath.Join(cfg.Reports.ArchiveDir, base+"."+format)
+		if err := os.WriteFile(path, buf.Bytes(), 0o640); err != nil {
+			return files, fmt.Errorf("failed to write report: %w", err)
+		}
+		files = append(files, path)
+		attachments = append(attachments, notify.Attachment{Name: filepath.Base(path), ContentType: contentType, Data: buf.Bytes()})
+	}
+
+	if len(schedule.Email) > 0 {
+		msg := notify.Message{
+			To:          schedule.Email,
+			Subject:     fmt.Sprintf("%s %s (%s)", t("reportStatusTitle"), schedule.Name, now.Format("2006-01-02")),
+			Text:        summaryText(r, t),
+			Attachments: attachments,
+		}
+		if err := notify.SendEmail(cfg.Notifier.Email, msg); err != nil {
+			return files, fmt.Errorf("report archived but not emailed: %w", err)
+		}
+	}
+	return files, nil
+}
+
+// summaryText is the body of the report email: the health and RPO compliance of every database.
+func summaryText(r *StatusReport, t Translator) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s – %s\n\n", t("reportPeriod"), r.Period.From.Format("2006-01-02 15:04"), r.Period.To.Format("2006-01-02 15:04"))
+	for _, db := range r.Databases {
+		fmt.Fprintf(&b, "%s: %s, %s %s\n", db.Status.Name, t("health_"+db.Status.Health.Level), t("reportLagWithinRpoPercent"), displayPercent(db.SLA.LagWithinRPOPercent))
+	}
+	return b.String()
+}
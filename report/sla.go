@@ -0,0 +1,397 @@
+// Package report computes disaster recovery SLA figures from the recorded
+// check history and renders them as CSV or printable HTML.
+package report
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/history"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+// maxGapIntervals is how many refresh intervals may pass between two samples
+// before the time in between counts as not observed, e.g. while the dashboard was down.
+const maxGapIntervals = 3
+
+// Period is the time range a report covers, [From, To).
+type Period struct {
+	Label string    `json:"label"`
+	From  time.Time `json:"from"`
+	To    time.Time `json:"to"`
+}
+
+// ParsePeriod interprets a period such as "2026-09" (a calendar month),
+// "last-month", "this-month" or "30d" (the last 30 days). Callers supply
+// their own default, so an empty period is an error.
+func ParsePeriod(value string, now time.Time) (Period, error) {
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	switch value = strings.TrimSpace(strings.ToLower(value)); {
+	case value == "":
+		return Period{}, fmt.Errorf("no period given (expected YYYY-MM, last-month, this-month or <days>d)")
+	case value == "last-month":
+		from := monthStart.AddDate(0, -1, 0)
+		return Period{Label: from.Format("2006-01"), From: from, To: monthStart}, nil
+	case value == "this-month":
+		return Period{Label: monthStart.Format("2006-01"), From: monthStart, To: monthStart.AddDate(0, 1, 0)}, nil
+	case strings.HasSuffix(value, "d"):
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil || days <= 0 {
+			return Period{}, fmt.Errorf("invalid period '%s'", value)
+		}
+		return Period{Label: value, From: now.AddDate(0, 0, -days), To: now}, nil
+	}
+	month, err := time.ParseInLocation("2006-01", value, now.Location())
+	if err != nil {
+		return Period{}, fmt.Errorf("invalid period '%s' (expected YYYY-MM, last-month, this-month or <days>d)", value)
+	}
+	return Period{Label: value, From: month, To: month.AddDate(0, 1, 0)}, nil
+}
+
+// Warning codes of SLAReport.Warnings.
+const (
+	WarningHistoryInMemory = "HISTORY_IN_MEMORY" // history.dir is empty
+	WarningBeforeRetention = "BEFORE_RETENTION"  // The period starts before the oldest retained day
+)
+
+// SLAReport holds the SLA figures of every database for one period.
+type SLAReport struct {
+	Period    Period        `json:"period"`
+	Generated time.Time     `json:"generated"`
+	Databases []DatabaseSLA `json:"databases"`
+	Warnings  []Warning     `json:"warnings"` // Why the figures may not cover the whole period
+}
+
+// Warning explains why a report cannot be complete.
+type Warning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// coverageWarnings reports the part of the period the store cannot hold samples for.
+func coverageWarnings(store *history.Store, period Period, now time.Time) []Warning {
+	horizon := store.Horizon(now)
+	if horizon.IsZero() || !period.From.Before(horizon) {
+		return []Warning{}
+	}
+	if !store.Persistent() {
+		return []Warning{{Code: WarningHistoryInMemory, Message: fmt.Sprintf(
+			"history.dir is not set, so history is only kept in memory since %s; the period before is not covered", horizon.Format(time.RFC3339))}}
+	}
+	return []Warning{{Code: WarningBeforeRetention, Message: fmt.Sprintf(
+		"the period starts before the history retention; samples before %s have been deleted", horizon.Format(time.RFC3339))}}
+}
+
+// DatabaseSLA holds the SLA figures of one database. Maintenance windows are
+// left out of every figure; percentages are nil when nothing was observed.
+type DatabaseSLA struct {
+	Database         string  `json:"database"`
+	Group            string  `json:"group,omitempty"`
+	RPOSeconds       int     `json:"rpo_seconds"`
+	RTOMinutes       int     `json:"rto_minutes"`
+	SLATargetPercent float64 `json:"sla_target_percent"`
+
+	ObservedSeconds    float64  `json:"observed_seconds"`    // Covered by samples, outside maintenance
+	MaintenanceSeconds float64  `json:"maintenance_seconds"` // Excluded from the figures below
+	CoveragePercent    *float64 `json:"coverage_percent"`    // Observed and maintenance time relative to the period
+
+	LagWithinRPOPercent *float64   `json:"lag_within_rpo_percent"` // Unknown lag counts as outside the RPO
+	LongestExcursion    *Excursion `json:"longest_excursion"`      // Longest stretch with the lag beyond the RPO
+	MaxLagSeconds       float64    `json:"max_lag_seconds"`
+
+	StandbyAvailabilityPercent *float64 `json:"standby_availability_percent"`
+	ServiceAvailabilityPercent *float64 `json:"service_availability_percent"` // Time a member answered as primary
+
+	Unreachable        []Outage `json:"unreachable"` // Periods in which a member could not be queried
+	UnreachableSeconds float64  `json:"unreachable_seconds"`
+	ServiceOutages     []Outage `json:"service_outages"` // Periods without a reachable primary
+	RTOBreaches        int      `json:"rto_breaches"`    // Service outages longer than the RTO
+
+	RoleTransitions []RoleTransition `json:"role_transitions"`
+
+	RPOMet bool `json:"rpo_met"`
+	RTOMet bool `json:"rto_met"`
+}
+
+// Excursion is a stretch of time in which the standby lag exceeded the RPO or was unknown.
+type Excursion struct {
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+	Seconds       float64   `json:"seconds"`
+	MaxLagSeconds float64   `json:"max_lag_seconds"` // Largest known lag; -1 when the lag was never known
+}
+
+// Outage is a stretch of time in which a member, or the service, was unavailable.
+type Outage struct {
+	Member  string    `json:"member,omitempty"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Seconds float64   `json:"seconds"`
+}
+
+// RoleTransition is a change of the member acting as primary.
+type RoleTransition struct {
+	Time time.Time `json:"time"`
+	From string    `json:"from"`
+	To   string    `json:"to"`
+}
+
+// ComputeSLA reads the samples of the period from the store and computes the
+// figures of the configured databases that pass the filter.
+func ComputeSLA(store *history.Store, cfg models.Config, filter models.DatabaseFilter, period Period, now time.Time) (*SLAReport, error) {
+	interval := time.Duration(cfg.Server.RefreshInterval) * time.Second
+	report := &SLAReport{Period: period, Generated: now, Databases: []DatabaseSLA{}, Warnings: coverageWarnings(store, period, now)}
+	accumulators := make(map[string]*slaAccumulator)
+	var order []*slaAccumulator
+	for _, db := range cfg.DBs {
+		if !filter.MatchesDatabase(db) {
+			continue
+		}
+		acc := newAccumulator(db, interval)
+		accumulators[db.Name] = acc
+		order = append(order, acc)
+	}
+
+	err := store.Samples(period.From, period.To, func(sample history.Sample) error {
+		if acc := accumulators[sample.Database]; acc != nil {
+			acc.add(sample)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	end := period.To
+	if now.Before(end) {
+		end = now
+	}
+	for _, acc := range order {
+		report.Databases = append(report.Databases, acc.finish(period.From, end))
+	}
+	return report, nil
+}
+
+// slaAccumulator consumes the samples of one database in time order. Each
+// sample stands for the time until the next one, up to maxGapIntervals
+// refresh intervals.
+type slaAccumulator struct {
+	result   DatabaseSLA
+	interval time.Duration
+	prev     *history.Sample
+
+	withinRPO, standbyUp, standbyObserved, serviceUp, serviceObserved float64
+
+	excursion     *Excursion
+	unreachable   map[string]*Outage
+	serviceOutage *Outage
+	primary       string
+}
+
+func newAccumulator(db models.DatabaseConfig, interval time.Duration) *slaAccumulator {
+	return &slaAccumulator{
+		result: DatabaseSLA{
+			Database:         db.Name,
+			Group:            db.Group,
+			RPOSeconds:       db.RPOSeconds,
+			RTOMinutes:       db.RTOMinutes,
+			SLATargetPercent: db.SLATargetPercent,
+			Unreachable:      []Outage{},
+			ServiceOutages:   []Outage{},
+			RoleTransitions:  []RoleTransition{},
+		},
+		interval:    interval,
+		unreachable: make(map[string]*Outage),
+	}
+}
+
+// add accounts for the previous sample up to this one and keeps this one.
+func (a *slaAccumulator) add(sample history.Sample) {
+	if a.prev != nil {
+		a.account(*a.prev, sample.Time)
+	}
+	a.prev = &sample
+}
+
+// account adds the figures of a sample lasting until next, or for at most the
+// gap limit. Stretches are closed at a gap, so that they never span unobserved time.
+func (a *slaAccumulator) account(s history.Sample, next time.Time) {
+	end := next
+	if limit := s.Time.Add(maxGapIntervals * a.interval); end.After(limit) {
+		end = limit
+		defer a.closeAll()
+	}
+	seconds := end.Sub(s.Time).Seconds()
+	if seconds <= 0 {
+		return
+	}
+	if s.Maintenance {
+		a.result.MaintenanceSeconds += seconds
+		a.closeAll()
+		return
+	}
+	a.result.ObservedSeconds += seconds
+
+	standby, primaryName := standbyOf(s)
+
+	// Lag against the RPO and standby availability, unless the standby is under maintenance.
+	if standby.Maintenance {
+		a.excursion = a.closeExcursion(a.excursion)
+	} else {
+		a.standbyObserved += seconds
+		if standby.Connected {
+			a.standbyUp += seconds
+			if standby.Lag > a.result.MaxLagSeconds {
+				a.result.MaxLagSeconds = standby.Lag
+			}
+		}
+		if standby.Connected && standby.Lag >= 0 && standby.Lag <= float64(a.result.RPOSeconds) {
+			a.withinRPO += seconds
+			a.excursion = a.closeExcursion(a.excursion)
+		} else {
+			lag := -1.0
+			if standby.Connected {
+				lag = standby.Lag
+			}
+			if a.excursion == nil {
+				a.excursion = &Excursion{Start: s.Time, MaxLagSeconds: -1}
+			}
+			a.excursion.End = end
+			if lag > a.excursion.MaxLagSeconds {
+				a.excursion.MaxLagSeconds = lag
+			}
+		}
+	}
+
+	// Unreachable members.
+	for _, m := range []struct {
+		name   string
+		sample history.MemberSample
+	}{{models.MemberProduction, s.Production}, {models.MemberDisaster, s.Disaster}} {
+		if m.sample.Maintenance || m.sample.Connected {
+			a.closeUnreachable(m.name)
+			continue
+		}
+		outage := a.unreachable[m.name]
+		if outage == nil {
+			outage = &Outage{Member: m.name, Start: s.Time}
+			a.unreachable[m.name] = outage
+		}
+		outage.End = end
+	}
+
+	// Service availability: some member answers as primary. Judged only when
+	// neither member is under maintenance, as a switchover may be in progress.
+	if s.Production.Maintenance || s.Disaster.Maintenance {
+		a.closeServiceOutage()
+	} else {
+		a.serviceObserved += seconds
+		if primaryName != "" {
+			a.serviceUp += seconds
+			a.closeServiceOutage()
+		} else {
+			if a.serviceOutage == nil {
+				a.serviceOutage = &Outage{Start: s.Time}
+			}
+			a.serviceOutage.End = end
+		}
+	}
+
+	if primaryName != "" {
+		if a.primary != "" && a.primary != primaryName {
+			a.result.RoleTransitions = append(a.result.RoleTransitions, RoleTransition{Time: s.Time, From: a.primary, To: primaryName})
+		}
+		a.primary = primaryName
+	}
+}
+
+// standbyOf returns the standby member of a sample and the name of the member
+// answering as primary, if any. The standby is whichever member is not the
+// primary: the disaster member unless the roles were switched.
+func standbyOf(s history.Sample) (standby history.MemberSample, primary string) {
+	switch {
+	case s.Production.Role == "PRIMARY" && s.Production.Connected:
+		return s.Disaster, models.MemberProduction
+	case s.Disaster.Role == "PRIMARY" && s.Disaster.Connected:
+		return s.Production, models.MemberDisaster
+	}
+	return s.Disaster, ""
+}
+
+// closeExcursion ends a stretch of lag beyond the RPO, keeping it if it is the longest so far.
+func (a *slaAccumulator) closeExcursion(e *Excursion) *Excursion {
+	if e == nil {
+		return nil
+	}
+	e.Seconds = e.End.Sub(e.Start).Seconds()
+	if a.result.LongestExcursion == nil || e.Seconds > a.result.LongestExcursion.Seconds {
+		a.result.LongestExcursion = e
+	}
+	return nil
+}
+
+func (a *slaAccumulator) closeUnreachable(member string) {
+	outage := a.unreachable[member]
+	if outage == nil {
+		return
+	}
+	outage.Seconds = outage.End.Sub(outage.Start).Seconds()
+	a.result.Unreachable = append(a.result.Unreachable, *outage)
+	a.result.UnreachableSeconds += outage.Seconds
+	delete(a.unreachable, member)
+}
+
+func (a *slaAccumulator) closeServiceOutage() {
+	if a.serviceOutage == nil {
+		return
+	}
+	outage := a.serviceOutage
+	outage.Seconds = outage.End.Sub(outage.Start).Seconds()
+	a.result.ServiceOutages = append(a.result.ServiceOutages, *outage)
+	if outage.Seconds > float64(a.result.RTOMinutes*60) {
+		a.result.RTOBreaches++
+	}
+	a.serviceOutage = nil
+}
+
+func (a *slaAccumulator) closeAll() {
+	a.excursion = a.closeExcursion(a.excursion)
+	a.closeUnreachable(models.MemberProduction)
+	a.closeUnreachable(models.MemberDisaster)
+	a.closeServiceOutage()
+}
+
+// finish accounts for the last sample and computes the percentages for the
+// period from start to end.
+func (a *slaAccumulator) finish(start, end time.Time) DatabaseSLA {
+	if a.prev != nil {
+		next := a.prev.Time.Add(a.interval)
+		if next.After(end) {
+			next = end
+		}
+		a.account(*a.prev, next)
+	}
+	a.closeAll()
+
+	r := a.result
+	if total := end.Sub(start).Seconds(); total > 0 {
+		r.CoveragePercent = percent(r.ObservedSeconds+r.MaintenanceSeconds, total)
+	}
+	r.LagWithinRPOPercent = percent(a.withinRPO, a.standbyObserved)
+	r.StandbyAvailabilityPercent = percent(a.standbyUp, a.standbyObserved)
+	r.ServiceAvailabilityPercent = percent(a.serviceUp, a.serviceObserved)
+	r.RPOMet = r.LagWithinRPOPercent != nil && *r.LagWithinRPOPercent >= r.SLATargetPercent
+	r.RTOMet = r.RTOBreaches == 0
+	return r
+}
+
+// percent returns part as a percentage of whole, or nil when whole is zero.
+func percent(part, whole float64) *float64 {
+	if whole <= 0 {
+		return nil
+	}
+	p := part / whole * 100
+	return &p
+}
@@ -0,0 +1,294 @@
+package report
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/history"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+// t0 is the start of every synthetic history.
+var t0 = time.Date(2026, time.March, 2, 8, 0, 0, 0, time.UTC)
+
+func primary() history.MemberSample {
+	return history.MemberSample{Role: "PRIMARY", Status: "READ WRITE", Connected: true, Lag: -1}
+}
+
+func standby(lag float64) history.MemberSample {
+	return history.MemberSample{Role: "PHYSICAL STANDBY", Status: "MOUNTED", Connected: true, Lag: lag}
+}
+
+func down() history.MemberSample {
+	return history.MemberSample{Connected: false, Lag: -1}
+}
+
+func underMaintenance(m history.MemberSample) history.MemberSample {
+	m.Maintenance = true
+	return m
+}
+
+// minute returns a sample taken the given number of minutes after t0.
+func minute(m int, production, disaster history.MemberSample) history.Sample {
+	return history.Sample{Time: t0.Add(time.Duration(m) * time.Minute), Database: "ERP_DB", Production: production, Disaster: disaster}
+}
+
+// steady returns samples with the production primary and the disaster standby at lag, one per minute from first to last.
+func steady(first, last int, lag float64) []history.Sample {
+	var samples []history.Sample
+	for m := first; m <= last; m++ {
+		samples = append(samples, minute(m, primary(), standby(lag)))
+	}
+	return samples
+}
+
+func concat(lists ...[]history.Sample) []history.Sample {
+	var samples []history.Sample
+	for _, list := range lists {
+		samples = append(samples, list...)
+	}
+	return samples
+}
+
+func TestSLAAccumulator(t *testing.T) {
+	wholeMaintenance := minute(2, underMaintenance(primary()), underMaintenance(standby(400)))
+	wholeMaintenance.Maintenance = true
+
+	tests := []struct {
+		name    string
+		samples []history.Sample
+		minutes int // Length of the period from t0
+
+		observed, maintenance float64
+		coverage              float64 // Percentages; -1 when nil
+		withinRPO             float64
+		standby, service      float64
+		excursion             float64   // Longest excursion in seconds; 0 when none
+		maxLag                float64   // Of the longest excursion
+		unreachable           []string  // "member:seconds"
+		outages               []float64 // Service outages in seconds
+		breaches              int
+		transitions           []string // "from>to"
+	}{
+		{
+			name:    "steady within the RPO",
+			samples: steady(0, 9, 10), minutes: 10,
+			observed: 600, coverage: 100, withinRPO: 100, standby: 100, service: 100,
+		},
+		{
+			name:     "no samples",
+			minutes:  10,
+			coverage: 0, withinRPO: -1, standby: -1, service: -1,
+		},
+		{
+			// A sample counts for at most three intervals; the rest of the gap is not observed.
+			name:    "gap limit",
+			samples: concat(steady(0, 1, 10), steady(10, 11, 10)), minutes: 12,
+			observed: 360, coverage: 50, withinRPO: 100, standby: 100, service: 100,
+		},
+		{
+			name:    "excursion closes when the lag recovers",
+			samples: concat(steady(0, 1, 10), steady(2, 4, 400), steady(5, 5, 10)), minutes: 6,
+			observed: 360, coverage: 100, withinRPO: 50, standby: 100, service: 100,
+			excursion: 180, maxLag: 400,
+		},
+		{
+			name:    "excursion closes at a gap",
+			samples: concat(steady(0, 1, 400), steady(10, 11, 400)), minutes: 12,
+			observed: 360, coverage: 50, withinRPO: 0, standby: 100, service: 100,
+			excursion: 240, maxLag: 400,
+		},
+		{
+			name:    "unknown lag is an excursion",
+			samples: concat(steady(0, 0, 10), []history.Sample{minute(1, primary(), down()), minute(2, primary(), down())}, steady(3, 3, 10)), minutes: 4,
+			observed: 240, coverage: 100, withinRPO: 50, standby: 50, service: 100,
+			excursion: 120, maxLag: -1, unreachable: []string{"disaster:120"},
+		},
+		{
+			name:    "database maintenance is excluded",
+			samples: concat(steady(0, 1, 400), []history.Sample{wholeMaintenance}, steady(3, 3, 400)), minutes: 4,
+			observed: 180, maintenance: 60, coverage: 100, withinRPO: 0, standby: 100, service: 100,
+			excursion: 120, maxLag: 400,
+		},
+		{
+			name: "standby maintenance is left out of the lag figures",
+			samples: concat(steady(0, 1, 10), []history.Sample{minute(2, primary(), underMaintenance(down())), minute(3, primary(), underMaintenance(down()))},
+				steady(4, 5, 10)), minutes: 6,
+			observed: 360, coverage: 100, withinRPO: 100, standby: 100, service: 100,
+		},
+		{
+			// Without a reachable primary for three minutes, longer than the RTO of two.
+			name:    "service outage",
+			samples: concat(steady(0, 1, 10), []history.Sample{minute(2, down(), standby(10)), minute(3, down(), standby(10)), minute(4, down(), standby(10))}, steady(5, 5, 10)), minutes: 6,
+			observed: 360, coverage: 100, withinRPO: 100, standby: 100, service: 50,
+			unreachable: []string{"production:180"}, outages: []float64{180}, breaches: 1,
+		},
+		{
+			name:    "short service outage within the RTO",
+			samples: concat(steady(0, 1, 10), []history.Sample{minute(2, down(), standby(10))}, steady(3, 3, 10)), minutes: 4,
+			observed: 240, coverage: 100, withinRPO: 100, standby: 100, service: 75,
+			unreachable: []string{"production:60"}, outages: []float64{60},
+		},
+		{
+			// After the switchover the production member is the standby whose lag counts.
+			name: "role transition",
+			samples: concat(steady(0, 1, 10), []history.Sample{minute(2, standby(400), primary()), minute(3, standby(5), primary()),
+				minute(4, primary(), standby(5))}), minutes: 5,
+			observed: 300, coverage: 100, withinRPO: 80, standby: 100, service: 100,
+			excursion: 60, maxLag: 400, transitions: []string{"production>disaster", "disaster>production"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acc := newAccumulator(models.DatabaseConfig{Name: "ERP_DB", RPOSeconds: 300, RTOMinutes: 2, SLATargetPercent: 99}, time.Minute)
+			for _, sample := range tt.samples {
+				acc.add(sample)
+			}
+			r := acc.finish(t0, t0.Add(time.Duration(tt.minutes)*time.Minute))
+
+			if r.ObservedSeconds != tt.observed || r.MaintenanceSeconds != tt.maintenance {
+				t.Errorf("got %gs observed and %gs maintenance, want %gs and %gs", r.ObservedSeconds, r.MaintenanceSeconds, tt.observed, tt.maintenance)
+			}
+			for _, p := range []struct {
+				name string
+				got  *float64
+				want float64
+			}{
+				{"coverage", r.CoveragePercent, tt.coverage},
+				{"lag within RPO", r.LagWithinRPOPercent, tt.withinRPO},
+				{"standby availability", r.StandbyAvailabilityPercent, tt.standby},
+				{"service availability", r.ServiceAvailabilityPercent, tt.service},
+			} {
+				if (p.got == nil) != (p.want < 0) || (p.got != nil && math.Abs(*p.got-p.want) > 1e-9) {
+					t.Errorf("%s: got %s, want %g", p.name, formatPercent(p.got), p.want)
+				}
+			}
+			if r.RPOMet != (tt.withinRPO >= 99) {
+				t.Errorf("got RPO met %v with %g%% within the RPO", r.RPOMet, tt.withinRPO)
+			}
+
+			switch {
+			case tt.excursion == 0 && r.LongestExcursion != nil:
+				t.Errorf("got excursion %+v, want none", *r.LongestExcursion)
+			case tt.excursion != 0 && r.LongestExcursion == nil:
+				t.Errorf("got no excursion, want %gs", tt.excursion)
+			case tt.excursion != 0 && (r.LongestExcursion.Seconds != tt.excursion || r.LongestExcursion.MaxLagSeconds != tt.maxLag):
+				t.Errorf("got excursion of %gs with lag %g, want %gs with lag %g", r.LongestExcursion.Seconds, r.LongestExcursion.MaxLagSeconds, tt.excursion, tt.maxLag)
+			}
+
+			var unreachable []string
+			for _, outage := range r.Unreachable {
+				unreachable = append(unreachable, outage.Member+":"+formatSeconds(outage.Seconds))
+			}
+			if strings.Join(unreachable, ",") != strings.Join(tt.unreachable, ",") {
+				t.Errorf("got unreachable %v, want %v", unreachable, tt.unreachable)
+			}
+			var outages []float64
+			for _, outage := range r.ServiceOutages {
+				outages = append(outages, outage.Seconds)
+			}
+			if len(outages) != len(tt.outages) || (len(outages) > 0 && outages[0] != tt.outages[0]) {
+				t.Errorf("got service outages %v, want %v", outages, tt.outages)
+			}
+			if r.RTOBreaches != tt.breaches || r.RTOMet != (tt.breaches == 0) {
+				t.Errorf("got %d RTO breaches (met %v), want %d", r.RTOBreaches, r.RTOMet, tt.breaches)
+			}
+
+			var transitions []string
+			for _, transition := range r.RoleTransitions {
+				transitions = append(transitions, transition.From+">"+transition.To)
+			}
+			if strings.Join(transitions, ",") != strings.Join(tt.transitions, ",") {
+				t.Errorf("got role transitions %v, want %v", transitions, tt.transitions)
+			}
+		})
+	}
+}
+
+func TestParsePeriod(t *testing.T) {
+	now := time.Date(2026, time.March, 15, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		value    string
+		label    string
+		from, to time.Time
+		wantErr  string
+	}{
+		{value: "last-month", label: "2026-02", from: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), to: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{value: " This-Month ", label: "2026-03", from: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), to: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{value: "2025-12", label: "2025-12", from: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), to: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{value: "30d", label: "30d", from: now.AddDate(0, 0, -30), to: now},
+		{value: "", wantErr: "no period given"},
+		{value: "0d", wantErr: "invalid period"},
+		{value: "xd", wantErr: "invalid period"},
+		{value: "2026-13", wantErr: "invalid period"},
+		{value: "yesterday", wantErr: "invalid period"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			period, err := ParsePeriod(tt.value, now)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got %+v, %v; want an error containing %q", period, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if period.Label != tt.label || !period.From.Equal(tt.from) || !period.To.Equal(tt.to) {
+				t.Errorf("got %+v, want %s from %s to %s", period, tt.label, tt.from, tt.to)
+			}
+		})
+	}
+}
+
+func TestComputeSLAWarnsAboutUncoveredPeriods(t *testing.T) {
+	now := time.Now()
+	cfg := models.Config{DBs: []models.DatabaseConfig{{Name: "ERP_DB", RPOSeconds: 300, RTOMinutes: 60, SLATargetPercent: 99.9}}}
+	cfg.Server.RefreshInterval = 30
+	lastMonth, err := ParsePeriod("last-month", now)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	lastDay := Period{Label: "1d", From: now.Add(-12 * time.Hour), To: now}
+
+	memory, err := history.Open("", 90)
+	if err != nil {
+		t.Fatalf("open memory history: %v", err)
+	}
+	persisted, err := history.Open(t.TempDir(), 7)
+	if err != nil {
+		t.Fatalf("open history: %v", err)
+	}
+	defer persisted.Close()
+
+	tests := []struct {
+		name   string
+		store  *history.Store
+		period Period
+		want   string
+	}{
+		{name: "memory, last month", store: memory, period: lastMonth, want: WarningHistoryInMemory},
+		{name: "memory, last hours", store: memory, period: lastDay},
+		{name: "beyond the retention", store: persisted, period: lastMonth, want: WarningBeforeRetention},
+		{name: "within the retention", store: persisted, period: lastDay},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := ComputeSLA(tt.store, cfg, models.DatabaseFilter{}, tt.period, now)
+			if err != nil {
+				t.Fatalf("compute: %v", err)
+			}
+			var codes []string
+			for _, warning := range report.Warnings {
+				codes = append(codes, warning.Code)
+			}
+			if strings.Join(codes, ",") != tt.want {
+				t.Errorf("got warnings %v, want %q", codes, tt.want)
+			}
+		})
+	}
+}
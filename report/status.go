@@ -0,0 +1,198 @@
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/history"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+// maxLagPoints bounds the points of a lag chart. Longer periods are reduced to
+// the largest lag per interval, so that short spikes stay visible.
+const maxLagPoints = 336
+
+// StatusReport is a dated record of the DR state: the latest check of every
+// database, what happened during the period and the SLA figures of the period.
+type StatusReport struct {
+	Name        string           `json:"name,omitempty"` // Schedule the report was generated for
+	Period      Period           `json:"period"`
+	Generated   time.Time        `json:"generated"`
+	CollectedAt time.Time        `json:"collected_at"` // When the reported statuses were checked
+	LagInterval time.Duration    `json:"-"`            // Time covered by one point of the lag charts
+	Databases   []DatabaseReport `json:"databases"`
+	Events      []history.Event  `json:"events"` // Recorded events of the reported databases, oldest first
+	Warnings    []Warning        `json:"warnings"`
+}
+
+// DatabaseReport is the part of a status report about one database.
+type DatabaseReport struct {
+	Status models.DatabaseStatus `json:"status"`
+	SLA    DatabaseSLA           `json:"sla"`
+	Lag    []LagPoint            `json:"lag"`
+	Alerts []Alert               `json:"alerts"`
+}
+
+// LagPoint is the largest standby lag seen in one interval of a lag chart.
+type LagPoint struct {
+	Time time.Time `json:"time"`
+	Lag  float64   `json:"lag"` // Seconds; -1 when the standby could not be queried
+}
+
+// Alert is a stretch of time in which the health of a database was worse than OK.
+type Alert struct {
+	Level   string    `json:"level"` // Worst level reached
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Reasons []string  `json:"reasons"` // Reason codes reported during the alert, in order of appearance
+	Ongoing bool      `json:"ongoing"` // Still firing at the end of the period
+}
+
+// BuildStatus assembles a status report from the latest statuses of the
+// databases that pass the filter and the history of the period.
+func BuildStatus(store *history.Store, cfg models.Config, filter models.DatabaseFilter, statuses []models.DatabaseStatus, collectedAt time.Time, period Period, now time.Time) (*StatusReport, error) {
+	sla, err := ComputeSLA(store, cfg, filter, period, now)
+	if err != nil {
+		return nil, err
+	}
+	interval := time.Duration(cfg.Server.RefreshInterval) * time.Second
+	lagInterval := period.To.Sub(period.From) / maxLagPoints
+	if lagInterval < interval {
+		lagInterval = interval
+	}
+	r := &StatusReport{
+		Period:      period,
+		Generated:   now,
+		CollectedAt: collectedAt,
+		Warnings:    sla.Warnings,
+		LagInterval: lagInterval,
+		Databases:   []DatabaseReport{},
+		Events:      []history.Event{},
+	}
+
+	collectors := make(map[string]*statusCollector)
+	byName := make(map[string]models.DatabaseStatus, len(statuses))
+	for _, status := range statuses {
+		byName[status.Name] = status
+	}
+	for _, db := range sla.Databases {
+		status, ok := byName[db.Database]
+		if !ok {
+			status = models.DatabaseStatus{Name: db.Database, Group: db.Group, Health: models.HealthVerdict{Level: models.HealthUnknown}}
+		}
+		r.Databases = append(r.Databases, DatabaseReport{Status: status, SLA: db, Lag: []LagPoint{}, Alerts: []Alert{}})
+		collectors[db.Database] = &statusCollector{from: period.From, bucket: lagInterval, interval: interval, lag: make(map[int64]float64)}
+	}
+
+	err = store.Samples(period.From, period.To, func(sample history.Sample) error {
+		if c := collectors[sample.Database]; c != nil {
+			c.add(sample)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range r.Databases {
+		c := collectors[r.Databases[i].Status.Name]
+		c.closeAlert(true)
+		r.Databases[i].Lag = c.lagPoints()
+		r.Databases[i].Alerts = c.alerts
+	}
+
+	err = store.Events(period.From, period.To, func(event history.Event) error {
+		if collectors[event.Database] != nil {
+			r.Events = append(r.Events, event)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// statusCollector reduces the samples of one database to lag chart points and alerts.
+type statusCollector struct {
+	from     time.Time
+	bucket   time.Duration
+	interval time.Duration     // Refresh interval; a sample stands for this long, as in the SLA figures
+	lag      map[int64]float64 // Largest lag per bucket; -1 when only unknown lags were seen
+
+	alerts []Alert
+	alert  *Alert
+	last   time.Time
+}
+
+func (c *statusCollector) add(s history.Sample) {
+	if standby, _ := standbyOf(s); !standby.Maintenance {
+		lag := -1.0
+		if standby.Connected && standby.Lag >= 0 {
+			lag = standby.Lag
+		}
+		bucket := int64(s.Time.Sub(c.from) / c.bucket)
+		if previous, ok := c.lag[bucket]; !ok || lag > previous {
+			c.lag[bucket] = lag
+		}
+	}
+
+	if c.alert != nil && s.Time.Sub(c.last) > maxGapIntervals*c.interval {
+		c.closeAlert(false)
+	}
+	c.last = s.Time
+	if models.HealthSeverity(s.Level) == 0 {
+		if c.alert != nil {
+			c.alert.End = s.Time
+			c.closeAlert(false)
+		}
+		return
+	}
+	if c.alert == nil {
+		c.alert = &Alert{Level: s.Level, Start: s.Time, Reasons: []string{}}
+	}
+	c.alert.End = s.Time.Add(c.interval)
+	if models.HealthSeverity(s.Level) > models.HealthSeverity(c.alert.Level) {
+		c.alert.Level = s.Level
+	}
+	for _, code := range s.Reasons {
+		if !contains(c.alert.Reasons, code) {
+			c.alert.Reasons = append(c.alert.Reasons, code)
+		}
+	}
+}
+
+// closeAlert ends the current alert; atEnd marks it as still firing at the
+// last sample of the period.
+func (c *statusCollector) closeAlert(atEnd bool) {
+	if c.alert == nil {
+		return
+	}
+	if atEnd {
+		c.alert.Ongoing = true
+		c.alert.End = c.last
+	}
+	c.alerts = append(c.alerts, *c.alert)
+	c.alert = nil
+}
+
+func (c *statusCollector) lagPoints() []LagPoint {
+	buckets := make([]int64, 0, len(c.lag))
+	for bucket := range c.lag {
+		buckets = append(buckets, bucket)
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i] < buckets[j] })
+	points := make([]LagPoint, len(buckets))
+	for i, bucket := range buckets {
+		points[i] = LagPoint{Time: c.from.Add(time.Duration(bucket) * c.bucket), Lag: c.lag[bucket]}
+	}
+	return points
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,406 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+// Limits of the PDF, which cannot scroll: further rows are counted but not listed.
+const (
+	maxPDFAlerts = 25
+	maxPDFEvents = 300
+)
+
+// levelColors are the colors of the health levels, as on the dashboard.
+var levelColors = map[string]string{
+	models.HealthOK:          "#237804",
+	models.HealthWarning:     "#d48806",
+	models.HealthCritical:    "#cf1322",
+	models.HealthUnknown:     "#595959",
+	models.HealthMaintenance: "#9254de",
+}
+
+// SLARows returns the SLA figures of the reported databases.
+func (r *StatusReport) SLARows() []DatabaseSLA {
+	rows := make([]DatabaseSLA, len(r.Databases))
+	for i, db := range r.Databases {
+		rows[i] = db.SLA
+	}
+	return rows
+}
+
+// Chart returns the lag chart of a database as inline SVG.
+func (r *StatusReport) Chart(db DatabaseReport) template.HTML {
+	return newLagChart(db.Lag, r.Period, r.LagInterval, db.SLA.RPOSeconds, 720, 160).SVG()
+}
+
+// StandbyLag returns the lag last reported by the standby member, or a dash
+// when it could not be queried.
+func (db DatabaseReport) StandbyLag() string {
+	s := db.Status
+	delay, connected := s.DisasterDgDelay, s.DisasterDbConnect
+	if s.DisasterRole == "PRIMARY" && s.ProductionRole != "PRIMARY" {
+		delay, connected = s.ProductionDgDelay, s.ProductionDbConnect
+	}
+	if !connected || delay < 0 {
+		return "–"
+	}
+	return formatDuration(float64(delay))
+}
+
+// WriteStatusHTML writes a self-contained status report page.
+func WriteStatusHTML(w io.Writer, r *StatusReport, t Translator, lang string) error {
+	return templates.ExecuteTemplate(w, "status", htmlData{Report: r, Lang: lang, t: t})
+}
+
+const statusHTML = `{{define "status"}}<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<title>{{.T "reportStatusTitle"}} {{.Report.Period.Label}}</title>
+{{template "style"}}
+</head>
+<body>
+{{$d := .}}{{$r := .Report}}<h1>{{.T "reportStatusTitle"}}{{if $r.Name}} · {{$r.Name}}{{end}}</h1>
+<div class="meta">{{.T "reportPeriod"}}: {{date $r.Period.From}} – {{date $r.Period.To}} · {{.T "reportCollected"}}: {{time $r.CollectedAt}} · {{.T "reportGenerated"}}: {{time $r.Generated}}</div>
+{{range $r.Warnings}}<div class="warning">{{$d.Warning .Code}}</div>
+{{end}}
+<h2>{{.T "reportSnapshot"}}</h2>
+<table>
+<tr><th>{{.T "reportDatabase"}}</th><th>{{.T "reportHealth"}}</th><th>{{.T "targetProd"}}</th><th>{{.T "targetDR"}}</th><th>{{.T "delayLabel"}}</th><th>{{.T "reportReasons"}}</th></tr>
+{{range $r.Databases}}{{$s := .Status}}<tr>
+<td>{{$s.Name}}</td>
+<td class="level level-{{lower $s.Health.Level}}">{{$d.Health $s.Health.Level}}</td>
+<td>{{$d.T $s.ProductionRole}}{{if $s.ProductionStatus}} · {{$d.T $s.ProductionStatus}}{{end}}</td>
+<td>{{$d.T $s.DisasterRole}}{{if $s.DisasterStatus}} · {{$d.T $s.DisasterStatus}}{{end}}</td>
+<td class="num">{{.StandbyLag}}</td>
+<td>{{range $i, $reason := $s.Health.Reasons}}{{if $i}}, {{end}}{{$d.Health $reason.Code}}{{if $reason.Member}} ({{$d.Member $reason.Member}}){{end}}{{if $reason.Acknowledged}} ✓{{end}}{{end}}</td>
+</tr>{{end}}
+</table>
+{{range $r.Databases}}{{$s := .Status}}{{if or $s.Acknowledgements $s.Notes $s.Maintenance}}<h3>{{$s.Name}}</h3>
+<ul>
+{{range $s.Maintenance}}<li>{{$d.T "reportMaintenance"}}{{if .Member}} ({{$d.Member .Member}}){{end}}: {{.Reason}} – {{.Owner}}, {{date .Start}} – {{date .End}}</li>
+{{end}}{{range $s.Acknowledgements}}<li>{{$d.T "ackLabel"}}: {{$d.Health .Code}}{{if .Member}} ({{$d.Member .Member}}){{end}} – {{.Comment}} ({{.By}}, {{time .Time}}{{if .Expires}}, {{$d.T "ackExpires"}} {{time .Expires}}{{end}})</li>
+{{end}}{{range $s.Notes}}<li>{{$d.T "noteText"}}: {{.Text}} ({{.By}}, {{time .Time}})</li>
+{{end}}</ul>
+{{end}}{{end}}
+<h2>{{.T "reportSlaTitle"}}</h2>
+{{template "slaTable" (.Rows $r.SLARows)}}
+
+<h2>{{.T "reportLagAndAlerts"}}</h2>
+{{range $r.Databases}}<div class="database">
+<h3>{{.Status.Name}}</h3>
+{{$r.Chart .}}
+<div class="legend"><span style="border-color: #1677ff"></span>{{$d.T "reportStandbyLag"}}<span style="border-color: #cf1322; border-top-style: dashed"></span>RPO<span style="border-color: #ffccc7; border-top-width: 8px"></span>{{$d.T "reportStandbyUnreachable"}}</div>
+{{if .Alerts}}<table>
+<tr><th>{{$d.T "reportStart"}}</th><th>{{$d.T "reportEnd"}}</th><th>{{$d.T "reportHealth"}}</th><th>{{$d.T "reportReasons"}}</th></tr>
+{{range .Alerts}}<tr><td>{{time .Start}}</td><td>{{time .End}}{{if .Ongoing}} ({{$d.T "reportOngoing"}}){{end}}</td><td class="level level-{{lower .Level}}">{{$d.Health .Level}}</td><td>{{range $i, $code := .Reasons}}{{if $i}}, {{end}}{{$d.Health $code}}{{end}}</td></tr>
+{{end}}</table>{{else}}<div class="none">{{$d.T "reportNoAlerts"}}</div>{{end}}
+</div>
+{{end}}
+<h2>{{.T "reportEvents"}}</h2>
+{{if $r.Events}}<table>
+<tr><th>{{.T "reportTime"}}</th><th>{{.T "reportDatabase"}}</th><th>{{.T "reportEventType"}}</th><th>{{.T "reportMessage"}}</th></tr>
+{{range $r.Events}}<tr><td>{{time .Time}}</td><td>{{.Database}}</td><td>{{.Type}}</td><td>{{.Message}}</td></tr>
+{{end}}</table>{{else}}<div class="none">{{.T "reportNone"}}</div>{{end}}
+</body>
+</html>
+{{end}}`
+
+// WriteStatusPDF writes the status report as a PDF document with the same
+// content as the HTML page; long lists are cut short.
+func WriteStatusPDF(w io.Writer, r *StatusReport, t Translator, lang string) error {
+	p := statusPDF{doc: newPDFDocument(lang), t: t}
+	d := p.doc
+	right := pageWidth - pageMargin
+	width := right - pageMargin
+
+	title := t("reportStatusTitle")
+	if r.Name != "" {
+		title += " · " + r.Name
+	}
+	d.text(pageMargin, d.y+16, 16, true, "#222222", title)
+	d.y += 24
+	d.text(pageMargin, d.y+9, 8, false, "#666666", fmt.Sprintf("%s: %s – %s · %s: %s · %s: %s",
+		t("reportPeriod"), r.Period.From.Format("2006-01-02 15:04"), r.Period.To.Format("2006-01-02 15:04"),
+		t("reportCollected"), r.CollectedAt.Format("2006-01-02 15:04:05"), t("reportGenerated"), r.Generated.Format("2006-01-02 15:04:05")))
+	d.y += 14
+	for _, warning := range r.Warnings {
+		for _, line := range d.wrap(t("reportWarning_"+warning.Code), width, 8) {
+			d.text(pageMargin, d.y+9, 8, false, "#d48806", line)
+			d.y += 11
+		}
+	}
+
+	// Current state.
+	p.heading(t("reportSnapshot"))
+	var rows [][]string
+	var colors []string
+	for _, db := range r.Databases {
+		s := db.Status
+		var reasons []string
+		for _, reason := range s.Health.Reasons {
+			text := t("health_" + reason.Code)
+			if reason.Member != "" {
+				text += " (" + memberName(reason.Member, t) + ")"
+			}
+			if reason.Acknowledged {
+				text += " [" + t("ackLabel") + "]"
+			}
+			reasons = append(reasons, text)
+		}
+		rows = append(rows, []string{s.Name, t("health_" + s.Health.Level), memberState(s.ProductionRole, s.ProductionStatus, t), memberState(s.DisasterRole, s.DisasterStatus, t), db.StandbyLag(), strings.Join(reasons, ", ")})
+		colors = append(colors, levelColors[s.Health.Level])
+	}
+	p.table([]pdfColumn{{t("reportDatabase"), 80, false}, {t("reportHealth"), 60, false}, {t("targetProd"), 80, false}, {t("targetDR"), 80, false}, {t("delayLabel"), 40, true}, {t("reportReasons"), width - 340, false}}, rows,
+		func(row, col int) string {
+			if col == 1 {
+				return colors[row]
+			}
+			return ""
+		})
+	for _, db := range r.Databases {
+		s := db.Status
+		var lines []string
+		for _, m := range s.Maintenance {
+			lines = append(lines, fmt.Sprintf("%s: %s – %s, %s – %s", t("reportMaintenance"), m.Reason, m.Owner, m.Start.Format("2006-01-02 15:04"), m.End.Format("2006-01-02 15:04")))
+		}
+		for _, ack := range s.Acknowledgements {
+			lines = append(lines, fmt.Sprintf("%s: %s – %s (%s, %s)", t("ackLabel"), t("health_"+ack.Code), ack.Comment, ack.By, ack.Time.Format("2006-01-02 15:04")))
+		}
+		for _, note := range s.Notes {
+			lines = append(lines, fmt.Sprintf("%s: %s (%s, %s)", t("noteText"), note.Text, note.By, note.Time.Format("2006-01-02 15:04")))
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		d.ensure(24)
+		d.y += 4
+		d.text(pageMargin, d.y+9, 9, true, "#222222", s.Name)
+		d.y += 12
+		for _, line := range lines {
+			for _, wrapped := range d.wrap("• "+line, width-8, 8) {
+				d.ensure(11)
+				d.text(pageMargin+4, d.y+8, 8, false, "#222222", wrapped)
+				d.y += 11
+			}
+		}
+	}
+
+	// SLA figures.
+	p.heading(t("reportSlaTitle"))
+	rows = nil
+	var met [][2]bool
+	for _, db := range r.Databases {
+		sla := db.SLA
+		excursion := "–"
+		if sla.LongestExcursion != nil {
+			excursion = formatDuration(sla.LongestExcursion.Seconds)
+		}
+		rows = append(rows, []string{sla.Database, displayPercent(sla.LagWithinRPOPercent), excursion, displayPercent(sla.StandbyAvailabilityPercent), displayPercent(sla.ServiceAvailabilityPercent),
+			fmt.Sprintf("%d (%s)", len(sla.Unreachable), formatDuration(sla.UnreachableSeconds)), fmt.Sprint(sla.RTOBreaches), fmt.Sprint(len(sla.RoleTransitions)), displayPercent(sla.CoveragePercent)})
+		met = append(met, [2]bool{sla.RPOMet, sla.RTOMet})
+	}
+	numeric := (width - 80) / 8
+	p.table([]pdfColumn{{t("reportDatabase"), 80, false}, {t("reportLagWithinRpoPercent"), numeric, true}, {t("reportLongestExcursion"), numeric, true}, {t("reportStandbyAvailabilityPercent"), numeric, true},
+		{t("reportServiceAvailabilityPercent"), numeric, true}, {t("reportUnreachable"), numeric, true}, {t("reportRtoBreaches"), numeric, true}, {t("reportRoleTransitions"), numeric, true}, {t("reportCoveragePercent"), numeric, true}}, rows,
+		func(row, col int) string {
+			switch {
+			case col == 1 && met[row][0], col == 6 && met[row][1]:
+				return levelColors[models.HealthOK]
+			case col == 1, col == 6:
+				return levelColors[models.HealthCritical]
+			}
+			return ""
+		})
+
+	// Lag charts and alerts per database.
+	p.heading(t("reportLagAndAlerts"))
+	for _, db := range r.Databases {
+		chart := newLagChart(db.Lag, r.Period, r.LagInterval, db.SLA.RPOSeconds, width, 130)
+		d.ensure(chart.Height + 40)
+		d.text(pageMargin, d.y+10, 10, true, "#222222", db.Status.Name)
+		d.y += 14
+		p.chart(chart)
+		p.legend()
+
+		rows = nil
+		colors = nil
+		for i, alert := range db.Alerts {
+			if i == maxPDFAlerts {
+				rows = append(rows, []string{fmt.Sprintf("+%d", len(db.Alerts)-maxPDFAlerts), "", "", ""})
+				colors = append(colors, "")
+				break
+			}
+			end := alert.End.Format("2006-01-02 15:04:05")
+			if alert.Ongoing {
+				end += " (" + t("reportOngoing") + ")"
+			}
+			var reasons []string
+			for _, code := range alert.Reasons {
+				reasons = append(reasons, t("health_"+code))
+			}
+			rows = append(rows, []string{alert.Start.Format("2006-01-02 15:04:05"), end, t("health_" + alert.Level), strings.Join(reasons, ", ")})
+			colors = append(colors, levelColors[alert.Level])
+		}
+		if len(rows) == 0 {
+			d.ensure(14)
+			d.text(pageMargin, d.y+9, 8, false, "#888888", t("reportNoAlerts"))
+			d.y += 16
+			continue
+		}
+		p.table([]pdfColumn{{t("reportStart"), 90, false}, {t("reportEnd"), 120, false}, {t("reportHealth"), 60, false}, {t("reportReasons"), width - 270, false}}, rows,
+			func(row, col int) string {
+				if col == 2 {
+					return colors[row]
+				}
+				return ""
+			})
+		d.y += 4
+	}
+
+	// Events.
+	p.heading(t("reportEvents"))
+	rows = nil
+	for i, event := range r.Events {
+		if i == maxPDFEvents {
+			rows = append(rows, []string{fmt.Sprintf("+%d", len(r.Events)-maxPDFEvents), "", "", ""})
+			break
+		}
+		rows = append(rows, []string{event.Time.Format("2006-01-02 15:04:05"), event.Database, event.Type, event.Message})
+	}
+	if len(rows) == 0 {
+		d.text(pageMargin, d.y+9, 8, false, "#888888", t("reportNone"))
+	} else {
+		p.table([]pdfColumn{{t("reportTime"), 80, false}, {t("reportDatabase"), 70, false}, {t("reportEventType"), 110, false}, {t("reportMessage"), width - 260, false}}, rows, nil)
+	}
+
+	_, err := d.WriteTo(w)
+	return err
+}
+
+// statusPDF lays out the parts of the status report on a pdfDocument.
+type statusPDF struct {
+	doc *pdfDocument
+	t   Translator
+}
+
+type pdfColumn struct {
+	title string
+	width float64
+	right bool // Right-aligned, for numbers
+}
+
+// heading starts a section, on a new page if little space is left.
+func (p statusPDF) heading(title string) {
+	d := p.doc
+	d.ensure(60)
+	d.y += 10
+	d.text(pageMargin, d.y+12, 12, true, "#222222", title)
+	d.y += 16
+	d.line(pageMargin, d.y, pageWidth-pageMargin, d.y, 0.5, "#cccccc", "")
+	d.y += 6
+}
+
+// table draws rows of cells cut to their column widths, repeating the header
+// on every page. color, if set, returns the text color of a cell.
+func (p statusPDF) table(columns []pdfColumn, rows [][]string, color func(row, col int) string) {
+	const rowHeight, size = 13.0, 7.5
+	d := p.doc
+	header := func() {
+		x := pageMargin
+		for _, column := range columns {
+			d.rect(x, d.y, column.width, rowHeight, "#f0f0f0", "#cccccc")
+			d.text(x+3, d.y+9, size, true, "#222222", d.fit(column.title, column.width-6, size, true))
+			x += column.width
+		}
+		d.y += rowHeight
+	}
+	d.ensure(2 * rowHeight)
+	header()
+	for i, row := range rows {
+		if d.y+rowHeight > pageHeight-pageMargin {
+			d.newPage()
+			header()
+		}
+		x := pageMargin
+		for j, column := range columns {
+			d.rect(x, d.y, column.width, rowHeight, "", "#cccccc")
+			textColor := "#222222"
+			if color != nil && color(i, j) != "" {
+				textColor = color(i, j)
+			}
+			text := d.fit(row[j], column.width-6, size, false)
+			if column.right {
+				d.textRight(x+column.width-3, d.y+9, size, false, textColor, text)
+			} else {
+				d.text(x+3, d.y+9, size, false, textColor, text)
+			}
+			x += column.width
+		}
+		d.y += rowHeight
+	}
+	d.y += 6
+}
+
+// chart draws a lag chart below the cursor.
+func (p statusPDF) chart(c lagChart) {
+	d := p.doc
+	x0, y0 := pageMargin, d.y
+	for _, u := range c.Unknown {
+		w := u[1] - u[0]
+		if w < 0.5 {
+			w = 0.5
+		}
+		d.rect(x0+u[0], y0+c.Top, w, c.Bottom-c.Top, chartUnknownColor, "")
+	}
+	for _, tick := range c.YTicks {
+		d.line(x0+c.Left, y0+tick.Pos, x0+c.Right, y0+tick.Pos, 0.3, chartGridColor, "")
+		d.textRight(x0+c.Left-3, y0+tick.Pos+2.5, 6.5, false, chartTextColor, tick.Label)
+	}
+	for _, tick := range c.XTicks {
+		d.line(x0+tick.Pos, y0+c.Top, x0+tick.Pos, y0+c.Bottom, 0.3, chartGridColor, "")
+		d.text(x0+tick.Pos-d.width(tick.Label, 6.5, false)/2, y0+c.Height-5, 6.5, false, chartTextColor, tick.Label)
+	}
+	d.line(x0+c.Left, y0+c.RPOY, x0+c.Right, y0+c.RPOY, 0.8, chartRPOColor, "4 3")
+	for _, line := range c.Lines {
+		d.polyline(line, x0, y0, 1, chartLagColor)
+	}
+	d.rect(x0+c.Left, y0+c.Top, c.Right-c.Left, c.Bottom-c.Top, "", chartTextColor)
+	d.y += c.Height + 2
+}
+
+// legend explains the colors of the charts.
+func (p statusPDF) legend() {
+	d := p.doc
+	x := pageMargin + 48
+	for _, item := range []struct{ color, dash, label string }{
+		{chartLagColor, "", p.t("reportStandbyLag")},
+		{chartRPOColor, "3 2", "RPO"},
+		{chartUnknownColor, "", p.t("reportStandbyUnreachable")},
+	} {
+		width := 1.5
+		if item.color == chartUnknownColor {
+			width = 6
+		}
+		d.line(x, d.y+4, x+14, d.y+4, width, item.color, item.dash)
+		d.text(x+18, d.y+7, 7, false, chartTextColor, item.label)
+		x += 30 + d.width(item.label, 7, false)
+	}
+	d.y += 14
+}
+
+// memberState returns the translated role and open mode of a member.
+func memberState(role, status string, t Translator) string {
+	if role == "" {
+		return t(status)
+	}
+	if status == "" {
+		return t(role)
+	}
+	return t(role) + " · " + t(status)
+}
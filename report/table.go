@@ -0,0 +1,336 @@
+package report
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+// Export formats.
+const (
+	ExportCSV   = "csv"
+	ExportJSONL = "jsonl"
+	ExportXLSX  = "xlsx"
+)
+
+// ExportContentType returns the MIME type of an export format, or "" if the format is not supported.
+func ExportContentType(format string) string {
+	switch format {
+	case ExportCSV:
+		return "text/csv; charset=utf-8"
+	case ExportJSONL:
+		return "application/x-ndjson"
+	case ExportXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return ""
+}
+
+// column is one column of an exported table.
+type column struct {
+	Key    string // Field name in JSON Lines
+	Header string // Message ID of the spreadsheet header
+	Member string // DR member the column describes; its name prefixes the header
+}
+
+func (c column) title(t Translator) string {
+	if c.Member != "" {
+		return memberName(c.Member, t) + " " + t(c.Header)
+	}
+	return t(c.Header)
+}
+
+// memberColumns returns the columns of both DR members for the given fields.
+func memberColumns(fields []column) []column {
+	var columns []column
+	for _, member := range []string{models.MemberProduction, models.MemberDisaster} {
+		for _, field := range fields {
+			columns = append(columns, column{Key: member + "_" + field.Key, Header: field.Header, Member: member})
+		}
+	}
+	return columns
+}
+
+// tableWriter writes the rows of an export one at a time, so that long
+// history ranges are streamed. Cells are strings, bools, ints, int64s,
+// float64s, *float64s, times, string lists or string maps; nil and zero
+// times are empty.
+type tableWriter interface {
+	WriteRow(cells []interface{}) error
+	Close() error
+}
+
+// newTableWriter writes the header of a table in the format; sheet names the
+// worksheet of an xlsx file.
+func newTableWriter(w io.Writer, format string, columns []column, t Translator, sheet string) (tableWriter, error) {
+	switch format {
+	case ExportCSV:
+		return newCSVTable(w, columns, t)
+	case ExportJSONL:
+		return &jsonlTable{w: bufio.NewWriter(w), columns: columns}, nil
+	case ExportXLSX:
+		return newXLSXTable(w, columns, t, sheet)
+	}
+	return nil, fmt.Errorf("unsupported export format '%s'", format)
+}
+
+// csvTable writes RFC 4180 CSV with localized headers.
+type csvTable struct {
+	out *csv.Writer
+	row []string
+}
+
+func newCSVTable(w io.Writer, columns []column, t Translator) (*csvTable, error) {
+	// The byte order mark lets spreadsheet applications detect UTF-8, which
+	// the localized headers need.
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return nil, err
+	}
+	table := &csvTable{out: csv.NewWriter(w), row: make([]string, len(columns))}
+	for i, col := range columns {
+		table.row[i] = col.title(t)
+	}
+	return table, table.out.Write(table.row)
+}
+
+func (c *csvTable) WriteRow(cells []interface{}) error {
+	for i, cell := range cells {
+		c.row[i] = cellText(cell)
+	}
+	return c.out.Write(c.row)
+}
+
+func (c *csvTable) Close() error {
+	c.out.Flush()
+	return c.out.Error()
+}
+
+// cellText formats a cell for CSV: times in RFC 3339, lists separated by
+// commas and maps as sorted key=value pairs.
+func cellText(cell interface{}) string {
+	switch v := cell.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return formatFloat(v)
+	case *float64:
+		if v == nil {
+			return ""
+		}
+		return formatFloat(*v)
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	case []string:
+		return strings.Join(v, ",")
+	case map[string]string:
+		pairs := make([]string, 0, len(v))
+		for key, value := range v {
+			pairs = append(pairs, key+"="+value)
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, "; ")
+	}
+	return fmt.Sprint(cell)
+}
+
+// jsonlTable writes one JSON object per row, keyed by the column keys in column order.
+type jsonlTable struct {
+	w       *bufio.Writer
+	columns []column
+}
+
+func (j *jsonlTable) WriteRow(cells []interface{}) error {
+	j.w.WriteByte('{')
+	for i, cell := range cells {
+		if i > 0 {
+			j.w.WriteByte(',')
+		}
+		key, _ := json.Marshal(j.columns[i].Key)
+		j.w.Write(key)
+		j.w.WriteByte(':')
+		if t, ok := cell.(time.Time); ok && t.IsZero() {
+			cell = nil
+		}
+		value, err := json.Marshal(cell)
+		if err != nil {
+			return err
+		}
+		j.w.Write(value)
+	}
+	j.w.WriteString("}\n")
+	if j.w.Buffered() > 32*1024 {
+		return j.w.Flush()
+	}
+	return nil
+}
+
+func (j *jsonlTable) Close() error { return j.w.Flush() }
+
+// maxXLSXRows is the row limit of a worksheet, including the header.
+const maxXLSXRows = 1048576
+
+// xlsxTable streams a single-sheet Office Open XML workbook. Strings are
+// stored inline, so the sheet can be written before all rows are known.
+type xlsxTable struct {
+	zip   *zip.Writer
+	sheet *bufio.Writer
+	rows  int
+}
+
+func newXLSXTable(w io.Writer, columns []column, t Translator, sheet string) (*xlsxTable, error) {
+	z := zip.NewWriter(w)
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, xmlText(sheetName(sheet)))},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, part := range parts {
+		f, err := z.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+	f, err := z.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	table := &xlsxTable{zip: z, sheet: bufio.NewWriter(f)}
+	fmt.Fprintf(table.sheet, xlsxSheetStart, len(columns))
+	table.sheet.WriteString("<row>")
+	for _, col := range columns {
+		fmt.Fprintf(table.sheet, `<c t="inlineStr" s="1"><is><t>%s</t></is></c>`, xmlText(col.title(t)))
+	}
+	table.sheet.WriteString("</row>")
+	table.rows = 1
+	return table, nil
+}
+
+func (x *xlsxTable) WriteRow(cells []interface{}) error {
+	if x.rows >= maxXLSXRows {
+		return fmt.Errorf("more than %d rows do not fit into a worksheet; narrow the range or use csv or jsonl", maxXLSXRows-1)
+	}
+	x.rows++
+	x.sheet.WriteString("<row>")
+	for _, cell := range cells {
+		switch v := cell.(type) {
+		case bool:
+			b := 0
+			if v {
+				b = 1
+			}
+			fmt.Fprintf(x.sheet, `<c t="b"><v>%d</v></c>`, b)
+		case int:
+			fmt.Fprintf(x.sheet, `<c><v>%d</v></c>`, v)
+		case int64:
+			fmt.Fprintf(x.sheet, `<c><v>%d</v></c>`, v)
+		case float64:
+			fmt.Fprintf(x.sheet, `<c><v>%s</v></c>`, formatFloat(v))
+		case *float64:
+			if v == nil {
+				x.sheet.WriteString("<c/>")
+				continue
+			}
+			fmt.Fprintf(x.sheet, `<c><v>%s</v></c>`, formatFloat(*v))
+		case time.Time:
+			if v.IsZero() {
+				x.sheet.WriteString("<c/>")
+				continue
+			}
+			fmt.Fprintf(x.sheet, `<c s="2"><v>%s</v></c>`, formatFloat(excelTime(v)))
+		default:
+			text := cellText(cell)
+			if text == "" {
+				x.sheet.WriteString("<c/>")
+				continue
+			}
+			fmt.Fprintf(x.sheet, `<c t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, xmlText(text))
+		}
+	}
+	x.sheet.WriteString("</row>")
+	return nil
+}
+
+func (x *xlsxTable) Close() error {
+	x.sheet.WriteString(xlsxSheetEnd)
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.zip.Close()
+}
+
+// excelTime returns the spreadsheet serial date of t in server local time, in days since 1899-12-30.
+func excelTime(t time.Time) float64 {
+	_, offset := t.In(time.Local).Zone()
+	return float64(t.Unix()+int64(offset))/86400 + 25569
+}
+
+func xmlText(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// sheetName returns a valid worksheet name: at most 31 characters and none of []:*?/\.
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	if name == "" {
+		name = "Sheet1"
+	}
+	return name
+}
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/></Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`
+
+// xlsxStyles defines the cell formats: 0 default, 1 bold header, 2 date and time.
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts><fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts><fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills><borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders><cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs><cellXfs count="3"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/><xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs></styleSheet>`
+
+// xlsxSheetStart freezes the header row and widens all %d columns.
+const xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews><cols><col min="1" max="%d" width="18" customWidth="1"/></cols><sheetData>`
+
+const xlsxSheetEnd = `</sheetData></worksheet>`
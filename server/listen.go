@@ -0,0 +1,87 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/util"
+)
+
+// listenAndServe serves the handler on the Unix socket, over HTTPS or over
+// plain HTTP, as configured in the server section.
+func listenAndServe(handler http.Handler, cfg models.ServerConfig) error {
+	if cfg.UnixSocket != "" {
+		return serveUnixSocket(handler, cfg.UnixSocket)
+	}
+
+	port := cfg.Port
+	if port == "" {
+		port = "8080"
+	}
+	server := &http.Server{Addr: ":" + port, Handler: handler}
+	if !cfg.TLS.Enabled() {
+		logListening("http", port, cfg.PublicBasePath)
+		return server.ListenAndServe()
+	}
+
+	tlsConfig, err := newTLSConfig(cfg.TLS)
+	if err != nil {
+		return err
+	}
+	server.TLSConfig = tlsConfig
+	if cfg.TLS.RedirectHTTPPort != "" {
+		go redirectToHTTPS(cfg.TLS.RedirectHTTPPort, port)
+	}
+	logListening("https", port, cfg.PublicBasePath)
+	return server.ListenAndServeTLS("", "")
+}
+
+// serveUnixSocket serves plain HTTP on a Unix socket, replacing a socket file
+// left behind by an earlier run.
+func serveUnixSocket(handler http.Handler, path string) error {
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return fmt.Errorf("failed to listen on unix socket '%s': %w", path, err)
+	}
+	// Let a proxy running under another user in the same group connect.
+	if err := os.Chmod(path, 0o660); err != nil {
+		util.Logger.Printf("Failed to set permissions of unix socket '%s': %v", path, err)
+	}
+	util.Logger.Printf("Server started, listening on unix socket: %s\n", path)
+	fmt.Printf("Server started, listening on unix socket: %s\n", path)
+	return http.Serve(listener, handler)
+}
+
+// redirectToHTTPS serves plain HTTP on httpPort, redirecting every request to
+// the same host and path on the HTTPS port.
+func redirectToHTTPS(httpPort, httpsPort string) {
+	redirect := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(r.Host); err == nil {
+			host = h
+		}
+		if httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+	util.Logger.Printf("Redirecting HTTP on port %s to HTTPS", httpPort)
+	if err := http.ListenAndServe(":"+httpPort, redirect); err != nil {
+		util.Logger.Printf("HTTP redirect listener failed: %v", err)
+	}
+}
+
+// logListening prints where the server can be reached.
+func logListening(scheme, port, basePath string) {
+	util.Logger.Printf("Server started, listening on port: %s\n", port)
+	util.Logger.Printf("Public base path: %s\n", basePath)
+	util.Logger.Printf("Access URL: %s://localhost:%s%s\n", scheme, port, basePath)
+	fmt.Printf("Server started, listening on port: %s\n", port)
+	fmt.Printf("Access URL: %s://localhost:%s%s\n", scheme, port, basePath)
+}
@@ -4,20 +4,22 @@ import (
 	"fmt"
 	"math/rand"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/handlers"
 	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
 )
 
 // MockApiResponse is a custom response structure for the mock endpoint,
 // which includes translated titles along with the data.
 type MockApiResponse struct {
-	Code      int                    `json:"code"`
+	Code      int                     `json:"code"`
 	Data      []models.DatabaseStatus `json:"data"`
-	Titles    models.TitlesConfig    `json:"titles"`
-	Message   string                 `json:"message"`
-	Timestamp int64                  `json:"timestamp"`
+	Titles    models.TitlesConfig     `json:"titles"`
+	Message   string                  `json:"message"`
+	Timestamp int64                   `json:"timestamp"`
 }
 
 // mockDataHandler generates mock data for 12 databases for screenshots.
@@ -87,21 +89,37 @@ func mockDataHandler(c *gin.Context) {
 		"MES_DB", "ERP_DB", "SCM_DB", "WMS_DB", "PLM_DB", "CRM_DB",
 		"QMS_DB", "HRM_DB", "FIN_DB", "BI_DB", "OA_DB", "DCS_DB",
 	}
+	dbGroups := []string{
+		"Manufacturing", "Corporate", "Supply Chain", "Supply Chain", "Manufacturing", "Corporate",
+		"Manufacturing", "Corporate", "Corporate", "Corporate", "Corporate", "Manufacturing",
+	}
+	filter := filterFromQuery(c)
 
 	rand.Seed(time.Now().UnixNano())
 
 	dbStatuses := make([]models.DatabaseStatus, 0, len(dbNames))
 	for i, name := range dbNames {
+		tier := "tier-1"
+		if i >= 6 {
+			tier = "tier-2"
+		}
+		tags := []string{tier, strings.ToLower(strings.TrimSuffix(name, "_DB"))}
+		if !filter.Matches(name, dbGroups[i], tags) {
+			continue
+		}
+
 		prodRole := selectedTrans.Primary
 		disasterRole := selectedTrans.PhysicalStandby
 		prodStatus := selectedTrans.Open
 		disasterStatus := selectedTrans.ReadOnly
+		rawDisasterStatus := "READ ONLY WITH APPLY"
 		prodDelay := 0
 		disasterDelay := rand.Intn(20) // 0-19 seconds delay
 
 		// Introduce some random variations for realism
 		if i%4 == 0 {
 			disasterStatus = selectedTrans.Mounted
+			rawDisasterStatus = "MOUNTED"
 			disasterDelay = rand.Intn(120) + 60 // 1-3 minutes delay
 		}
 
@@ -116,23 +134,61 @@ func mockDataHandler(c *gin.Context) {
 			ProductionAlive:       true,
 			ProductionPort1521:    true,
 			ProductionDbConnect:   true,
-			ProductionStatus:      prodStatus,
-			ProductionRole:        prodRole,
+			ProductionStatus:      "READ WRITE",
+			ProductionRole:        "PRIMARY",
 			ProductionDgDelay:     prodDelay,
 			DisasterIP:            fmt.Sprintf("10.20.1.%d", 10+i),
 			DisasterAlive:         true,
 			DisasterPort1521:      true,
 			DisasterDbConnect:     i%4 != 0, // Simulate a connection issue occasionally
-			DisasterStatus:        disasterStatus,
-			DisasterRole:          disasterRole,
+			DisasterStatus:        rawDisasterStatus,
+			DisasterRole:          "PHYSICAL STANDBY",
 			DisasterDgDelay:       disasterDelay,
+			Group:                 dbGroups[i],
+			Tier:                  tier,
+			Tags:                  tags,
+		}
+		if status.DisasterDbConnect {
+			heartbeat := time.Now().Add(-time.Duration(disasterDelay) * time.Second)
+			status.DisasterHeartbeat = &models.HeartbeatLag{
+				LagSeconds:       float64(disasterDelay) + float64(rand.Intn(1000))/1000,
+				ClockSkewSeconds: float64(rand.Intn(400)-200) / 1000,
+				Heartbeat:        &heartbeat,
+			}
 		}
+		status.ProductionSpace = mockSpaceUsage(20 + rand.Float64()*40)
+		if status.DisasterDbConnect {
+			status.DisasterSpace = mockSpaceUsage(30 + float64(i*7%60))
+		}
+		status.Backup = mockBackupStatus(i)
+		status.Drift = &models.DriftReport{Differences: []models.DriftItem{}, Compared: 64}
+		if i%3 == 1 {
+			status.Drift.Differences = append(status.Drift.Differences,
+				models.DriftItem{Key: "database.flashback_on", Production: "YES", Disaster: "NO"},
+				models.DriftItem{Key: "parameter.standby_file_management", Production: "AUTO", Disaster: "MANUAL"})
+		}
+		status.Nologging = mockNologgingStatus(i)
+		status.PDBs = mockPDBReport(i, name, rawDisasterStatus)
+		// Health is judged on the raw Oracle values before they are replaced by translated ones.
+		handlers.ApplyHealth(&status, models.GetConfig().Health)
+		if i == 4 {
+			// The standby whose connection fails is being patched.
+			start := time.Now().Add(-40 * time.Minute).Truncate(time.Minute)
+			handlers.ApplyMaintenance(&status, append(status.Maintenance, models.MaintenancePeriod{
+				ID: "mock-patching", Member: models.MemberDisaster, Start: start, End: start.Add(2 * time.Hour),
+				Reason: "Quarterly patch set", Owner: "dba-oncall", Source: models.MaintenanceFromAPI,
+			}))
+		}
+		status.ProductionStatus = prodStatus
+		status.ProductionRole = prodRole
+		status.DisasterStatus = disasterStatus
+		status.DisasterRole = disasterRole
 		dbStatuses = append(dbStatuses, status)
 	}
 
 	response := MockApiResponse{
 		Code:      200,
-		Data:      dbStatuses,
+		Data:      visibleDetails(c, dbStatuses),
 		Titles:    selectedTitles,
 		Message:   "Mock data generated successfully",
 		Timestamp: time.Now().Unix(),
@@ -140,3 +196,135 @@ func mockDataHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+// mockSpaceUsage returns a 500 GB recovery area with the given usage, mostly archived logs.
+func mockSpaceUsage(usedPercent float64) *models.SpaceUsage {
+	const limit = int64(500) << 30
+	used := int64(usedPercent / 100 * float64(limit))
+	rate := float64(int64(5)<<30) + rand.Float64()*float64(int64(10)<<30)
+	hoursToFull := float64(limit-used) / rate
+	dest := float64(used) / float64(limit) * 100
+	total, free := limit, limit-used
+	return &models.SpaceUsage{
+		RecoveryArea: &models.RecoveryAreaUsage{
+			Name:        "+FRA",
+			LimitBytes:  limit,
+			UsedBytes:   used,
+			UsedPercent: usedPercent,
+			FileTypes: []models.RecoveryFileTypeUsage{
+				{FileType: "ARCHIVED LOG", PercentUsed: usedPercent * 0.8, NumberOfFiles: 120},
+				{FileType: "BACKUP PIECE", PercentUsed: usedPercent * 0.2, NumberOfFiles: 12},
+			},
+			ArchiveBytesPerHour: &rate,
+			HoursToFull:         &hoursToFull,
+		},
+		ArchiveDests: []models.ArchiveDestSpace{
+			{DestName: "LOG_ARCHIVE_DEST_1", Destination: "USE_DB_RECOVERY_FILE_DEST", Status: "VALID", TotalBytes: &total, FreeBytes: &free, UsedPercent: &dest},
+		},
+	}
+}
+
+// mockBackupStatus returns backups taken on the standby, with an overdue full
+// backup on every fifth database.
+func mockBackupStatus(i int) *models.BackupStatus {
+	now := time.Now()
+	job := func(inputType string, age time.Duration, duration int64, size int64) *models.BackupJob {
+		end := now.Add(-age)
+		return &models.BackupJob{
+			Member:          models.MemberDisaster,
+			InputType:       inputType,
+			Status:          "COMPLETED",
+			StartTime:       end.Add(-time.Duration(duration) * time.Second),
+			EndTime:         &end,
+			DurationSeconds: duration,
+			OutputBytes:     size,
+			DeviceType:      "SBT_TAPE",
+		}
+	}
+	types := []struct {
+		backupType string
+		job        *models.BackupJob
+		sla        float64
+	}{
+		{models.BackupFull, job("DB FULL", time.Duration(48+i*12)*time.Hour, 5400, 180<<30), 192},
+		{models.BackupIncremental, job("DB INCR", time.Duration(2+i)*time.Hour, 900, 12<<30), 36},
+		{models.BackupArchivelog, job("ARCHIVELOG", time.Duration(10+i*5)*time.Minute, 120, 2<<30), 4},
+	}
+	if i%5 == 4 {
+		types[0].job = job("DB FULL", 240*time.Hour, 5400, 180<<30)
+	}
+
+	backup := &models.BackupStatus{}
+	for _, entry := range types {
+		age := now.Sub(*entry.job.EndTime).Hours()
+		backup.Types = append(backup.Types, models.BackupTypeStatus{
+			Type:        entry.backupType,
+			LastSuccess: entry.job,
+			AgeHours:    &age,
+			SLAHours:    entry.sla,
+			Overdue:     age > entry.sla,
+		})
+	}
+	return backup
+}
+
+// mockNologgingStatus reports an unacknowledged NOLOGGING load on every sixth
+// database, with the resulting non-logged blocks on its standby.
+func mockNologgingStatus(i int) *models.NologgingStatus {
+	nologging := &models.NologgingStatus{
+		PrimaryMember:   models.MemberProduction,
+		ForceLogging:    "YES",
+		Files:           []models.UnrecoverableFile{},
+		StandbyMember:   models.MemberDisaster,
+		NonloggedBlocks: &models.NonloggedBlocks{},
+	}
+	if i%6 != 2 {
+		return nologging
+	}
+	detected := time.Now().Add(-35 * time.Minute)
+	operation := detected.Add(-4 * time.Minute)
+	nologging.ForceLogging = "NO"
+	nologging.LatestChange = 48211930455
+	nologging.DetectedAt = &detected
+	nologging.Unacknowledged = true
+	nologging.Files = []models.UnrecoverableFile{
+		{File: 7, Name: "+DATA/PROD/DATAFILE/users.271.1123456789", Change: 48211930455, Time: &operation},
+	}
+	nologging.NonloggedBlocks = &models.NonloggedBlocks{Files: 1, Ranges: 3, Blocks: 1536}
+	return nologging
+}
+
+// mockPDBReport returns two application PDBs for the multitenant databases
+// (every database but every third), with a restricted, closed standby PDB on one of them.
+func mockPDBReport(i int, name, disasterStatus string) *models.PDBReport {
+	if i%3 == 2 {
+		return nil
+	}
+	standbyMode := "READ ONLY"
+	if disasterStatus == "MOUNTED" {
+		standbyMode = "MOUNTED"
+	}
+	prefix := strings.TrimSuffix(name, "_DB")
+	report := &models.PDBReport{PDBs: []models.PDBComparison{}}
+	for conID, pdbName := range []string{"PDB$SEED", prefix + "_APP", prefix + "_RPT"} {
+		primaryMode := "READ WRITE"
+		if conID == 0 {
+			primaryMode = "READ ONLY"
+		}
+		item := models.PDBComparison{
+			Name:       pdbName,
+			Production: &models.PDBState{ConID: int64(conID + 2), Name: pdbName, OpenMode: primaryMode, Restricted: "NO", RecoveryStatus: "ENABLED"},
+			Disaster:   &models.PDBState{ConID: int64(conID + 2), Name: pdbName, OpenMode: standbyMode, Restricted: "NO", RecoveryStatus: "ENABLED"},
+			Issues:     []models.PDBIssue{},
+		}
+		if i == 7 && conID == 2 {
+			item.Disaster.OpenMode, item.Disaster.Restricted = "MOUNTED", "YES"
+			item.Issues = append(item.Issues,
+				models.PDBIssue{Code: handlers.PDBRestricted, Member: models.MemberDisaster, Level: models.HealthWarning},
+				models.PDBIssue{Code: handlers.PDBNotOpen, Member: models.MemberDisaster, Level: models.HealthWarning})
+			report.Mismatches++
+		}
+		report.PDBs = append(report.PDBs, item)
+	}
+	return report
+}
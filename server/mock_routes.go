@@ -5,6 +5,6 @@ package server
 import "github.com/gin-gonic/gin"
 
 // registerMockRoutes adds the mock data endpoint to the router.
-func registerMockRoutes(r *gin.Engine) {
+func registerMockRoutes(r gin.IRouter) {
 	r.GET("/api/mock-data", mockDataHandler)
 }
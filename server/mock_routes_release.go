@@ -5,6 +5,6 @@ package server
 import "github.com/gin-gonic/gin"
 
 // registerMockRoutes is a no-op for release builds.
-func registerMockRoutes(r *gin.Engine) {
+func registerMockRoutes(r gin.IRouter) {
 	// This function is intentionally left empty.
 }
@@ -3,16 +3,24 @@ package server
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
 	"io/fs"
 	"log"
 	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/audit"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/auth"
 	"github.com/goodwaysIT/go-oracle-dr-dashboard/handlers"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/history"
 	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/report"
 	"github.com/goodwaysIT/go-oracle-dr-dashboard/util"
 
 	"github.com/fsnotify/fsnotify"
@@ -35,7 +43,45 @@ func i18nMiddleware(bundle *i18n.Bundle) gin.HandlerFunc {
 	}
 }
 
-// watchConfig monitors the config file for changes and reloads it.
+// translator returns a function translating message IDs into the language of
+// the request, for content rendered on the server.
+func translator(c *gin.Context) report.Translator {
+	localizer, _ := c.Value("localizer").(*i18n.Localizer)
+	return localizerTranslator(localizer)
+}
+
+// requestLanguage returns the language translator(c) uses, e.g. "zh".
+func requestLanguage(c *gin.Context) string {
+	localizer, _ := c.Value("localizer").(*i18n.Localizer)
+	if localizer == nil {
+		return "en"
+	}
+	_, tag, err := localizer.LocalizeWithTag(&i18n.LocalizeConfig{MessageID: "pageTitle"})
+	if err != nil {
+		return "en"
+	}
+	base, _ := tag.Base()
+	return base.String()
+}
+
+// localizerTranslator adapts a localizer to report.Translator. IDs without a
+// translation are returned as is.
+func localizerTranslator(localizer *i18n.Localizer) report.Translator {
+	return func(id string) string {
+		if localizer == nil {
+			return id
+		}
+		text, err := localizer.Localize(&i18n.LocalizeConfig{MessageID: id})
+		if err != nil || text == "" {
+			return id
+		}
+		return text
+	}
+}
+
+// watchConfig monitors the config file and its included files for changes and reloads them.
+// The directories of the include patterns are watched as well, so that files
+// added to or removed from e.g. conf.d/ are picked up without a restart.
 func watchConfig(configFile string) {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -43,37 +89,237 @@ func watchConfig(configFile string) {
 	}
 	defer watcher.Close()
 
-	go func() {
-		for {
-			select {
-			case event, ok := <-watcher.Events:
-				if !ok {
-					return
-				}
-				if event.Has(fsnotify.Write) {
-					util.Logger.Println("Configuration file change detected, reloading...")
-					if err := models.LoadConfig(configFile); err != nil {
-						util.Logger.Printf("Failed to hot-reload config file: %v", err)
-					} else {
-						util.Logger.Println("Configuration file hot-reloaded successfully.")
-					}
-				}
-			case err, ok := <-watcher.Errors:
-				if !ok {
-					return
-				}
-				util.Logger.Printf("File watcher error: %v", err)
+	err = watcher.Add(configFile)
+	if err != nil {
+		util.Logger.Fatalf("Failed to add file to watcher: %v", err)
+	}
+	watched := map[string]bool{configFile: true}
+	syncIncludeWatches(watcher, configFile, watched)
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+				delete(watched, event.Name) // fsnotify drops the watch, re-add on next sync
+			}
+			if !isConfigEvent(configFile, event) {
+				continue
+			}
+			util.Logger.Printf("Configuration change detected (%s), reloading...", event.Name)
+			record := audit.Record{Actor: "system", Action: audit.ActionConfigReload, Target: event.Name}
+			if changes, err := reloadConfig(configFile); err != nil {
+				util.Logger.Printf("Failed to hot-reload config file: %v", err)
+				record.Outcome, record.Details = audit.OutcomeFailure, map[string]string{"error": err.Error()}
+			} else {
+				util.Logger.Println("Configuration file hot-reloaded successfully.")
+				record.Changes = changes
 			}
+			// Editors often write a file in several steps; only reloads that changed something are audited.
+			if record.Outcome == audit.OutcomeFailure || len(record.Changes) > 0 {
+				audit.Log(record)
+			}
+			syncIncludeWatches(watcher, configFile, watched)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			util.Logger.Printf("File watcher error: %v", err)
 		}
-	}()
+	}
+}
 
-	err = watcher.Add(configFile)
+// reloadConfig re-reads the configuration, reopens the audit log if its file
+// changed and starts a check cycle. It returns the differences to the
+// configuration in use before, for the audit log.
+func reloadConfig(configFile string) ([]audit.Change, error) {
+	previous, current, err := models.ReloadConfig(configFile)
 	if err != nil {
-		util.Logger.Fatalf("Failed to add file to watcher: %v", err)
+		return nil, err
+	}
+	if err := audit.Open(current.Audit); err != nil {
+		util.Logger.Printf("Failed to open audit log: %v", err)
+	}
+	handlers.TriggerCollection()
+	return audit.ConfigDiff(previous, current), nil
+}
+
+// isConfigEvent reports whether a watcher event concerns the main config file,
+// a currently loaded included file, or a file matching one of the include patterns.
+func isConfigEvent(configFile string, event fsnotify.Event) bool {
+	if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) && !event.Has(fsnotify.Remove) && !event.Has(fsnotify.Rename) {
+		return false
+	}
+	name := filepath.Clean(event.Name)
+	for _, file := range models.GetConfigFiles() {
+		if filepath.Clean(file) == name {
+			return true
+		}
+	}
+	for _, pattern := range models.IncludePatterns(configFile, models.GetConfig().Include) {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// syncIncludeWatches adds watches for included files and the directories of
+// the include patterns that are not yet being watched. Watches for paths that
+// disappear are dropped automatically by fsnotify.
+func syncIncludeWatches(watcher *fsnotify.Watcher, configFile string, watched map[string]bool) {
+	paths := models.GetConfigFiles()
+	for _, pattern := range models.IncludePatterns(configFile, models.GetConfig().Include) {
+		paths = append(paths, filepath.Dir(pattern))
+	}
+	for _, path := range paths {
+		if watched[path] {
+			continue
+		}
+		if err := watcher.Add(path); err != nil {
+			util.Logger.Printf("Failed to watch config path '%s': %v", path, err)
+			continue
+		}
+		watched[path] = true
+	}
+}
+
+// filterFromQuery builds a database filter from the "db", "group" and "tag" query parameters.
+// Names and tags may be repeated (?tag=a&tag=b) or comma-separated (?tag=a,b).
+// The filter is limited to the databases the user is allowed to see.
+func filterFromQuery(c *gin.Context) models.DatabaseFilter {
+	return models.DatabaseFilter{
+		Names: splitQueryList(c.QueryArray("db")),
+		Group: strings.TrimSpace(c.Query("group")),
+		Tags:  splitQueryList(c.QueryArray("tag")),
+		Scope: auth.Scope(c),
+	}
+}
+
+// periodFromQuery reads the report period from the "period" query parameter,
+// defaulting to def; "from" and "to" override its start and end.
+func periodFromQuery(c *gin.Context, def string, now time.Time) (report.Period, error) {
+	period, err := report.ParsePeriod(c.DefaultQuery("period", def), now)
+	if err != nil {
+		return period, err
+	}
+	from, err := timeFromQuery(c, "from")
+	if err != nil {
+		return period, err
+	}
+	to, err := timeFromQuery(c, "to")
+	if err != nil {
+		return period, err
+	}
+	if !from.IsZero() || !to.IsZero() {
+		if !from.IsZero() {
+			period.From = from
+		}
+		if !to.IsZero() {
+			period.To = to
+		}
+		period.Label = period.From.Format("2006-01-02") + "/" + period.To.Format("2006-01-02")
+	}
+	if !period.From.Before(period.To) {
+		return period, errors.New("the period must end after it starts")
+	}
+	return period, nil
+}
+
+// timeFromQuery parses a query parameter holding an RFC 3339 time or a
+// YYYY-MM-DD date (local midnight). It returns the zero time when the parameter is absent.
+func timeFromQuery(c *gin.Context, name string) (time.Time, error) {
+	value := strings.TrimSpace(c.Query(name))
+	if value == "" {
+		return time.Time{}, nil
 	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s '%s' (expected RFC 3339 time or YYYY-MM-DD)", name, value)
+	}
+	return t, nil
+}
 
-	// Block forever
-	<-make(chan struct{})
+// visibleDetails drops the SQL-derived details (backups, drift, NOLOGGING, PDBs)
+// from the statuses unless the user may view diagnostics.
+func visibleDetails(c *gin.Context, statuses []models.DatabaseStatus) []models.DatabaseStatus {
+	if auth.Allowed(c, models.PermDiagnostics) {
+		return statuses
+	}
+	result := make([]models.DatabaseStatus, len(statuses))
+	for i, status := range statuses {
+		status.Backup = nil
+		status.Drift = nil
+		status.Nologging = nil
+		status.PDBs = nil
+		result[i] = status
+	}
+	return result
+}
+
+// expiry returns the expiry of an acknowledgement or note given either as a
+// time or as minutes from now; nil when neither is set.
+func expiry(expires *time.Time, minutes int) *time.Time {
+	if expires == nil && minutes > 0 {
+		t := time.Now().Add(time.Duration(minutes) * time.Minute)
+		expires = &t
+	}
+	return expires
+}
+
+// maintenanceDetails describes a maintenance window for the audit log.
+func maintenanceDetails(w models.MaintenanceWindow) map[string]string {
+	details := map[string]string{"id": w.ID, "reason": w.Reason, "owner": w.Owner}
+	if w.Member != "" {
+		details["member"] = w.Member
+	}
+	if w.Schedule != "" {
+		details["schedule"] = w.Schedule
+		details["duration_minutes"] = strconv.Itoa(w.DurationMinutes)
+	} else {
+		details["start"] = w.Start.Format(time.RFC3339)
+		details["end"] = w.End.Format(time.RFC3339)
+	}
+	return details
+}
+
+// splitQueryList flattens repeated and comma-separated query values, dropping empty ones.
+func splitQueryList(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+	}
+	return result
+}
+
+// accessLogFormatter writes gin's default access log line with the kiosk token
+// removed from the URL.
+func accessLogFormatter(param gin.LogFormatterParams) string {
+	var statusColor, methodColor, resetColor string
+	if param.IsOutputColor() {
+		statusColor, methodColor, resetColor = param.StatusCodeColor(), param.MethodColor(), param.ResetColor()
+	}
+	if param.Latency > time.Minute {
+		param.Latency = param.Latency.Truncate(time.Second)
+	}
+	return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		statusColor, param.StatusCode, resetColor,
+		param.Latency,
+		param.ClientIP,
+		methodColor, param.Method, resetColor,
+		auth.RedactKioskToken(param.Path),
+		param.ErrorMessage,
+	)
 }
 
 func Run(staticFS, localeFS fs.FS, configFile string) {
@@ -87,6 +333,17 @@ func Run(staticFS, localeFS fs.FS, configFile string) {
 		log.Fatalf("Failed to initialize logger: %v", err)
 	}
 
+	historyConfig := models.GetConfig().History
+	store, err := history.Open(historyConfig.Dir, historyConfig.RetentionDays)
+	if err != nil {
+		util.Logger.Fatalf("Failed to open check history: %v", err)
+	}
+	handlers.StartCollector(store)
+
+	if err := audit.Open(models.GetConfig().Audit); err != nil {
+		util.Logger.Fatalf("Failed to open audit log: %v", err)
+	}
+
 	go watchConfig(configFile)
 
 	// --- Pre-read and cache index.html ---
@@ -120,16 +377,38 @@ func Run(staticFS, localeFS fs.FS, configFile string) {
 	if err != nil {
 		util.Logger.Fatalf("failed to load zh.json: %v", err)
 	}
+	_, err = bundle.LoadMessageFileFS(localeFS, "ja.json")
+	if err != nil {
+		util.Logger.Fatalf("failed to load ja.json: %v", err)
+	}
+
+	report.StartScheduler(store, handlers.LatestStatus, func(lang string) report.Translator {
+		return localizerTranslator(i18n.NewLocalizer(bundle, lang))
+	})
 
 	util.Logger.Println("Starting server...")
 	gin.SetMode(gin.ReleaseMode)
-	router := gin.Default()
+	router := gin.New()
+	router.Use(gin.LoggerWithFormatter(accessLogFormatter), gin.Recovery())
 
 	// Register i18n middleware
 	router.Use(i18nMiddleware(bundle))
 
+	// Every route below requires a login, token or kiosk token when auth.enabled is set.
+	router.Use(auth.Middleware())
+	auth.RegisterRoutes(router)
+
+	// Route groups by the permission they need; all are open when auth is disabled.
+	view := router.Group("", auth.Require(models.PermView))
+	diagnostics := router.Group("", auth.Require(models.PermDiagnostics))
+	acknowledge := router.Group("", auth.Require(models.PermAcknowledge))
+	recheck := router.Group("", auth.Require(models.PermRecheck))
+	reload := router.Group("", auth.Require(models.PermReload))
+	auditors := router.Group("", auth.Require(models.PermAudit))
+	maintainers := router.Group("", auth.Require(models.PermMaintenance))
+
 	// Register mock routes if the 'mock' build tag is enabled.
-	registerMockRoutes(router)
+	registerMockRoutes(view)
 
 	// --- New I18n API Endpoint ---
 	router.GET("/api/i18n/:lang", func(c *gin.Context) {
@@ -153,16 +432,378 @@ func Run(staticFS, localeFS fs.FS, configFile string) {
 	})
 
 	// --- API Route (remains unchanged at /api/data) ---
-	router.GET("/api/data", func(c *gin.Context) {
-		dbStatuses := handlers.GetAllDatabaseStatus()
-		response := models.ApiResponse{Code: 200, Data: dbStatuses, Message: "success", Timestamp: time.Now().Unix()}
+	view.GET("/api/data", func(c *gin.Context) {
+		// Served from the background collector; checked directly only until its first cycle completes.
+		filter := filterFromQuery(c)
+		dbStatuses, _, ok := handlers.LatestStatus(filter)
+		if !ok {
+			dbStatuses = handlers.GetDatabaseStatus(filter)
+		}
+		response := models.ApiResponse{Code: 200, Data: visibleDetails(c, dbStatuses), Message: "success", Timestamp: time.Now().Unix()}
+		c.JSON(http.StatusOK, response)
+	})
+
+	// --- Groups available for the frontend group selector ---
+	view.GET("/api/groups", func(c *gin.Context) {
+		groups := models.GetConfig().GroupsOf(models.DatabaseFilter{Scope: auth.Scope(c)})
+		response := models.ApiResponse{Code: 200, Data: groups, Message: "success", Timestamp: time.Now().Unix()}
 		c.JSON(http.StatusOK, response)
 	})
 
+	// --- Monitoring account self-check ---
+	diagnostics.GET("/api/diagnostics", func(c *gin.Context) {
+		report := handlers.RunDiagnostics(filterFromQuery(c))
+		response := models.ApiResponse{Code: 200, Data: report, Message: "success", Timestamp: time.Now().Unix()}
+		c.JSON(http.StatusOK, response)
+	})
+
+	// --- NOLOGGING acknowledgement ---
+	acknowledge.POST("/api/databases/:name/nologging/ack", func(c *gin.Context) {
+		if !auth.InScope(c, c.Param("name")) {
+			auth.Abort(c, http.StatusForbidden, "database is outside your scope")
+			return
+		}
+		var request struct {
+			Comment string `json:"comment"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil && err != io.EOF {
+			c.JSON(http.StatusBadRequest, models.ApiResponse{Code: 400, Message: "invalid request body", Timestamp: time.Now().Unix()})
+			return
+		}
+		ack, err := handlers.AcknowledgeNologging(c.Param("name"), auth.Actor(c), request.Comment)
+		if err != nil {
+			c.JSON(http.StatusConflict, models.ApiResponse{Code: 409, Message: err.Error(), Timestamp: time.Now().Unix()})
+			return
+		}
+		util.Logger.Printf("NOLOGGING operations on %s acknowledged up to change %d by %s", c.Param("name"), ack.Change, ack.By)
+		auth.Audit(c, audit.Record{Action: audit.ActionNologgingAck, Target: c.Param("name"),
+			Details: map[string]string{"change": strconv.FormatInt(ack.Change, 10), "comment": ack.Comment}})
+		c.JSON(http.StatusOK, models.ApiResponse{Code: 200, Data: ack, Message: "success", Timestamp: time.Now().Unix()})
+	})
+
+	// --- Alert acknowledgements and pinned notes ---
+	acknowledge.POST("/api/databases/:name/acks", func(c *gin.Context) {
+		if !auth.InScope(c, c.Param("name")) {
+			auth.Abort(c, http.StatusForbidden, "database is outside your scope")
+			return
+		}
+		var request struct {
+			Code           string     `json:"code"`
+			Member         string     `json:"member"`
+			Comment        string     `json:"comment"`
+			Expires        *time.Time `json:"expires"`
+			ExpiresMinutes int        `json:"expires_minutes"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil || request.Code == "" {
+			c.JSON(http.StatusBadRequest, models.ApiResponse{Code: 400, Message: "invalid request body, code is required", Timestamp: time.Now().Unix()})
+			return
+		}
+		ack, err := handlers.AcknowledgeAlert(models.AlertAck{
+			Database: c.Param("name"), Code: request.Code, Member: request.Member, Comment: request.Comment,
+			By: auth.Actor(c), Expires: expiry(request.Expires, request.ExpiresMinutes),
+		})
+		switch {
+		case errors.Is(err, handlers.ErrNotFiring):
+			c.JSON(http.StatusConflict, models.ApiResponse{Code: 409, Message: err.Error(), Timestamp: time.Now().Unix()})
+			return
+		case err != nil:
+			c.JSON(http.StatusBadRequest, models.ApiResponse{Code: 400, Message: err.Error(), Timestamp: time.Now().Unix()})
+			return
+		}
+		util.Logger.Printf("%s on %s acknowledged by %s", ack.Code, ack.Database, ack.By)
+		auth.Audit(c, audit.Record{Action: audit.ActionAlertAck, Target: ack.Database,
+			Details: map[string]string{"id": ack.ID, "code": ack.Code, "member": ack.Member, "comment": ack.Comment}})
+		c.JSON(http.StatusCreated, models.ApiResponse{Code: 201, Data: ack, Message: "success", Timestamp: time.Now().Unix()})
+	})
+
+	acknowledge.DELETE("/api/databases/:name/acks/:id", func(c *gin.Context) {
+		if !auth.InScope(c, c.Param("name")) {
+			auth.Abort(c, http.StatusForbidden, "database is outside your scope")
+			return
+		}
+		ack, err := handlers.RemoveAcknowledgement(c.Param("name"), c.Param("id"), auth.Actor(c))
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ApiResponse{Code: 404, Message: err.Error(), Timestamp: time.Now().Unix()})
+			return
+		}
+		util.Logger.Printf("Acknowledgement of %s on %s removed by %s", ack.Code, ack.Database, auth.Actor(c))
+		auth.Audit(c, audit.Record{Action: audit.ActionAlertUnack, Target: ack.Database,
+			Details: map[string]string{"id": ack.ID, "code": ack.Code, "member": ack.Member}})
+		c.JSON(http.StatusOK, models.ApiResponse{Code: 200, Data: ack, Message: "success", Timestamp: time.Now().Unix()})
+	})
+
+	acknowledge.POST("/api/databases/:name/notes", func(c *gin.Context) {
+		if !auth.InScope(c, c.Param("name")) {
+			auth.Abort(c, http.StatusForbidden, "database is outside your scope")
+			return
+		}
+		var request struct {
+			Text           string     `json:"text"`
+			Expires        *time.Time `json:"expires"`
+			ExpiresMinutes int        `json:"expires_minutes"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, models.ApiResponse{Code: 400, Message: "invalid request body", Timestamp: time.Now().Unix()})
+			return
+		}
+		note, err := handlers.PinNote(models.Note{
+			Database: c.Param("name"), Text: request.Text, By: auth.Actor(c),
+			Expires: expiry(request.Expires, request.ExpiresMinutes),
+		})
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ApiResponse{Code: 400, Message: err.Error(), Timestamp: time.Now().Unix()})
+			return
+		}
+		util.Logger.Printf("Note pinned to %s by %s", note.Database, note.By)
+		auth.Audit(c, audit.Record{Action: audit.ActionNotePin, Target: note.Database,
+			Details: map[string]string{"id": note.ID, "text": note.Text}})
+		c.JSON(http.StatusCreated, models.ApiResponse{Code: 201, Data: note, Message: "success", Timestamp: time.Now().Unix()})
+	})
+
+	acknowledge.DELETE("/api/databases/:name/notes/:id", func(c *gin.Context) {
+		if !auth.InScope(c, c.Param("name")) {
+			auth.Abort(c, http.StatusForbidden, "database is outside your scope")
+			return
+		}
+		note, err := handlers.RemoveNote(c.Param("name"), c.Param("id"), auth.Actor(c))
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ApiResponse{Code: 404, Message: err.Error(), Timestamp: time.Now().Unix()})
+			return
+		}
+		util.Logger.Printf("Note on %s removed by %s", note.Database, auth.Actor(c))
+		auth.Audit(c, audit.Record{Action: audit.ActionNoteRemove, Target: note.Database,
+			Details: map[string]string{"id": note.ID, "text": note.Text}})
+		c.JSON(http.StatusOK, models.ApiResponse{Code: 200, Data: note, Message: "success", Timestamp: time.Now().Unix()})
+	})
+
+	// --- Maintenance windows ---
+	view.GET("/api/maintenance", func(c *gin.Context) {
+		windows := []models.MaintenanceWindow{}
+		for _, w := range handlers.MaintenanceWindows() {
+			if auth.InScope(c, w.Database) {
+				windows = append(windows, w)
+			}
+		}
+		c.JSON(http.StatusOK, models.ApiResponse{Code: 200, Data: windows, Message: "success", Timestamp: time.Now().Unix()})
+	})
+
+	maintainers.POST("/api/maintenance", func(c *gin.Context) {
+		var window models.MaintenanceWindow
+		if err := c.ShouldBindJSON(&window); err != nil {
+			c.JSON(http.StatusBadRequest, models.ApiResponse{Code: 400, Message: "invalid request body", Timestamp: time.Now().Unix()})
+			return
+		}
+		if !auth.InScope(c, window.Database) {
+			auth.Abort(c, http.StatusForbidden, "database is outside your scope")
+			return
+		}
+		if window.Owner == "" {
+			window.Owner = auth.Actor(c)
+		}
+		window, err := handlers.AddMaintenance(window)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ApiResponse{Code: 400, Message: err.Error(), Timestamp: time.Now().Unix()})
+			return
+		}
+		util.Logger.Printf("Maintenance window %s on %s added by %s: %s", window.ID, window.Database, auth.Actor(c), window.Reason)
+		auth.Audit(c, audit.Record{Action: audit.ActionMaintenanceAdd, Target: window.Database,
+			Details: maintenanceDetails(window)})
+		c.JSON(http.StatusCreated, models.ApiResponse{Code: 201, Data: window, Message: "success", Timestamp: time.Now().Unix()})
+	})
+
+	maintainers.DELETE("/api/maintenance/:id", func(c *gin.Context) {
+		for _, w := range handlers.MaintenanceWindows() {
+			if w.ID == c.Param("id") && !auth.InScope(c, w.Database) {
+				auth.Abort(c, http.StatusForbidden, "database is outside your scope")
+				return
+			}
+		}
+		window, err := handlers.EndMaintenance(c.Param("id"))
+		switch {
+		case errors.Is(err, handlers.ErrMaintenanceNotFound):
+			c.JSON(http.StatusNotFound, models.ApiResponse{Code: 404, Message: err.Error(), Timestamp: time.Now().Unix()})
+			return
+		case err != nil:
+			c.JSON(http.StatusConflict, models.ApiResponse{Code: 409, Message: err.Error(), Timestamp: time.Now().Unix()})
+			return
+		}
+		util.Logger.Printf("Maintenance window %s on %s ended by %s", window.ID, window.Database, auth.Actor(c))
+		auth.Audit(c, audit.Record{Action: audit.ActionMaintenanceEnd, Target: window.Database,
+			Details: maintenanceDetails(window)})
+		c.JSON(http.StatusOK, models.ApiResponse{Code: 200, Data: window, Message: "success", Timestamp: time.Now().Unix()})
+	})
+
+	// --- Immediate check cycle ---
+	recheck.POST("/api/recheck", func(c *gin.Context) {
+		handlers.TriggerCollection()
+		util.Logger.Printf("Check cycle triggered by %s", auth.Actor(c))
+		auth.Audit(c, audit.Record{Action: audit.ActionRecheck})
+		c.JSON(http.StatusAccepted, models.ApiResponse{Code: 202, Message: "check cycle triggered", Timestamp: time.Now().Unix()})
+	})
+
+	// --- Configuration reload, for hosts where file change notifications do not arrive ---
+	reload.POST("/api/admin/reload", func(c *gin.Context) {
+		changes, err := reloadConfig(configFile)
+		if err != nil {
+			util.Logger.Printf("Configuration reload by %s failed: %v", auth.Actor(c), err)
+			auth.Audit(c, audit.Record{Action: audit.ActionConfigReload, Outcome: audit.OutcomeFailure, Details: map[string]string{"error": err.Error()}})
+			c.JSON(http.StatusInternalServerError, models.ApiResponse{Code: 500, Message: err.Error(), Timestamp: time.Now().Unix()})
+			return
+		}
+		util.Logger.Printf("Configuration reloaded by %s", auth.Actor(c))
+		auth.Audit(c, audit.Record{Action: audit.ActionConfigReload, Changes: changes})
+		c.JSON(http.StatusOK, models.ApiResponse{Code: 200, Data: changes, Message: "configuration reloaded", Timestamp: time.Now().Unix()})
+	})
+
+	// --- Audit log ---
+	auditors.GET("/api/admin/audit", func(c *gin.Context) {
+		filter := audit.Filter{
+			Actor:  c.Query("actor"),
+			Action: c.Query("action"),
+			Target: c.Query("target"),
+			Limit:  200,
+		}
+		var err error
+		if filter.From, err = timeFromQuery(c, "from"); err == nil {
+			filter.To, err = timeFromQuery(c, "to")
+		}
+		if err == nil && c.Query("limit") != "" {
+			if filter.Limit, err = strconv.Atoi(c.Query("limit")); err != nil || filter.Limit < 0 {
+				err = fmt.Errorf("invalid limit '%s'", c.Query("limit"))
+			}
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ApiResponse{Code: 400, Message: err.Error(), Timestamp: time.Now().Unix()})
+			return
+		}
+		records, err := audit.Query(filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ApiResponse{Code: 500, Message: err.Error(), Timestamp: time.Now().Unix()})
+			return
+		}
+		c.JSON(http.StatusOK, models.ApiResponse{Code: 200, Data: records, Message: "success", Timestamp: time.Now().Unix()})
+	})
+
+	// --- SLA report ---
+	view.GET("/api/reports/sla", func(c *gin.Context) {
+		now := time.Now()
+		period, err := periodFromQuery(c, "last-month", now)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ApiResponse{Code: 400, Message: err.Error(), Timestamp: now.Unix()})
+			return
+		}
+		format := c.DefaultQuery("format", "json")
+		if format != "json" && format != "csv" && format != "html" {
+			c.JSON(http.StatusBadRequest, models.ApiResponse{Code: 400, Message: fmt.Sprintf("unsupported format '%s' (expected json, csv or html)", format), Timestamp: now.Unix()})
+			return
+		}
+		sla, err := report.ComputeSLA(store, models.GetConfig(), filterFromQuery(c), period, now)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ApiResponse{Code: 500, Message: err.Error(), Timestamp: now.Unix()})
+			return
+		}
+		switch format {
+		case "csv":
+			c.Header("Content-Type", "text/csv; charset=utf-8")
+			c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="sla-%s.csv"`, period.From.Format("2006-01-02")))
+			err = report.WriteCSV(c.Writer, sla, translator(c))
+		case "html":
+			c.Header("Content-Type", "text/html; charset=utf-8")
+			err = report.WriteHTML(c.Writer, sla, translator(c))
+		default:
+			c.JSON(http.StatusOK, models.ApiResponse{Code: 200, Data: sla, Message: "success", Timestamp: now.Unix()})
+		}
+		if err != nil {
+			util.Logger.Printf("Failed to write SLA report: %v", err)
+		}
+	})
+
+	// Status report as generated by reports.schedules, on demand.
+	view.GET("/api/reports/status", func(c *gin.Context) {
+		now := time.Now()
+		period, err := periodFromQuery(c, "7d", now)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ApiResponse{Code: 400, Message: err.Error(), Timestamp: now.Unix()})
+			return
+		}
+		format := c.DefaultQuery("format", "html")
+		if format != "json" && format != models.ReportFormatHTML && format != models.ReportFormatPDF {
+			c.JSON(http.StatusBadRequest, models.ApiResponse{Code: 400, Message: fmt.Sprintf("unsupported format '%s' (expected html, pdf or json)", format), Timestamp: now.Unix()})
+			return
+		}
+		filter := filterFromQuery(c)
+		statuses, collectedAt, ok := handlers.LatestStatus(filter)
+		if !ok {
+			c.JSON(http.StatusServiceUnavailable, models.ApiResponse{Code: 503, Message: "no check cycle has completed yet", Timestamp: now.Unix()})
+			return
+		}
+		status, err := report.BuildStatus(store, models.GetConfig(), filter, statuses, collectedAt, period, now)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ApiResponse{Code: 500, Message: err.Error(), Timestamp: now.Unix()})
+			return
+		}
+		switch format {
+		case models.ReportFormatPDF:
+			c.Header("Content-Type", "application/pdf")
+			c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="status-%s.pdf"`, now.Format("20060102-1504")))
+			err = report.WriteStatusPDF(c.Writer, status, translator(c), requestLanguage(c))
+		case models.ReportFormatHTML:
+			c.Header("Content-Type", "text/html; charset=utf-8")
+			err = report.WriteStatusHTML(c.Writer, status, translator(c), requestLanguage(c))
+		default:
+			c.JSON(http.StatusOK, models.ApiResponse{Code: 200, Data: status, Message: "success", Timestamp: now.Unix()})
+		}
+		if err != nil {
+			util.Logger.Printf("Failed to write status report: %v", err)
+		}
+	})
+
+	// --- Tabular export of the latest statuses, the check history and events ---
+	view.GET("/api/export", func(c *gin.Context) {
+		now := time.Now()
+		format := c.DefaultQuery("format", report.ExportCSV)
+		contentType := report.ExportContentType(format)
+		if contentType == "" {
+			c.JSON(http.StatusBadRequest, models.ApiResponse{Code: 400, Message: fmt.Sprintf("unsupported format '%s' (expected csv, jsonl or xlsx)", format), Timestamp: now.Unix()})
+			return
+		}
+		data := c.DefaultQuery("data", report.ExportStatusData)
+		if data != report.ExportStatusData && data != report.ExportHistoryData && data != report.ExportEventsData {
+			c.JSON(http.StatusBadRequest, models.ApiResponse{Code: 400, Message: fmt.Sprintf("unsupported data '%s' (expected status, history or events)", data), Timestamp: now.Unix()})
+			return
+		}
+		period, err := periodFromQuery(c, "1d", now)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ApiResponse{Code: 400, Message: err.Error(), Timestamp: now.Unix()})
+			return
+		}
+		filter := filterFromQuery(c)
+		statuses, collectedAt, ok := handlers.LatestStatus(filter)
+		if data == report.ExportStatusData && !ok {
+			c.JSON(http.StatusServiceUnavailable, models.ApiResponse{Code: 503, Message: "no check cycle has completed yet", Timestamp: now.Unix()})
+			return
+		}
+
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.%s"`, data, now.Format("20060102-1504"), format))
+		switch data {
+		case report.ExportHistoryData:
+			err = report.ExportHistory(c.Writer, format, store, models.GetConfig(), filter, period.From, period.To, translator(c))
+		case report.ExportEventsData:
+			err = report.ExportEvents(c.Writer, format, store, models.GetConfig(), filter, period.From, period.To, translator(c))
+		default:
+			err = report.ExportStatus(c.Writer, format, statuses, collectedAt, translator(c))
+		}
+		if err != nil {
+			// The status line has been sent with the first rows; the client sees a truncated file.
+			util.Logger.Printf("Failed to export %s: %v", data, err)
+		}
+	})
+
 	// --- Static File Serving Setup ---
 
 	// *** Modified handler for the root "/" ***
-	router.GET("/", func(c *gin.Context) {
+	view.GET("/", func(c *gin.Context) {
 		currentConfig := models.GetConfig()
 		// 1. Determine Base Tag
 		baseTag := ""
@@ -177,10 +818,14 @@ func Run(staticFS, localeFS fs.FS, configFile string) {
 			BasePath string                  `json:"basePath"`
 			Layout   models.LayoutConfig     `json:"layout"`
 			Frontend models.FrontendSettings `json:"frontend"`
+			Groups   []string                `json:"groups"`
+			User     *auth.Principal         `json:"user,omitempty"`
 		}{
 			BasePath: currentConfig.Server.PublicBasePath,
 			Layout:   currentConfig.Layout,
 			Frontend: currentConfig.Frontend,
+			Groups:   currentConfig.GroupsOf(models.DatabaseFilter{Scope: auth.Scope(c)}),
+			User:     auth.Current(c),
 		}
 		configJSON, err := json.Marshal(frontendConfig)
 		if err != nil {
@@ -219,7 +864,9 @@ func Run(staticFS, localeFS fs.FS, configFile string) {
 
 	// Serve other specific root files using StaticFileFS
 	router.StaticFileFS("/favicon.ico", "favicon.ico", http.FS(staticFS))
-	router.StaticFileFS("/dashboard.html", "dashboard.html", http.FS(staticFS))
+	view.StaticFileFS("/dashboard.html", "dashboard.html", http.FS(staticFS))
+	diagnostics.StaticFileFS("/diagnostics", "diagnostics.html", http.FS(staticFS))
+	router.StaticFileFS("/login", "login.html", http.FS(staticFS))
 
 	// Middleware to handle language selection from URL query parameter
 	router.Use(func(c *gin.Context) {
@@ -235,19 +882,9 @@ func Run(staticFS, localeFS fs.FS, configFile string) {
 	// Serve assets under the '/static' path using StaticFS
 	router.StaticFS("/static", http.FS(staticFS))
 
-	// --- Server Startup (logging remains the same) ---
-	port := models.GetConfig().Server.Port
-	if port == "" {
-		port = "8080"
-	}
-	util.Logger.Printf("Server started, listening on port: %s\n", port)
-	currentConfig := models.GetConfig()
-	util.Logger.Printf("Public base path: %s\n", currentConfig.Server.PublicBasePath)
-	util.Logger.Printf("Access URL: http://localhost:%s%s\n", port, currentConfig.Server.PublicBasePath)
-	fmt.Printf("Server started, listening on port: %s\n", port)
-	fmt.Printf("Access URL: http://localhost:%s%s\n", port, currentConfig.Server.PublicBasePath)
-
-	err = router.Run(":" + port)
+	// --- Server Startup ---
+	// Listener settings are read once; changing them needs a restart.
+	err = listenAndServe(router, models.GetConfig().Server)
 	if err != nil {
 		util.Logger.Fatalf("Failed to start server: %v", err)
 	}
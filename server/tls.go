@@ -0,0 +1,143 @@
+package server
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/util"
+)
+
+// reloadDelay is how long certificate files must be unchanged before they are reloaded.
+const reloadDelay = time.Second
+
+// certStore holds the server certificate and client CA pool currently in use.
+// Both are replaced when their files change; a failed reload keeps the previous ones.
+type certStore struct {
+	cfg models.TLSConfig
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+	caPEM    []byte
+}
+
+// newTLSConfig loads the certificate files and returns a TLS configuration that
+// always uses the latest successfully loaded ones.
+func newTLSConfig(cfg models.TLSConfig) (*tls.Config, error) {
+	store := &certStore{cfg: cfg}
+	if _, err := store.load(); err != nil {
+		return nil, err
+	}
+	go store.watch()
+
+	minVersion := uint16(tls.VersionTLS12)
+	if cfg.MinVersion == "1.3" {
+		minVersion = tls.VersionTLS13
+	}
+	// The configuration returned for each client replaces the one net/http
+	// prepared, so it has to offer HTTP/2 itself.
+	nextProtos := []string{"h2", "http/1.1"}
+	base := &tls.Config{MinVersion: minVersion, NextProtos: nextProtos}
+	return &tls.Config{
+		MinVersion: minVersion,
+		NextProtos: nextProtos,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			store.mu.RLock()
+			defer store.mu.RUnlock()
+			config := base.Clone()
+			config.Certificates = []tls.Certificate{*store.cert}
+			if store.clientCA != nil {
+				config.ClientCAs = store.clientCA
+				config.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return config, nil
+		},
+	}, nil
+}
+
+// load reads the key pair and the client CA bundle. It reports whether either
+// differs from what was in use.
+func (s *certStore) load() (bool, error) {
+	cert, err := tls.LoadX509KeyPair(s.cfg.CertFile, s.cfg.KeyFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	var pool *x509.CertPool
+	var caPEM []byte
+	if s.cfg.ClientCAFile != "" {
+		if caPEM, err = os.ReadFile(s.cfg.ClientCAFile); err != nil {
+			return false, fmt.Errorf("failed to read client CA bundle: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return false, fmt.Errorf("no certificates found in client CA bundle '%s'", s.cfg.ClientCAFile)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := s.cert == nil || !bytes.Equal(s.cert.Certificate[0], cert.Certificate[0]) || !bytes.Equal(s.caPEM, caPEM)
+	s.cert, s.clientCA, s.caPEM = &cert, pool, caPEM
+	return changed, nil
+}
+
+// watch reloads the files when anything changes in their directories. Watching
+// the directories rather than the files also catches renewals that replace the
+// files or swap a symlink, as certbot and Kubernetes secret mounts do.
+func (s *certStore) watch() {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		util.Logger.Printf("Failed to watch TLS certificate files, renewals need a restart: %v", err)
+		return
+	}
+	defer watcher.Close()
+
+	for _, file := range []string{s.cfg.CertFile, s.cfg.KeyFile, s.cfg.ClientCAFile} {
+		if file == "" {
+			continue
+		}
+		if err := watcher.Add(filepath.Dir(file)); err != nil {
+			util.Logger.Printf("Failed to watch '%s' for certificate renewals: %v", filepath.Dir(file), err)
+		}
+	}
+
+	// A renewal writes the certificate and key one after the other, so the files
+	// are loaded once they have been quiet for reloadDelay.
+	reload := time.NewTimer(time.Hour)
+	reload.Stop()
+	var changedFile string
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Chmod) {
+				continue
+			}
+			changedFile = event.Name
+			reload.Reset(reloadDelay)
+		case <-reload.C:
+			changed, err := s.load()
+			if err != nil {
+				util.Logger.Printf("TLS certificate not reloaded after change of %s, keeping the previous one: %v", changedFile, err)
+				continue
+			}
+			if changed {
+				util.Logger.Printf("TLS certificate reloaded after change of %s", changedFile)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			util.Logger.Printf("Certificate watcher error: %v", err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/util"
+)
+
+// writeCertificate writes a self-signed certificate for localhost with the
+// given serial number and its key.
+func writeCertificate(t *testing.T, certFile, keyFile string, serial int64) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644); err != nil {
+		t.Fatalf("write certificate: %v", err)
+	}
+}
+
+// handshake connects to addr offering HTTP/2 and HTTP/1.1 and returns the
+// negotiated protocol and the serial number of the server certificate.
+func handshake(t *testing.T, addr string) (string, int64) {
+	t.Helper()
+	conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true, NextProtos: []string{"h2", "http/1.1"}})
+	if err != nil {
+		t.Fatalf("TLS handshake: %v", err)
+	}
+	defer conn.Close()
+	state := conn.ConnectionState()
+	return state.NegotiatedProtocol, state.PeerCertificates[0].SerialNumber.Int64()
+}
+
+func TestTLSNegotiatesHTTP2AcrossReloads(t *testing.T) {
+	util.Logger = log.New(io.Discard, "", 0)
+	dir := t.TempDir()
+	cfg := models.TLSConfig{CertFile: filepath.Join(dir, "tls.crt"), KeyFile: filepath.Join(dir, "tls.key")}
+	writeCertificate(t, cfg.CertFile, cfg.KeyFile, 1)
+
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		t.Fatalf("newTLSConfig: %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := &http.Server{
+		Handler:   http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, r.Proto) }),
+		TLSConfig: tlsConfig,
+	}
+	go server.ServeTLS(listener, "", "")
+	t.Cleanup(func() { server.Close() })
+	addr := listener.Addr().String()
+
+	if proto, serial := handshake(t, addr); proto != "h2" || serial != 1 {
+		t.Fatalf("got protocol %q with certificate %d, want h2 with certificate 1", proto, serial)
+	}
+
+	writeCertificate(t, cfg.CertFile, cfg.KeyFile, 2)
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		proto, serial := handshake(t, addr)
+		if serial == 2 {
+			if proto != "h2" {
+				t.Fatalf("got protocol %q after the certificate reload, want h2", proto)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the renewed certificate was not picked up")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, ForceAttemptHTTP2: true}}
+	resp, err := client.Get("https://" + addr + "/")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+	if body, _ := io.ReadAll(resp.Body); string(body) != "HTTP/2.0" {
+		t.Errorf("request was served over %s, want HTTP/2.0", body)
+	}
+}
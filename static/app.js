@@ -4,6 +4,9 @@ const domCache = {};
 // Helper function to get language from URL, defaulting to 'zh'
 const getLang = () => new URLSearchParams(window.location.search).get('lang') || 'zh';
 
+// Helper function to get the selected database group from URL
+const getGroup = () => new URLSearchParams(window.location.search).get('group') || '';
+
 // Helper function for translation with fallback
 function t(key) {
     return (window.I18N && window.I18N[key]) || key; // Fallback to the key itself if not found
@@ -95,9 +98,37 @@ function getApiUrl(endpoint) {
        effectiveBasePath += '/';
     }
     if (effectiveBasePath === '/' && cleanEndpoint.startsWith('/')) {
-         return cleanEndpoint;
+         return withKioskToken(cleanEndpoint);
+    }
+    return withKioskToken(effectiveBasePath + cleanEndpoint);
+}
+
+// Wall displays open the dashboard with ?kiosk_token=<token>; API calls pass the token on.
+function withKioskToken(url) {
+    const token = new URLSearchParams(window.location.search).get('kiosk_token');
+    if (!token) return url;
+    return `${url}${url.includes('?') ? '&' : '?'}kiosk_token=${encodeURIComponent(token)}`;
+}
+
+// Whether the signed-in user holds a permission; everything is allowed without authentication.
+function hasPermission(permission) {
+    const user = window.APP_CONFIG && window.APP_CONFIG.user;
+    return !user || (user.permissions || []).includes(permission);
+}
+
+// Show the signed-in user with a logout link when authentication is enabled.
+function renderUserInfo() {
+    const user = window.APP_CONFIG && window.APP_CONFIG.user;
+    const container = document.getElementById('user-info');
+    if (!user || !container) return;
+    container.textContent = user.name;
+    if (user.method === 'password' || user.method === 'oidc') {
+        const logout = document.createElement('a');
+        logout.href = getApiUrl('auth/logout');
+        logout.textContent = t('logoutLabel');
+        container.appendChild(logout);
     }
-    return effectiveBasePath + cleanEndpoint;
+    container.style.display = '';
 }
 
 // Format time
@@ -168,9 +199,19 @@ async function fetchAndRenderData() {
                 const urlParams = new URLSearchParams(window.location.search);
         const useMockData = urlParams.get('mock') === 'true';
         const lang = getLang();
-        const dataUrl = useMockData ? `api/mock-data?lang=${lang}` : 'api/data';
+        const query = new URLSearchParams();
+        if (useMockData) query.set('lang', lang);
+        if (getGroup()) query.set('group', getGroup());
+        urlParams.getAll('tag').forEach(tag => query.append('tag', tag));
+        const queryString = query.toString();
+        const dataUrl = (useMockData ? 'api/mock-data' : 'api/data') + (queryString ? `?${queryString}` : '');
 
         const response = await fetch(getApiUrl(dataUrl));
+        if (response.status === 401) {
+            // Session expired: sign in again and come back here.
+            window.location.href = getApiUrl(`login?next=${encodeURIComponent(window.location.pathname + window.location.search)}`);
+            return;
+        }
         const result = await response.json();
 
         if (result.code === 200) {
@@ -178,6 +219,9 @@ async function fetchAndRenderData() {
             if (useMockData && result.titles) {
                 updateTitles(result.titles);
             }
+            if (useMockData) {
+                addGroupOptions(result.data.map(db => db.group));
+            }
             render(result.data);
         } else {
             showError(result.message || 'Failed to fetch data');
@@ -189,6 +233,20 @@ async function fetchAndRenderData() {
 }
 
 function render(data) {
+    detailState.lastData = data;
+    if (detailState.name) {
+        renderDetail();
+    }
+    const kiosk = getKioskSettings();
+    if (kiosk.enabled) {
+        kioskState.lastData = data;
+        renderKioskPage();
+        return;
+    }
+    renderDatabases(data);
+}
+
+function renderDatabases(data) {
     // --- Dynamic Layout ---
     const dbCount = data.length;
     if (dbCount > 4) { // When there are more than 4 databases, apply wide-screen layout
@@ -218,6 +276,173 @@ function render(data) {
     });
 }
 
+// --- Kiosk Mode (paginated, rotating wall display) ---
+const kioskState = {
+    pageIndex: 0,
+    pageKey: null,
+    timer: null,
+    lastData: [],
+};
+
+// Kiosk settings come from the layout config and can be overridden per screen via URL params:
+// ?kiosk=1&page_size=8&page_by=group&rotate=20&problems=pin
+function getKioskSettings() {
+    const params = new URLSearchParams(window.location.search);
+    const cfg = (window.APP_CONFIG && window.APP_CONFIG.layout && window.APP_CONFIG.layout.kiosk) || {};
+    const enabled = params.has('kiosk') ? !['0', 'false'].includes(params.get('kiosk')) : !!cfg.enabled;
+    return {
+        enabled: enabled,
+        pageSize: parseInt(params.get('page_size'), 10) || cfg.page_size || 8,
+        pageBy: params.get('page_by') || cfg.page_by || 'count',
+        rotateSeconds: parseInt(params.get('rotate'), 10) || cfg.rotate_seconds || 30,
+        problemPages: params.get('problems') || cfg.problem_pages || 'first',
+    };
+}
+
+// A database has a problem when the backend health verdict is anything but OK
+// or MAINTENANCE, which the backend reports when nothing outside a maintenance window is wrong.
+function hasProblem(db) {
+    return !db.health || !['OK', 'MAINTENANCE'].includes(db.health.level);
+}
+
+// Split the databases into pages, either by fixed count or by group (large groups are split further).
+function paginate(data, settings) {
+    const chunks = [];
+    const pushChunks = (keyPrefix, label, dbs) => {
+        const total = Math.max(1, Math.ceil(dbs.length / settings.pageSize));
+        for (let i = 0; i < total; i++) {
+            chunks.push({
+                key: `${keyPrefix}#${i}`,
+                label: total > 1 && label ? `${label} (${i + 1}/${total})` : label,
+                dbs: dbs.slice(i * settings.pageSize, (i + 1) * settings.pageSize),
+            });
+        }
+    };
+
+    if (settings.pageBy === 'group') {
+        const groups = new Map();
+        data.forEach(db => {
+            const group = db.group || '';
+            if (!groups.has(group)) groups.set(group, []);
+            groups.get(group).push(db);
+        });
+        Array.from(groups.keys()).sort().forEach(group => {
+            pushChunks(`group:${group}`, group || t('ungrouped'), groups.get(group));
+        });
+    } else {
+        pushChunks('page', '', data);
+    }
+
+    chunks.forEach(page => { page.hasProblem = page.dbs.some(hasProblem); });
+
+    if (settings.problemPages === 'first' || settings.problemPages === 'pin') {
+        // Stable sort: pages with problems first, original order otherwise.
+        chunks.sort((a, b) => Number(b.hasProblem) - Number(a.hasProblem));
+    }
+    if (settings.problemPages === 'pin' && chunks.some(page => page.hasProblem)) {
+        // Only rotate among the pages with problems until they are resolved.
+        return chunks.filter(page => page.hasProblem);
+    }
+    return chunks;
+}
+
+// Render the current kiosk page from the most recent data. The page is tracked by key,
+// so a data refresh that reorders pages keeps showing the same page.
+function renderKioskPage() {
+    const settings = getKioskSettings();
+    const pages = paginate(kioskState.lastData, settings);
+
+    let index = pages.findIndex(page => page.key === kioskState.pageKey);
+    if (index < 0) {
+        index = pages.length > 0 ? kioskState.pageIndex % pages.length : 0;
+    }
+    kioskState.pageIndex = index;
+    kioskState.pageKey = pages.length > 0 ? pages[index].key : null;
+
+    renderDatabases(pages.length > 0 ? pages[index].dbs : []);
+    renderPageIndicator(pages, index);
+}
+
+function renderPageIndicator(pages, activeIndex) {
+    if (!domCache.pageIndicator) return;
+    domCache.pageIndicator.innerHTML = '';
+    domCache.pageIndicator.style.display = pages.length > 1 ? 'flex' : 'none';
+    if (pages.length <= 1) return;
+
+    const label = document.createElement('div');
+    label.className = 'page-label';
+    const pageText = `${t('pageLabel')} ${activeIndex + 1}/${pages.length}`;
+    label.textContent = pages[activeIndex].label ? `${pageText} · ${pages[activeIndex].label}` : pageText;
+    domCache.pageIndicator.appendChild(label);
+
+    const dots = document.createElement('div');
+    dots.className = 'page-dots';
+    pages.forEach((page, i) => {
+        const dot = document.createElement('span');
+        dot.className = 'page-dot';
+        if (i === activeIndex) dot.classList.add('active');
+        if (page.hasProblem) dot.classList.add('problem');
+        dot.title = page.label || `${i + 1}`;
+        dots.appendChild(dot);
+    });
+    domCache.pageIndicator.appendChild(dots);
+}
+
+function startKioskRotation() {
+    const settings = getKioskSettings();
+    if (!settings.enabled) return;
+    if (kioskState.timer) clearInterval(kioskState.timer);
+    kioskState.timer = setInterval(() => {
+        const pages = paginate(kioskState.lastData, settings);
+        if (pages.length <= 1) return;
+        const current = pages.findIndex(page => page.key === kioskState.pageKey);
+        const next = (current + 1) % pages.length;
+        kioskState.pageIndex = next;
+        kioskState.pageKey = pages[next].key;
+        renderKioskPage();
+    }, settings.rotateSeconds * 1000);
+}
+
+// --- Group Selector ---
+function initGroupSelector() {
+    if (!domCache.groupSelect) return;
+
+    const allOption = document.createElement('option');
+    allOption.value = '';
+    allOption.textContent = t('allGroups');
+    domCache.groupSelect.appendChild(allOption);
+
+    addGroupOptions((window.APP_CONFIG && window.APP_CONFIG.groups) || []);
+    addGroupOptions([getGroup()]);
+    domCache.groupSelect.value = getGroup();
+
+    domCache.groupSelect.addEventListener('change', () => {
+        const params = new URLSearchParams(window.location.search);
+        if (domCache.groupSelect.value) {
+            params.set('group', domCache.groupSelect.value);
+        } else {
+            params.delete('group');
+        }
+        const queryString = params.toString();
+        window.history.replaceState(null, '', window.location.pathname + (queryString ? `?${queryString}` : ''));
+        fetchAndRenderData();
+    });
+}
+
+// Add any groups not yet present to the selector; the selector stays hidden until there is a group to choose.
+function addGroupOptions(groups) {
+    if (!domCache.groupSelect) return;
+    const existing = new Set(Array.from(domCache.groupSelect.options).map(option => option.value));
+    groups.filter(group => group && !existing.has(group)).sort().forEach(group => {
+        const option = document.createElement('option');
+        option.value = group;
+        option.textContent = group;
+        domCache.groupSelect.appendChild(option);
+        existing.add(group);
+    });
+    domCache.groupFilter.style.display = domCache.groupSelect.options.length > 1 ? 'flex' : 'none';
+}
+
 function showError(message) {
     console.error(message);
     const errorMessage = `<div class="error-message">${t('dataLoadError')}: ${message}</div>`;
@@ -243,27 +468,72 @@ function dbCardTemplate(db, type) {
         connections: isProduction ? db.connections : null,
     };
 
-    const targetEnv = determineLoadBalancerTarget(db);
+    const health = (isProduction ? db.production_health : db.disaster_health) || { level: 'UNKNOWN', reasons: [] };
+    const targetEnv = lbTargetKey(db.load_balancer_target);
     const isTargetOfLB = (isProduction && targetEnv === 'targetProd') || (!isProduction && targetEnv === 'targetDR');
 
     // --- Set Content ---
     card.querySelector('.db-name-text').textContent = db.name;
+    if (db.tier) {
+        const tierBadge = card.querySelector('.db-tier');
+        tierBadge.textContent = db.tier;
+        tierBadge.title = [db.group, ...(db.tags || [])].filter(Boolean).join(', ');
+        tierBadge.style.display = 'inline-block';
+    }
+    if (db.drift && db.drift.differences.length > 0) {
+        const driftBadge = card.querySelector('.drift-badge');
+        driftBadge.textContent = `${t('driftBadge')} ${db.drift.differences.length}`;
+        driftBadge.title = db.drift.differences.slice(0, 10)
+            .map(item => `${item.key}: ${item.production} ≠ ${item.disaster}`).join('\n');
+        driftBadge.style.display = 'inline-block';
+    }
+    if (db.nologging && (db.nologging.unacknowledged || (db.nologging.nonlogged_blocks && db.nologging.nonlogged_blocks.blocks > 0))) {
+        const nologgingBadge = card.querySelector('.nologging-badge');
+        nologgingBadge.textContent = t('nologgingBadge');
+        nologgingBadge.title = nologgingSummary(db.nologging);
+        nologgingBadge.style.display = 'inline-block';
+    }
+    const maintenance = (db.maintenance || []).filter(period => !period.member || period.member === type);
+    if (maintenance.length > 0) {
+        card.classList.add('maintenance');
+        const maintenanceBadge = card.querySelector('.maintenance-badge');
+        maintenanceBadge.textContent = t('maintenanceBadge');
+        maintenanceBadge.title = maintenance.map(maintenanceSummary).join('\n');
+        maintenanceBadge.style.display = 'inline-block';
+    }
+    const acks = (db.acknowledgements || []).filter(ack => !ack.member || ack.member === type);
+    if (acks.length > 0) {
+        const ackBadge = card.querySelector('.ack-badge');
+        ackBadge.textContent = t('ackBadge');
+        ackBadge.title = acks.map(ackSummary).join('\n');
+        ackBadge.style.display = 'inline-block';
+    }
+    if (db.notes && db.notes.length > 0) {
+        const notes = card.querySelector('.card-notes');
+        notes.textContent = db.notes.map(note => note.text).join(' · ');
+        notes.title = db.notes.map(noteSummary).join('\n');
+        notes.style.display = 'block';
+    }
     card.querySelector('.ip').textContent = data.ip;
     card.querySelector('.role-item').innerHTML = `${t('roleLabel')}: ${t(data.role)}`;
     card.querySelector('.overall-status-text').textContent = t(data.status);
 
     // --- Set Status Classes ---
-    card.querySelector('.ping-status').classList.add(data.alive ? 'status-online' : 'status-offline');
+    const pingStatus = card.querySelector('.ping-status');
+    pingStatus.classList.add(data.alive ? 'status-online' : 'status-offline');
+    pingStatus.title = pingTooltip(isProduction ? db.production_ping : db.disaster_ping);
     card.querySelector('.port-status').classList.add(data.portAlive ? 'status-online' : 'status-offline');
     card.querySelector('.db-connect-status').classList.add(data.dbConnect ? 'status-online' : 'status-offline');
 
-    let overallStatusClass = 'status-offline';
-    if (data.alive && data.portAlive && data.dbConnect) {
-        overallStatusClass = data.status === 'Warning' ? 'status-warning' : 'status-online';
-    } else if (data.alive || data.portAlive) {
-        overallStatusClass = 'status-warning';
+    const overallStatus = card.querySelector('.overall-status');
+    overallStatus.classList.add(healthClass(health.level));
+    overallStatus.title = healthTooltip(health);
+    const memberError = isProduction ? db.production_error : db.disaster_error;
+    if (memberError) {
+        const errorText = errorTooltip(memberError);
+        overallStatus.title += `\n${errorText}`;
+        card.querySelector('.db-connect-status').title = `DB Connectivity\n${errorText}`;
     }
-    card.querySelector('.overall-status').classList.add(overallStatusClass);
 
     // --- Conditional Rendering ---
     if (isTargetOfLB) {
@@ -284,19 +554,133 @@ function dbCardTemplate(db, type) {
         if (data.delay > 60) delayClass = 'error-color';
         else if (data.delay > 5) delayClass = 'warning-color';
         delayItem.innerHTML = `${t('delayLabel')}: <span style="color: var(--${delayClass})">${data.delay}s</span>`;
+        const heartbeat = db.disaster_heartbeat;
+        if (heartbeat) {
+            delayItem.innerHTML += heartbeat.error
+                ? ` / ${t('heartbeatLabel')}: <span style="color: var(--error-color)">?</span>`
+                : ` / ${t('heartbeatLabel')}: ${heartbeat.lag_seconds.toFixed(1)}s`;
+            delayItem.title = heartbeatTooltip(heartbeat);
+        }
     } else {
         card.querySelector('.delay-item').innerHTML = '&nbsp;';
     }
 
+    renderSpaceGauge(card.querySelector('.space-item'), isProduction ? db.production_space : db.disaster_space);
+    renderPDBList(card.querySelector('.pdb-list'), db, type);
+
+    card.addEventListener('click', () => openDetail(db.name));
+
     return card;
 }
 
+// Fill the recovery area gauge of a card; the level comes from the backend thresholds.
+function renderSpaceGauge(item, space) {
+    if (!space || !space.recovery_area) {
+        return;
+    }
+    const fra = space.recovery_area;
+    const percent = Math.max(0, Math.min(100, fra.used_percent));
+    const fill = item.querySelector('.space-gauge-fill');
+    fill.style.width = `${percent}%`;
+    fill.classList.add(healthClass(space.level));
+    item.querySelector('.space-text').textContent = `${fra.used_percent.toFixed(0)}%`;
+
+    const lines = [
+        `${t('fraLabel')}: ${fra.name}`,
+        `${t('fraUsed')}: ${formatBytes(fra.used_bytes)} / ${formatBytes(fra.limit_bytes)}`,
+        `${t('fraReclaimable')}: ${formatBytes(fra.reclaimable_bytes)}`,
+    ];
+    (fra.file_types || []).filter(type => type.percent_used > 0).forEach(type => {
+        lines.push(`  ${type.file_type}: ${type.percent_used.toFixed(1)}% (${type.number_of_files})`);
+    });
+    if (fra.archive_bytes_per_hour !== undefined) {
+        lines.push(`${t('archiveRate')}: ${formatBytes(fra.archive_bytes_per_hour)}/h`);
+    }
+    if (fra.hours_to_full !== undefined) {
+        lines.push(`${t('timeToFull')}: ${fra.hours_to_full.toFixed(1)} h`);
+    }
+    (space.archive_dests || []).forEach(dest => {
+        const used = dest.used_percent !== undefined ? ` ${dest.used_percent.toFixed(0)}%` : '';
+        lines.push(`${dest.dest_name} (${dest.destination}): ${dest.status}${used}${dest.error ? ` - ${dest.error}` : ''}`);
+    });
+    item.title = lines.join('\n');
+    item.style.display = 'flex';
+}
+
+// PDB lists the user expanded, by database and member, so refreshes keep them open.
+const expandedPDBLists = new Set();
+
+// Fill the expandable PDB rows of a card with the PDBs of one member. Rows with
+// an issue on this member are highlighted; the tooltip names the issues.
+function renderPDBList(list, db, member) {
+    if (!db.pdbs || db.pdbs.pdbs.length === 0) {
+        return;
+    }
+    const key = `${db.name}|${member}`;
+    const issues = item => item.issues.filter(issue => issue.member === member);
+    const mismatches = db.pdbs.pdbs.filter(item => issues(item).length > 0).length;
+    const summary = list.querySelector('.pdb-summary');
+    summary.textContent = `PDB ${db.pdbs.pdbs.length}`;
+    if (mismatches > 0) {
+        const count = document.createElement('span');
+        count.className = 'pdb-mismatch-count';
+        count.textContent = ` · ${mismatches} ${t('pdbMismatches')}`;
+        summary.appendChild(count);
+    }
+
+    const tbody = list.querySelector('tbody');
+    db.pdbs.pdbs.forEach(item => {
+        const pdb = item[member];
+        const itemIssues = issues(item);
+        const tr = tbody.insertRow();
+        if (itemIssues.length > 0) {
+            tr.className = itemIssues.some(issue => issue.level === 'CRITICAL') ? 'error-text' : 'status-warning-text';
+            tr.title = itemIssues.map(issue => t(`health_${issue.code}`)).join('\n');
+        }
+        tr.insertCell().textContent = item.name;
+        tr.insertCell().textContent = pdb ? pdbModeText(pdb) : t('pdbMissing');
+    });
+
+    const error = db.pdbs[`${member}_error`];
+    if (error) {
+        list.title = errorTooltip(error);
+    }
+    list.open = expandedPDBLists.has(key);
+    list.addEventListener('toggle', () => {
+        if (list.open) expandedPDBLists.add(key);
+        else expandedPDBLists.delete(key);
+    });
+    // Expanding the rows must not open the detail view.
+    list.addEventListener('click', event => event.stopPropagation());
+    list.style.display = 'block';
+}
+
+// Describe the state of a PDB on one member, e.g. "READ ONLY, RESTRICTED".
+function pdbModeText(pdb) {
+    const parts = [pdb.open_mode];
+    if (pdb.restricted === 'YES') parts.push(t('pdbRestricted'));
+    if (pdb.recovery_status === 'DISABLED') parts.push(t('pdbRecoveryDisabled'));
+    return parts.join(', ');
+}
+
+// Format a byte count with a binary unit, e.g. "1.5 GB".
+function formatBytes(bytes) {
+    const units = ['B', 'KB', 'MB', 'GB', 'TB', 'PB'];
+    let value = bytes;
+    let unit = 0;
+    while (value >= 1024 && unit < units.length - 1) {
+        value /= 1024;
+        unit++;
+    }
+    return `${value.toFixed(unit === 0 ? 0 : 1)} ${units[unit]}`;
+}
+
 
 function lbItemTemplate(db) {
     const template = document.getElementById('lb-item-template').content.cloneNode(true);
     const item = template.querySelector('.lb-system');
 
-    const targetEnv = determineLoadBalancerTarget(db);
+    const targetEnv = lbTargetKey(db.load_balancer_target);
 
     // --- Set Content ---
     item.querySelector('.lb-name').textContent = db.name.split('数据库')[0];
@@ -304,7 +688,12 @@ function lbItemTemplate(db) {
     item.querySelector('.lb-ip-text').textContent = db.load_balancer_ip;
 
     // --- Set Status Classes ---
-    item.querySelector('.ping-status').classList.add(db.load_balancer_alive ? 'status-online' : 'status-offline');
+    if (db.load_balancer_error) {
+        item.querySelector('.db-connect-status').title = `DB Connectivity\n${errorTooltip(db.load_balancer_error)}`;
+    }
+    const pingStatus = item.querySelector('.ping-status');
+    pingStatus.classList.add(db.load_balancer_alive ? 'status-online' : 'status-offline');
+    pingStatus.title = pingTooltip(db.load_balancer_ping);
     item.querySelector('.port-status').classList.add(db.load_balancer_port_1521 ? 'status-online' : 'status-offline');
     item.querySelector('.db-connect-status').classList.add(db.load_balancer_db_connect ? 'status-online' : 'status-offline');
 
@@ -317,21 +706,451 @@ function lbItemTemplate(db) {
     }
     item.style.backgroundColor = bgColor;
 
+    // --- Actual routing seen through the VIP ---
+    if (db.load_balancer_routed_instance || db.load_balancer_routed_db_unique_name) {
+        item.title = `${t('lbRoutedTo')}: ${db.load_balancer_routed_db_unique_name} / ${db.load_balancer_routed_instance}@${db.load_balancer_routed_host} (${t(db.load_balancer_routed_role)})`;
+    }
+    const lbReasons = ((db.health && db.health.reasons) || []).filter(reason => reason.member === 'load_balancer' && reason.code.startsWith('LB_ROUTES_'));
+    if (lbReasons.length > 0) {
+        item.classList.add('lb-misrouted');
+        item.title = [item.title, ...lbReasons.map(reason => t(`health_${reason.code}`))].filter(Boolean).join('\n');
+    }
+
     return item;
 }
 
+// --- Database Detail View ---
+const detailState = {
+    name: null,
+    lastData: [],
+};
+
+function openDetail(name) {
+    detailState.name = name;
+    renderDetail();
+    domCache.detail.style.display = 'flex';
+}
+
+function closeDetail() {
+    detailState.name = null;
+    domCache.detail.style.display = 'none';
+}
+
+// Render the detail view of the selected database from the latest data, so it stays current.
+function renderDetail() {
+    const db = detailState.lastData.find(item => item.name === detailState.name);
+    if (!db) {
+        closeDetail();
+        return;
+    }
+    domCache.detail.querySelector('.detail-title').textContent = db.name;
+    const body = domCache.detail.querySelector('.detail-body');
+    body.innerHTML = '';
+
+    const health = db.health || { level: 'UNKNOWN', reasons: [] };
+    const summary = document.createElement('div');
+    summary.className = 'detail-summary';
+    const icon = document.createElement('span');
+    icon.className = `status-icon ${healthClass(health.level)}`;
+    summary.appendChild(icon);
+    summary.appendChild(document.createTextNode(healthTooltip(health).split('\n').join(' · ')));
+    body.appendChild(summary);
+
+    body.appendChild(detailSection(t('membersLabel'),
+        ['', t('roleLabel'), t('statusLabel'), t('delayLabel'), t('heartbeatLabel'), t('fraLabel')],
+        [['production', 'targetProd'], ['disaster', 'targetDR']].map(([member, label]) => {
+            const heartbeat = db[`${member}_heartbeat`];
+            const space = db[`${member}_space`];
+            return [
+                `${t(label)} (${db[`${member}_ip`]})`,
+                t(db[`${member}_role`]),
+                t(db[`${member}_status`]),
+                db[`${member}_dgdelay`] >= 0 ? `${db[`${member}_dgdelay`]}s` : '-',
+                heartbeat ? (heartbeat.error ? errorTooltip(heartbeat.error) : `${heartbeat.lag_seconds.toFixed(1)}s`) : '-',
+                space && space.recovery_area ? `${space.recovery_area.used_percent.toFixed(0)}%` : '-',
+            ];
+        })));
+
+    if (db.health && db.health.reasons.length > 0) {
+        body.appendChild(alertsSection(db));
+    }
+    body.appendChild(notesSection(db));
+
+    if (db.maintenance && db.maintenance.length > 0) {
+        body.appendChild(detailSection(t('maintenanceLabel'),
+            [t('memberLabel'), t('maintenanceUntil'), t('maintenanceReason'), t('maintenanceOwner')],
+            db.maintenance.map(period => [
+                period.member ? t(period.member === 'production' ? 'targetProd' : 'targetDR') : t('maintenanceWhole'),
+                formatTime(new Date(period.end).getTime() / 1000),
+                period.reason || '-',
+                period.owner || '-',
+            ])));
+    }
+
+    if (db.backup) {
+        const rows = db.backup.types.map(type => {
+            const job = type.last_success;
+            return {
+                className: type.overdue || type.last_failure ? 'error-text' : '',
+                cells: [
+                    t(`backup_${type.type}`),
+                    job ? formatTime(new Date(job.end_time).getTime() / 1000) : t('backupNone'),
+                    job ? t(job.member === 'production' ? 'targetProd' : 'targetDR') : '-',
+                    job ? formatDuration(job.duration_seconds) : '-',
+                    job ? formatBytes(job.output_bytes) : '-',
+                    type.sla_hours > 0 ? `${type.age_hours !== undefined && type.age_hours !== null ? type.age_hours.toFixed(1) : '-'} / ${type.sla_hours} h` : '-',
+                    type.last_failure ? `${t('backupFailedAt')} ${formatTime(new Date(type.last_failure.end_time).getTime() / 1000)}` : (type.overdue ? t('backupOverdue') : 'OK'),
+                ],
+            };
+        });
+        [db.backup.production_error, db.backup.disaster_error].filter(Boolean).forEach(error => {
+            rows.push({ className: 'error-text', cells: [errorTooltip(error), '', '', '', '', '', ''] });
+        });
+        body.appendChild(detailSection(t('backupsLabel'),
+            [t('backupType'), t('backupLastSuccess'), t('memberLabel'), t('backupDuration'), t('backupSize'), t('backupAgeSla'), t('statusLabel')],
+            rows));
+    }
+
+    if (db.drift) {
+        body.appendChild(driftSection(db.drift));
+    }
+
+    if (db.nologging) {
+        body.appendChild(nologgingSection(db));
+    }
+
+    if (db.pdbs) {
+        const rows = db.pdbs.pdbs.map(item => ({
+            className: item.issues.length > 0 ? 'error-text' : '',
+            cells: [
+                item.name,
+                item.production ? pdbModeText(item.production) : t('pdbMissing'),
+                item.disaster ? pdbModeText(item.disaster) : t('pdbMissing'),
+                item.issues.map(issue => t(`health_${issue.code}`)).join('; '),
+            ],
+        }));
+        [db.pdbs.production_error, db.pdbs.disaster_error].filter(Boolean).forEach(error => {
+            rows.push({ className: 'error-text', cells: [errorTooltip(error), '', '', ''] });
+        });
+        body.appendChild(detailSection(t('pdbLabel'), ['PDB', t('targetProd'), t('targetDR'), t('statusLabel')], rows));
+    }
+}
+
+// Summarize the NOLOGGING state for the card badge tooltip.
+function nologgingSummary(nologging) {
+    const lines = [];
+    if (nologging.unacknowledged) {
+        lines.push(`${t('nologgingChange')}: ${nologging.latest_change} (${nologging.files.length} ${t('nologgingFiles')})`);
+    }
+    if (nologging.nonlogged_blocks && nologging.nonlogged_blocks.blocks > 0) {
+        lines.push(`${t('nologgingBlocks')}: ${nologging.nonlogged_blocks.blocks}`);
+    }
+    return lines.join('\n');
+}
+
+// Detail section with the unrecoverable datafiles of the primary, the non-logged
+// blocks of the standby and a button to acknowledge handled operations.
+function nologgingSection(db) {
+    const nologging = db.nologging;
+    const rows = [];
+    if (nologging.primary_member) {
+        rows.push([t('nologgingForceLogging'), nologging.force_logging || '-']);
+    }
+    rows.push([t('nologgingChange'), nologging.latest_change > 0
+        ? `${nologging.latest_change}${nologging.detected_at ? ` (${t('nologgingDetected')} ${formatTime(new Date(nologging.detected_at).getTime() / 1000)})` : ''}`
+        : t('nologgingNone')]);
+    nologging.files.forEach(file => {
+        rows.push({
+            className: 'error-text',
+            cells: [`#${file.file} ${file.name}`, `${file.change}${file.time ? ` · ${formatTime(new Date(file.time).getTime() / 1000)}` : ''}`],
+        });
+    });
+    if (nologging.standby_member) {
+        const blocks = nologging.nonlogged_blocks;
+        rows.push({
+            className: blocks && blocks.blocks > 0 ? 'error-text' : '',
+            cells: [t('nologgingBlocks'), blocks ? `${blocks.blocks} (${blocks.files} ${t('nologgingFiles')})` : t('nologgingUnavailable')],
+        });
+    }
+    const ack = nologging.acknowledgement;
+    if (ack) {
+        rows.push([t('nologgingAcknowledged'), `${ack.change} · ${ack.by} · ${formatTime(new Date(ack.time).getTime() / 1000)}${ack.comment ? ` · ${ack.comment}` : ''}`]);
+    }
+    [nologging.primary_error, nologging.standby_error].filter(Boolean).forEach(error => {
+        rows.push({ className: 'error-text', cells: [errorTooltip(error), ''] });
+    });
+
+    const section = detailSection(t('nologgingLabel'), [t('nologgingItem'), t('nologgingValue')], rows);
+    if (nologging.unacknowledged && hasPermission('acknowledge')) {
+        const button = document.createElement('button');
+        button.className = 'detail-action';
+        button.textContent = t('nologgingAcknowledge');
+        button.addEventListener('click', () => acknowledgeNologging(db.name));
+        section.appendChild(button);
+    }
+    return section;
+}
+
+// Acknowledge the NOLOGGING operations of a database and refresh the view.
+async function acknowledgeNologging(name) {
+    const comment = window.prompt(t('nologgingAckPrompt'), '');
+    if (comment === null) {
+        return;
+    }
+    try {
+        const response = await fetch(getApiUrl(`api/databases/${encodeURIComponent(name)}/nologging/ack`), {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ comment }),
+        });
+        const result = await response.json();
+        if (result.code !== 200) {
+            window.alert(result.message || 'Failed to acknowledge');
+            return;
+        }
+        await fetchAndRenderData();
+    } catch (error) {
+        console.error('Failed to acknowledge NOLOGGING operations:', error);
+        window.alert('Failed to acknowledge NOLOGGING operations.');
+    }
+}
+
+// Detail section listing the reasons behind the verdict, with their acknowledgements.
+function alertsSection(db) {
+    const canAck = hasPermission('acknowledge');
+    const reasons = db.health.reasons;
+    const ackOf = reason => (db.acknowledgements || []).find(ack => ack.code === reason.code && (ack.member || '') === (reason.member || ''));
+    const section = detailSection(t('alertsLabel'), [t('alertReason'), t('memberLabel'), t('statusLabel'), t('ackLabel'), ''],
+        reasons.map(reason => {
+            const ack = ackOf(reason);
+            return {
+                className: reason.level === 'CRITICAL' && !ack ? 'error-text' : '',
+                cells: [t(`health_${reason.code}`), memberName(reason.member), t(`health_${reason.level}`), ack ? ackSummary(ack) : '-', ''],
+            };
+        }));
+    if (canAck) {
+        section.querySelectorAll('tbody tr').forEach((tr, i) => {
+            const ack = ackOf(reasons[i]);
+            const button = document.createElement('button');
+            button.className = 'detail-action';
+            button.textContent = ack ? t('ackRemove') : t('ackButton');
+            button.addEventListener('click', () => ack
+                ? operatorRequest('DELETE', `api/databases/${encodeURIComponent(db.name)}/acks/${ack.id}`)
+                : acknowledgeAlert(db.name, reasons[i]));
+            tr.cells[tr.cells.length - 1].appendChild(button);
+        });
+    }
+    return section;
+}
+
+// Detail section listing the notes pinned to a database.
+function notesSection(db) {
+    const notes = db.notes || [];
+    const canEdit = hasPermission('acknowledge');
+    const section = detailSection(t('notesLabel'), [t('noteText'), t('ackBy'), t('ackExpires'), ''],
+        notes.map(note => [note.text, `${note.by} · ${formatTime(new Date(note.time).getTime() / 1000)}`,
+            note.expires ? formatTime(new Date(note.expires).getTime() / 1000) : '-', '']));
+    if (canEdit) {
+        section.querySelectorAll('tbody tr').forEach((tr, i) => {
+            const button = document.createElement('button');
+            button.className = 'detail-action';
+            button.textContent = t('noteRemove');
+            button.addEventListener('click', () => operatorRequest('DELETE', `api/databases/${encodeURIComponent(db.name)}/notes/${notes[i].id}`));
+            tr.cells[tr.cells.length - 1].appendChild(button);
+        });
+        const button = document.createElement('button');
+        button.className = 'detail-action';
+        button.textContent = t('notePin');
+        button.addEventListener('click', () => pinNote(db.name));
+        section.appendChild(button);
+    }
+    return section;
+}
+
+// Ask for a comment and an optional expiry, then acknowledge a reason.
+async function acknowledgeAlert(name, reason) {
+    const comment = window.prompt(t('ackPrompt'), '');
+    if (comment === null) {
+        return;
+    }
+    const minutes = window.prompt(t('expiryPrompt'), '');
+    if (minutes === null) {
+        return;
+    }
+    await operatorRequest('POST', `api/databases/${encodeURIComponent(name)}/acks`, {
+        code: reason.code, member: reason.member || '', comment, expires_minutes: parseInt(minutes, 10) || 0,
+    });
+}
+
+// Ask for the text and an optional expiry, then pin a note to a database.
+async function pinNote(name) {
+    const text = window.prompt(t('notePrompt'), '');
+    if (!text) {
+        return;
+    }
+    const minutes = window.prompt(t('expiryPrompt'), '');
+    if (minutes === null) {
+        return;
+    }
+    await operatorRequest('POST', `api/databases/${encodeURIComponent(name)}/notes`, { text, expires_minutes: parseInt(minutes, 10) || 0 });
+}
+
+// Send an acknowledgement or note change and refresh the view.
+async function operatorRequest(method, path, body) {
+    try {
+        const response = await fetch(getApiUrl(path), {
+            method,
+            headers: body ? { 'Content-Type': 'application/json' } : {},
+            body: body ? JSON.stringify(body) : undefined,
+        });
+        const result = await response.json();
+        if (result.code >= 300) {
+            window.alert(result.message || 'Request failed');
+            return;
+        }
+        await fetchAndRenderData();
+    } catch (error) {
+        console.error(`${method} ${path} failed:`, error);
+        window.alert('Request failed.');
+    }
+}
+
+// Describe an acknowledgement, e.g. "LAG_CRITICAL ✓ alice: rebuilding index (until 14:00)".
+function ackSummary(ack) {
+    const until = ack.expires ? ` (${t('maintenanceUntil')} ${formatTime(new Date(ack.expires).getTime() / 1000)})` : '';
+    return `${t(`health_${ack.code}`)} ✓ ${ack.by}${ack.comment ? `: ${ack.comment}` : ''}${until}`;
+}
+
+// Describe a pinned note with its author and time.
+function noteSummary(note) {
+    return `${note.text} — ${note.by}, ${formatTime(new Date(note.time).getTime() / 1000)}`;
+}
+
+// Translate the member of a reason; empty for database-wide reasons.
+function memberName(member) {
+    switch (member) {
+        case 'production':
+            return t('targetProd');
+        case 'disaster':
+            return t('targetDR');
+        case 'load_balancer':
+            return 'LB';
+        default:
+            return '-';
+    }
+}
+
+// Detail section listing configuration differences between the members.
+function driftSection(drift) {
+    const rows = drift.differences.map(item => [item.key, item.production, item.disaster]);
+    [drift.production_error, drift.disaster_error].filter(Boolean).forEach(error => {
+        rows.push({ className: 'error-text', cells: [errorTooltip(error), '', ''] });
+    });
+    if (rows.length === 0) {
+        rows.push([`${t('driftNone')} (${drift.compared})`, '', '']);
+    }
+    return detailSection(t('driftLabel'), [t('driftSetting'), t('targetProd'), t('targetDR')], rows);
+}
+
+// Build a titled table for the detail view. Rows are arrays of cell texts or
+// objects with "cells" and a "className" applied to the row.
+function detailSection(title, headers, rows) {
+    const section = document.createElement('div');
+    section.className = 'detail-section';
+    const heading = document.createElement('h3');
+    heading.textContent = title;
+    section.appendChild(heading);
+
+    const table = document.createElement('table');
+    table.className = 'page-table';
+    const headRow = table.createTHead().insertRow();
+    headers.forEach(header => {
+        const th = document.createElement('th');
+        th.textContent = header;
+        headRow.appendChild(th);
+    });
+    const tbody = table.createTBody();
+    rows.forEach(row => {
+        const tr = tbody.insertRow();
+        const cells = Array.isArray(row) ? row : row.cells;
+        if (row.className) tr.className = row.className;
+        cells.forEach(text => {
+            tr.insertCell().textContent = text;
+        });
+    });
+    section.appendChild(table);
+    return section;
+}
+
+// Format a number of seconds as e.g. "1h 05m" or "42s".
+function formatDuration(seconds) {
+    if (seconds < 60) {
+        return `${seconds}s`;
+    }
+    const hours = Math.floor(seconds / 3600);
+    const minutes = Math.floor((seconds % 3600) / 60);
+    return hours > 0 ? `${hours}h ${minutes.toString().padStart(2, '0')}m` : `${minutes}m`;
+}
+
 // --- Helper Functions ---
-function determineLoadBalancerTarget(db) {
-    if (!db.load_balancer_alive) {
-        return 'targetOffline';
-    }
-    if (db.production_alive && db.production_role === "PRIMARY") {
-        return 'targetProd';
-    } else if (db.disaster_alive && db.disaster_role === "PRIMARY") {
-        return 'targetDR';
-    } else {
-        return 'targetOffline';
+// Map the backend load balancer target to its translation key.
+function lbTargetKey(target) {
+    switch (target) {
+        case 'PRODUCTION':
+            return 'targetProd';
+        case 'DISASTER':
+            return 'targetDR';
+        default:
+            return 'targetOffline';
+    }
+}
+
+// Describe the ICMP measurements of a host; without them the check ran in tcp-only mode or failed.
+function pingTooltip(ping) {
+    if (!ping) {
+        return `Ping: ${t('pingUnavailable')}`;
+    }
+    return `Ping: ${ping.rtt_ms.toFixed(2)} ms, ${t('pingJitter')} ${ping.jitter_ms.toFixed(2)} ms, ${t('pingLoss')} ${ping.packet_loss.toFixed(0)}%`;
+}
+
+// Describe the heartbeat-table lag measurement, including the compensated clock skew.
+function heartbeatTooltip(heartbeat) {
+    if (heartbeat.error) {
+        return `${t('heartbeatLabel')}: ${errorTooltip(heartbeat.error)}`;
     }
+    return `${t('heartbeatLabel')}: ${heartbeat.lag_seconds.toFixed(3)} s\n${t('clockSkewLabel')}: ${heartbeat.clock_skew_seconds.toFixed(3)} s`;
+}
+
+// Describe an active maintenance window, e.g. "Until 2026-10-18 02:00:00 · Patching (dba)".
+function maintenanceSummary(period) {
+    const until = `${t('maintenanceUntil')} ${formatTime(new Date(period.end).getTime() / 1000)}`;
+    return [until, period.reason, period.owner && `(${period.owner})`].filter(Boolean).join(' · ');
+}
+
+// Describe a classified member error, e.g. "Invalid credentials (ORA-01017): ...".
+function errorTooltip(error) {
+    const code = error.code ? ` (${error.code})` : '';
+    return `${t(`health_${error.category}`)}${code}: ${error.message}`;
+}
+
+// Map a backend health level to the status icon class.
+function healthClass(level) {
+    switch (level) {
+        case 'OK':
+            return 'status-online';
+        case 'CRITICAL':
+            return 'status-offline';
+        case 'MAINTENANCE':
+            return 'status-maintenance';
+        default:
+            return 'status-warning';
+    }
+}
+
+// Build a tooltip listing the translated reasons behind a health verdict.
+function healthTooltip(health) {
+    const reasons = (health.reasons || []).map(reason => `${t(`health_${reason.code}`)}${reason.acknowledged ? ' ✓' : ''}`);
+    return [t(`health_${health.level}`), ...reasons].join('\n');
 }
 
 function adjustGridForFitScreen(totalCards) {
@@ -401,16 +1220,31 @@ async function init() {
     domCache.lbSystemList = document.getElementById('lb-system-list');
         domCache.dashboardContainer = document.querySelector('.dashboard');
     domCache.fullscreenBtn = document.getElementById('fullscreen-btn');
+    domCache.groupFilter = document.getElementById('group-filter');
+    domCache.groupSelect = document.getElementById('group-select');
+    domCache.pageIndicator = document.getElementById('page-indicator');
+    domCache.detail = document.getElementById('db-detail');
+
+    domCache.detail.querySelector('.detail-close').addEventListener('click', closeDetail);
+    domCache.detail.addEventListener('click', event => {
+        if (event.target === domCache.detail) closeDetail();
+    });
+    document.addEventListener('keydown', event => {
+        if (event.key === 'Escape') closeDetail();
+    });
 
     await loadTranslations(); // Load translations first
 
     applyTranslations();
     updateTitles(window.APP_TITLES);
     applyLayout();
+    renderUserInfo();
+    initGroupSelector();
     updateCurrentTime();
     setInterval(updateCurrentTime, 1000);
 
     fetchAndRenderData();
+    startKioskRotation();
 
     // Add fullscreen button listener
     if (domCache.fullscreenBtn) {
@@ -0,0 +1,109 @@
+// Monitoring account diagnostics page: renders the self-check from /api/diagnostics.
+
+const getLang = () => new URLSearchParams(window.location.search).get('lang') || 'zh';
+
+function t(key) {
+    return (window.I18N && window.I18N[key]) || key;
+}
+
+async function loadTranslations() {
+    try {
+        const response = await fetch(`api/i18n/${getLang()}`);
+        window.I18N = response.ok ? await response.json() : {};
+    } catch (error) {
+        console.error(error);
+        window.I18N = {};
+    }
+    document.querySelectorAll('[data-i18n]').forEach(element => {
+        const key = element.getAttribute('data-i18n');
+        if (window.I18N[key]) {
+            element.textContent = window.I18N[key];
+        }
+    });
+}
+
+function levelClass(level) {
+    switch (level) {
+        case 'OK':
+            return 'status-online';
+        case 'CRITICAL':
+            return 'status-offline';
+        default:
+            return 'status-warning';
+    }
+}
+
+function cell(text, className) {
+    const td = document.createElement('td');
+    td.textContent = text;
+    if (className) td.className = className;
+    return td;
+}
+
+function renderMember(db, member) {
+    const tr = document.createElement('tr');
+    tr.appendChild(cell(db.name));
+    tr.appendChild(cell(member.member === 'production' ? t('targetProd') : t('targetDR')));
+    tr.appendChild(cell(member.address));
+
+    const statusCell = cell('');
+    const icon = document.createElement('span');
+    icon.className = `status-icon ${levelClass(member.verdict.level)}`;
+    statusCell.appendChild(icon);
+    statusCell.appendChild(document.createTextNode(t(`health_${member.verdict.level}`)));
+    tr.appendChild(statusCell);
+
+    const account = member.account;
+    tr.appendChild(cell(account ? `${account.username} (${account.account_status})` : '-'));
+    if (account && account.expiry_date) {
+        tr.appendChild(cell(`${account.expiry_date.substring(0, 10)} (${account.days_until_expiry} ${t('daysLabel')})`));
+    } else {
+        tr.appendChild(cell(account ? t('neverExpires') : '-'));
+    }
+
+    const missing = (member.privileges || []).filter(p => !p.granted).map(p => p.object);
+    if (!member.connected) {
+        tr.appendChild(cell('-'));
+    } else if (missing.length > 0) {
+        tr.appendChild(cell(`${t('missingLabel')}: ${missing.join(', ')}`, 'error-text'));
+    } else {
+        tr.appendChild(cell(`${member.privileges.length} OK`));
+    }
+
+    const findings = member.verdict.reasons.map(reason => t(`health_${reason.code}`));
+    if (member.error) {
+        findings.push(`${member.error.code || ''} ${member.error.message}`.trim());
+    }
+    tr.appendChild(cell(findings.join('; ') || '-'));
+    return tr;
+}
+
+async function loadDiagnostics() {
+    const body = document.getElementById('diagnostics-body');
+    const summary = document.getElementById('diagnostics-summary');
+    summary.textContent = t('lbIpLoading');
+    try {
+        const params = new URLSearchParams(window.location.search);
+        params.delete('lang');
+        const query = params.toString();
+        const response = await fetch(`api/diagnostics${query ? `?${query}` : ''}`);
+        const result = await response.json();
+        if (result.code !== 200) {
+            throw new Error(result.message);
+        }
+        const report = result.data;
+        body.innerHTML = '';
+        report.databases.forEach(db => db.members.forEach(member => body.appendChild(renderMember(db, member))));
+        document.getElementById('generated-at').textContent = new Date(report.generated_at).toLocaleString();
+        summary.textContent = `${t('statusLabel')}: ${t(`health_${report.verdict.level}`)}`;
+        summary.className = `page-summary ${levelClass(report.verdict.level)}-text`;
+    } catch (error) {
+        console.error(error);
+        summary.textContent = `${t('dataLoadError')}: ${error.message}`;
+    }
+}
+
+document.addEventListener('DOMContentLoaded', async () => {
+    await loadTranslations();
+    loadDiagnostics();
+});
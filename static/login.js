@@ -0,0 +1,91 @@
+// Login page: offers the login methods reported by /auth/providers.
+
+const params = new URLSearchParams(window.location.search);
+// The language follows the page that redirected here, e.g. next=/?lang=en.
+const nextParams = new URLSearchParams((params.get('next') || '').split('?')[1] || '');
+const getLang = () => params.get('lang') || nextParams.get('lang') || 'zh';
+
+function t(key) {
+    return (window.I18N && window.I18N[key]) || key;
+}
+
+async function loadTranslations() {
+    try {
+        const response = await fetch(`api/i18n/${getLang()}`);
+        window.I18N = response.ok ? await response.json() : {};
+    } catch (error) {
+        console.error(error);
+        window.I18N = {};
+    }
+    document.querySelectorAll('[data-i18n]').forEach(element => {
+        const key = element.getAttribute('data-i18n');
+        if (window.I18N[key]) {
+            element.textContent = window.I18N[key];
+        }
+    });
+}
+
+function showError(message) {
+    document.getElementById('login-error').textContent = message;
+}
+
+async function submitLogin(event) {
+    event.preventDefault();
+    showError('');
+    const body = {
+        username: document.getElementById('login-username').value,
+        password: document.getElementById('login-password').value,
+        next: params.get('next') || '',
+    };
+    try {
+        const response = await fetch('auth/login', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify(body),
+        });
+        const result = await response.json();
+        if (!response.ok) {
+            showError(response.status === 401 ? t('loginFailed') : result.message);
+            return;
+        }
+        window.location.href = result.data.next;
+    } catch (error) {
+        console.error(error);
+        showError(t('loginFailed'));
+    }
+}
+
+async function init() {
+    await loadTranslations();
+    document.title = t('loginTitle');
+    if (params.get('error') === 'oidc') {
+        showError(t('loginOidcFailed'));
+    }
+
+    let providers;
+    try {
+        const response = await fetch('auth/providers');
+        providers = (await response.json()).data;
+    } catch (error) {
+        console.error(error);
+        showError(t('loginFailed'));
+        return;
+    }
+    if (!providers.enabled) {
+        window.location.href = './';
+        return;
+    }
+    if (providers.password) {
+        const form = document.getElementById('login-form');
+        form.style.display = '';
+        form.addEventListener('submit', submitLogin);
+    }
+    if (providers.oidc) {
+        const link = document.getElementById('login-oidc');
+        link.textContent = t('loginWith').replace('{name}', providers.oidc_name);
+        link.href = `auth/oidc/login?next=${encodeURIComponent(params.get('next') || '')}`;
+        link.style.display = '';
+    }
+}
+
+document.addEventListener('DOMContentLoaded', init);
@@ -0,0 +1,56 @@
+package util
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+// GetBackupJobs returns the RMAN backup jobs started within the last days, oldest first.
+// V$RMAN_BACKUP_JOB_DETAILS is read from the control file, so each member only
+// knows the jobs that ran against it. Times are converted to the local clock.
+func (o *OracleDB) GetBackupJobs(days int) ([]models.BackupJob, error) {
+	query := `
+		SELECT INPUT_TYPE, STATUS, START_TIME, END_TIME, ELAPSED_SECONDS, OUTPUT_BYTES, OUTPUT_DEVICE_TYPE
+		FROM V$RMAN_BACKUP_JOB_DETAILS
+		WHERE START_TIME > SYSDATE - :1
+		ORDER BY START_TIME`
+	// DATE columns carry no time zone; relate them to the local clock through SYSDATE.
+	var dbNow time.Time
+	if err := o.db.QueryRow("SELECT SYSDATE FROM DUAL").Scan(&dbNow); err != nil {
+		return nil, fmt.Errorf("failed to query SYSDATE: %w", err)
+	}
+	shift := time.Since(dbNow)
+
+	rows, err := o.db.Query(query, days)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query V$RMAN_BACKUP_JOB_DETAILS: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []models.BackupJob{}
+	for rows.Next() {
+		var job models.BackupJob
+		var endTime sql.NullTime
+		var elapsed, output sql.NullFloat64
+		var device sql.NullString
+		if err := rows.Scan(&job.InputType, &job.Status, &job.StartTime, &endTime, &elapsed, &output, &device); err != nil {
+			return nil, fmt.Errorf("failed to scan V$RMAN_BACKUP_JOB_DETAILS row: %w", err)
+		}
+		job.StartTime = job.StartTime.Add(shift)
+		if endTime.Valid {
+			end := endTime.Time.Add(shift)
+			job.EndTime = &end
+		}
+		job.DurationSeconds = int64(elapsed.Float64)
+		job.OutputBytes = int64(output.Float64)
+		job.DeviceType = device.String
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating V$RMAN_BACKUP_JOB_DETAILS results: %w", err)
+	}
+	return jobs, nil
+}
@@ -0,0 +1,124 @@
+package util
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+// GetConfigSettings collects the settings compared by the drift check: all
+// initialization parameters, database-level switches, the software version,
+// applied SQL patches and the online and standby redo log layout.
+// DBA_REGISTRY_SQLPATCH cannot be read on a mounted standby; the patch
+// setting is then left out rather than failing the whole collection.
+func (o *OracleDB) GetConfigSettings() (map[string]models.ConfigSetting, error) {
+	settings := make(map[string]models.ConfigSetting)
+
+	rows, err := o.db.Query("SELECT NAME, VALUE, ISDEFAULT FROM V$PARAMETER")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query V$PARAMETER: %w", err)
+	}
+	for rows.Next() {
+		var name, value, isDefault sql.NullString
+		if err := rows.Scan(&name, &value, &isDefault); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan V$PARAMETER row: %w", err)
+		}
+		if !name.Valid {
+			continue
+		}
+		settings["parameter."+strings.ToLower(name.String)] = models.ConfigSetting{
+			Value:   value.String,
+			Default: isDefault.String == "TRUE",
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating V$PARAMETER results: %w", err)
+	}
+
+	var forceLogging, flashbackOn, logMode, platform, version string
+	err = o.db.QueryRow("SELECT FORCE_LOGGING, FLASHBACK_ON, LOG_MODE, PLATFORM_NAME FROM V$DATABASE").Scan(&forceLogging, &flashbackOn, &logMode, &platform)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query V$DATABASE: %w", err)
+	}
+	settings["database.force_logging"] = models.ConfigSetting{Value: forceLogging}
+	settings["database.flashback_on"] = models.ConfigSetting{Value: flashbackOn}
+	settings["database.log_mode"] = models.ConfigSetting{Value: logMode}
+	settings["database.platform"] = models.ConfigSetting{Value: platform}
+
+	if err := o.db.QueryRow("SELECT VERSION FROM V$INSTANCE").Scan(&version); err != nil {
+		return nil, fmt.Errorf("failed to query V$INSTANCE: %w", err)
+	}
+	settings["instance.version"] = models.ConfigSetting{Value: version}
+
+	for view, key := range map[string]string{"V$LOG": "redo.online_logs", "V$STANDBY_LOG": "redo.standby_logs"} {
+		if err := o.collectRedoLayout(view, key, settings); err != nil {
+			return nil, err
+		}
+	}
+
+	if patches, err := o.getSQLPatches(); err == nil {
+		settings["patch.sql_patches"] = models.ConfigSetting{Value: patches}
+	}
+	return settings, nil
+}
+
+// collectRedoLayout records the number and size of redo log groups per thread, e.g. "4 x 512M".
+func (o *OracleDB) collectRedoLayout(view, key string, settings map[string]models.ConfigSetting) error {
+	rows, err := o.db.Query(fmt.Sprintf("SELECT THREAD#, COUNT(*), MIN(BYTES), MAX(BYTES) FROM %s GROUP BY THREAD#", view))
+	if err != nil {
+		return fmt.Errorf("failed to query %s: %w", view, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var thread, count int
+		var minBytes, maxBytes int64
+		if err := rows.Scan(&thread, &count, &minBytes, &maxBytes); err != nil {
+			return fmt.Errorf("failed to scan %s row: %w", view, err)
+		}
+		size := fmt.Sprintf("%dM", minBytes>>20)
+		if minBytes != maxBytes {
+			size = fmt.Sprintf("%d-%dM", minBytes>>20, maxBytes>>20)
+		}
+		settings[fmt.Sprintf("%s.thread_%d", key, thread)] = models.ConfigSetting{Value: fmt.Sprintf("%d x %s", count, size)}
+	}
+	return rows.Err()
+}
+
+// getSQLPatches returns the IDs of the SQL patches currently applied, in ascending order.
+func (o *OracleDB) getSQLPatches() (string, error) {
+	rows, err := o.db.Query("SELECT PATCH_ID, ACTION FROM DBA_REGISTRY_SQLPATCH WHERE STATUS = 'SUCCESS' ORDER BY ACTION_TIME")
+	if err != nil {
+		return "", fmt.Errorf("failed to query DBA_REGISTRY_SQLPATCH: %w", err)
+	}
+	defer rows.Close()
+	applied := make(map[int64]bool)
+	for rows.Next() {
+		var patchID int64
+		var action string
+		if err := rows.Scan(&patchID, &action); err != nil {
+			return "", fmt.Errorf("failed to scan DBA_REGISTRY_SQLPATCH row: %w", err)
+		}
+		applied[patchID] = action != "ROLLBACK"
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	var ids []int64
+	for id, isApplied := range applied {
+		if isApplied {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = fmt.Sprint(id)
+	}
+	return strings.Join(parts, ","), nil
+}
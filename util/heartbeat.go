@@ -0,0 +1,87 @@
+package util
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// heartbeatID is the key of the single row maintained in the heartbeat table.
+const heartbeatID = 1
+
+// ClockReading relates a database clock to the local clock.
+type ClockReading struct {
+	DBTime time.Time     // SYSTIMESTAMP as reported by the database
+	Offset time.Duration // Database clock minus local clock, taken at the midpoint of the round trip
+	Local  time.Time     // Local time at the midpoint of the round trip
+}
+
+// HeartbeatReading is the heartbeat row as seen on a standby.
+type HeartbeatReading struct {
+	Heartbeat time.Time // Primary SYSTIMESTAMP stored in the row
+	Clock     ClockReading
+}
+
+// newClockReading estimates the database clock offset from a round trip that
+// started at before, ended at after and returned dbTime.
+func newClockReading(dbTime, before, after time.Time) ClockReading {
+	local := before.Add(after.Sub(before) / 2)
+	return ClockReading{DBTime: dbTime, Offset: dbTime.Sub(local), Local: local}
+}
+
+// WriteHeartbeat stores the current SYSTIMESTAMP in the heartbeat table and returns
+// the database clock reading taken just before the write. The table must have the
+// columns ID NUMBER PRIMARY KEY and TS TIMESTAMP WITH TIME ZONE.
+func (o *OracleDB) WriteHeartbeat(table string) (ClockReading, error) {
+	before := time.Now()
+	var dbTime time.Time
+	if err := o.db.QueryRow("SELECT SYSTIMESTAMP FROM DUAL").Scan(&dbTime); err != nil {
+		return ClockReading{}, fmt.Errorf("failed to query SYSTIMESTAMP: %w", err)
+	}
+	clock := newClockReading(dbTime, before, time.Now())
+
+	query := fmt.Sprintf(`
+		MERGE INTO %s h
+		USING (SELECT :1 AS ID FROM DUAL) s
+		ON (h.ID = s.ID)
+		WHEN MATCHED THEN UPDATE SET h.TS = SYSTIMESTAMP
+		WHEN NOT MATCHED THEN INSERT (ID, TS) VALUES (s.ID, SYSTIMESTAMP)`, table)
+	if _, err := o.db.Exec(query, heartbeatID); err != nil {
+		return clock, fmt.Errorf("failed to write heartbeat into %s: %w", table, err)
+	}
+	return clock, nil
+}
+
+// CheckTableWrite verifies that the connected user may run the given DML
+// ("INSERT" or "UPDATE") on table. The statement matches no rows, so nothing is
+// changed, but Oracle still checks the privilege when parsing it.
+func (o *OracleDB) CheckTableWrite(table, privilege string) error {
+	var query string
+	switch privilege {
+	case "INSERT":
+		query = fmt.Sprintf("INSERT INTO %[1]s (ID, TS) SELECT ID, TS FROM %[1]s WHERE 1 = 0", table)
+	case "UPDATE":
+		query = fmt.Sprintf("UPDATE %s SET TS = TS WHERE 1 = 0", table)
+	default:
+		return fmt.Errorf("unsupported privilege %s", privilege)
+	}
+	if _, err := o.db.Exec(query); err != nil {
+		return fmt.Errorf("failed to %s %s: %w", privilege, table, err)
+	}
+	return nil
+}
+
+// ReadHeartbeat reads the heartbeat row together with the database clock.
+func (o *OracleDB) ReadHeartbeat(table string) (HeartbeatReading, error) {
+	query := fmt.Sprintf("SELECT TS, SYSTIMESTAMP FROM %s WHERE ID = :1", table)
+	before := time.Now()
+	var heartbeat, dbTime time.Time
+	err := o.db.QueryRow(query, heartbeatID).Scan(&heartbeat, &dbTime)
+	if err == sql.ErrNoRows {
+		return HeartbeatReading{}, fmt.Errorf("heartbeat table %s has no row yet", table)
+	}
+	if err != nil {
+		return HeartbeatReading{}, fmt.Errorf("failed to read heartbeat from %s: %w", table, err)
+	}
+	return HeartbeatReading{Heartbeat: heartbeat, Clock: newClockReading(dbTime, before, time.Now())}, nil
+}
@@ -1,119 +1,188 @@
 package util
 
-// This file provides network utility functions, including ICMP Ping and TCP port checking.
+// This file provides network utility functions, including native ICMP Ping and TCP port checking.
 // The code aims for simplicity, readability, and adherence to Go best practices.
 
 import (
-	"bytes"
-	"context"
+	"errors"
 	"fmt"
-	"io"
 	"net"
-	"os/exec"
-	"runtime"
+	"os"
 	"strconv"
-	"strings"
+	"sync/atomic"
 	"time"
 
-	"golang.org/x/text/encoding/simplifiedchinese"
-	"golang.org/x/text/transform"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
 )
 
-// PingHost tests if a host is reachable by sending ICMP echo requests (ping).
-// It takes the target IP address and a timeout duration.
-// Returns true if a ping reply is received within the timeout, false otherwise.
-// Returns an error if the ping command fails to execute or times out.
-func PingHost(ip string, timeout time.Duration) (bool, error) {
-	if ip == "" {
-		return false, fmt.Errorf("IP address cannot be empty")
+// PingResult holds the outcome of a series of ICMP echo requests to one host.
+type PingResult struct {
+	Sent       int
+	Received   int
+	PacketLoss float64       // Percentage of requests without a reply (0-100)
+	AvgRTT     time.Duration // Average round-trip time of the replies
+	MinRTT     time.Duration
+	MaxRTT     time.Duration
+	Jitter     time.Duration // Mean absolute difference between consecutive round-trip times
+}
+
+// pingID distinguishes the echo requests of concurrent pings on raw sockets,
+// where every socket receives all ICMP replies. Unprivileged datagram sockets
+// get their identifier assigned by the kernel and are demultiplexed by it.
+var pingID uint32 = uint32(os.Getpid())
+
+// ErrICMPUnavailable is returned by Ping when neither a datagram nor a raw ICMP
+// socket can be opened, e.g. in unprivileged containers.
+var ErrICMPUnavailable = errors.New("ICMP unavailable")
+
+// Ping sends count ICMP echo requests to the host natively, without forking the
+// ping binary. It first tries an unprivileged datagram ICMP socket (Linux needs
+// net.ipv4.ping_group_range to include the process group, macOS allows it by
+// default) and falls back to a raw socket, which needs root or CAP_NET_RAW.
+// The timeout is shared evenly between the requests.
+// An error is returned only if the probe could not be run at all, wrapping
+// ErrICMPUnavailable when no ICMP socket could be opened; unanswered requests
+// are reported through the packet loss of the result.
+func Ping(host string, count int, timeout time.Duration) (PingResult, error) {
+	var result PingResult
+	if host == "" {
+		return result, fmt.Errorf("IP address cannot be empty")
+	}
+	if count <= 0 {
+		count = 2
 	}
 	if timeout <= 0 {
 		timeout = 3 * time.Second // Default timeout if not specified or invalid
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-
-	var cmd *exec.Cmd
-	// Ping arguments differ between OSes.
-	// -n/-c: Number of pings to send. 2 is usually sufficient for a quick check.
-	// -w/-W: Timeout *per ping* in milliseconds/seconds. Set low (1s) to avoid waiting long for unresponsive hosts.
-	// The overall timeout is controlled by the context.
-	if runtime.GOOS == "windows" {
-		cmd = exec.CommandContext(ctx, "ping", "-n", "2", "-w", "1000", ip)
-	} else { // Linux, macOS, other Unix-like
-		cmd = exec.CommandContext(ctx, "ping", "-c", "2", "-W", "1", ip)
+	dst, err := net.ResolveIPAddr("ip", host)
+	if err != nil {
+		return result, fmt.Errorf("failed to resolve %s: %w", host, err)
 	}
 
-	output, err := cmd.CombinedOutput()
+	conn, privileged, err := listenICMP(dst.IP.To4() == nil)
+	if err != nil {
+		return result, fmt.Errorf("failed to open ICMP socket for %s: %w: %w", host, ErrICMPUnavailable, err)
+	}
+	defer conn.Close()
 
-	// Check for context timeout first
-	if ctx.Err() == context.DeadlineExceeded {
-		return false, fmt.Errorf("ping %s timed out after %v: %w", ip, timeout, ctx.Err())
+	var target net.Addr = dst
+	if !privileged {
+		target = &net.UDPAddr{IP: dst.IP, Zone: dst.Zone}
 	}
-	// Check for other command execution errors
-	if err != nil {
-		// Even if the command exits with an error (e.g., exit code 1),
-		// it might still contain output indicating success (e.g., 1 packet received on Linux).
-		// So, we don't return immediately here, but we will check the output.
-		// However, if there's a fundamental execution error (command not found, etc.),
-		// CombinedOutput might return an error *and* empty output.
-		// We will implicitly handle this when checking the output below.
-		// It's useful to return the underlying error though.
-		// Let's refine this: return the error *unless* the output clearly indicates success.
-	}
-
-	// Convert potential non-UTF8 output (like GBK on Chinese Windows) to UTF-8
-	utf8Output, convErr := convertToUTF8(output)
-	if convErr != nil {
-		// If conversion fails, fallback to original output but log the conversion error possibility
-		// Or return an error immediately? Let's return an error for clarity.
-		return false, fmt.Errorf("failed to convert ping output for %s to UTF-8: %w; original error (if any): %v", ip, convErr, err)
-	}
-
-	// Check output for success indicators (case-insensitive)
-	lowerOutput := strings.ToLower(utf8Output)
-	successConditions := []string{
-		// Linux/Unix/macOS styles
-		"1 received", "2 received", // Short form often seen
-		"1 packets received", "2 packets received", // Full form
-		"bytes from", // Common indicator of a reply
-		// Windows styles (English/Chinese)
-		"received = 1", "received = 2",
-		"已接收 = 1", "已接收 = 2", // Example for Chinese Windows GBK output
-		"来自", // Another common indicator in Chinese replies
-	}
-
-	for _, condition := range successConditions {
-		if strings.Contains(lowerOutput, condition) {
-			return true, nil // Ping successful based on output
+
+	id := int(atomic.AddUint32(&pingID, 1) & 0xffff)
+	perPacket := timeout / time.Duration(count)
+	var rtts []time.Duration
+
+	for seq := 1; seq <= count; seq++ {
+		result.Sent++
+		rtt, err := sendEcho(conn, target, dst.IP.To4() == nil, privileged, id, seq, perPacket)
+		if err != nil {
+			continue // Counted as lost
 		}
+		rtts = append(rtts, rtt)
 	}
 
-	// If we reach here, the command might have finished (err could be nil or non-nil),
-	// but the output doesn't indicate success.
-	if err != nil {
-		// Return the original command execution error
-		return false, fmt.Errorf("ping %s command failed: %w; output: %s", ip, err, utf8Output)
+	result.Received = len(rtts)
+	result.PacketLoss = float64(result.Sent-result.Received) * 100 / float64(result.Sent)
+	if len(rtts) == 0 {
+		return result, nil
 	}
 
-	// Command finished without error, but output doesn't look like success
-	return false, fmt.Errorf("ping %s command succeeded but output indicates failure: %s", ip, utf8Output)
+	var total, jitterTotal time.Duration
+	result.MinRTT, result.MaxRTT = rtts[0], rtts[0]
+	for i, rtt := range rtts {
+		total += rtt
+		if rtt < result.MinRTT {
+			result.MinRTT = rtt
+		}
+		if rtt > result.MaxRTT {
+			result.MaxRTT = rtt
+		}
+		if i > 0 {
+			diff := rtt - rtts[i-1]
+			if diff < 0 {
+				diff = -diff
+			}
+			jitterTotal += diff
+		}
+	}
+	result.AvgRTT = total / time.Duration(len(rtts))
+	if len(rtts) > 1 {
+		result.Jitter = jitterTotal / time.Duration(len(rtts)-1)
+	}
+	return result, nil
 }
 
-// convertToUTF8 attempts to decode byte slice assumed to be GBK (common on Chinese Windows) into UTF-8 string.
-// Falls back to interpreting as UTF-8 if decoding fails.
-func convertToUTF8(s []byte) (string, error) {
-	// Try decoding as GBK
-	reader := transform.NewReader(bytes.NewReader(s), simplifiedchinese.GBK.NewDecoder())
-	d, err := io.ReadAll(reader) // Use io.ReadAll (Go 1.16+)
+// listenICMP opens an unprivileged datagram ICMP socket, falling back to a raw one.
+// The returned flag reports whether the raw socket is in use.
+func listenICMP(useIPv6 bool) (*icmp.PacketConn, bool, error) {
+	network, rawNetwork, address := "udp4", "ip4:icmp", "0.0.0.0"
+	if useIPv6 {
+		network, rawNetwork, address = "udp6", "ip6:ipv6-icmp", "::"
+	}
+	conn, err := icmp.ListenPacket(network, address)
 	if err == nil {
-		return string(d), nil // Successfully decoded as GBK
+		return conn, false, nil
+	}
+	rawConn, rawErr := icmp.ListenPacket(rawNetwork, address)
+	if rawErr != nil {
+		return nil, false, fmt.Errorf("datagram socket: %v; raw socket: %w", err, rawErr)
+	}
+	return rawConn, true, nil
+}
+
+// sendEcho sends one echo request and waits for the matching reply.
+func sendEcho(conn *icmp.PacketConn, target net.Addr, useIPv6, privileged bool, id, seq int, timeout time.Duration) (time.Duration, error) {
+	var requestType, replyType icmp.Type = ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	protocol := 1 // ICMP for IPv4
+	if useIPv6 {
+		requestType, replyType = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+		protocol = 58 // ICMPv6
+	}
+
+	msg := icmp.Message{
+		Type: requestType,
+		Body: &icmp.Echo{ID: id, Seq: seq, Data: []byte("go-oracle-dr-dashboard")},
+	}
+	request, err := msg.Marshal(nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to build echo request: %w", err)
+	}
+
+	start := time.Now()
+	deadline := start.Add(timeout)
+	if err := conn.SetReadDeadline(deadline); err != nil {
+		return 0, err
+	}
+	if _, err := conn.WriteTo(request, target); err != nil {
+		return 0, fmt.Errorf("failed to send echo request: %w", err)
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return 0, err // Includes the read deadline being exceeded
+		}
+		reply, err := icmp.ParseMessage(protocol, buf[:n])
+		if err != nil || reply.Type != replyType {
+			continue
+		}
+		echo, ok := reply.Body.(*icmp.Echo)
+		if !ok || echo.Seq != seq {
+			continue
+		}
+		// The kernel rewrites the identifier of datagram sockets, so it is only
+		// meaningful for raw sockets that see every reply on the host.
+		if privileged && echo.ID != id {
+			continue
+		}
+		return time.Since(start), nil
 	}
-	// If GBK decoding failed, return the original bytes interpreted as UTF-8,
-	// along with the decoding error for context. It might be UTF-8 already or some other encoding.
-	// Returning the original string(s) is often a reasonable fallback.
-	return string(s), fmt.Errorf("GBK decoding failed: %w", err)
 }
 
 // CheckTCPPort tests if a TCP connection can be established to a specific IP and port within a given timeout.
@@ -139,4 +208,4 @@ func CheckTCPPort(ip string, port int, timeout time.Duration) (bool, error) {
 	// Don't forget to close the connection if successfully opened!
 	defer conn.Close()
 	return true, nil // Connection successful
-} 
\ No newline at end of file
+}
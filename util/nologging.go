@@ -0,0 +1,72 @@
+package util
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+// tableNotFoundCode is ORA-00942, raised for views that do not exist in older releases.
+const tableNotFoundCode = 942
+
+// GetUnrecoverableFiles returns V$DATABASE.FORCE_LOGGING and the datafiles
+// that were changed by NOLOGGING operations, ordered by file number.
+func (o *OracleDB) GetUnrecoverableFiles() (string, []models.UnrecoverableFile, error) {
+	var forceLogging string
+	if err := o.db.QueryRow("SELECT FORCE_LOGGING FROM V$DATABASE").Scan(&forceLogging); err != nil {
+		return "", nil, fmt.Errorf("failed to query V$DATABASE.FORCE_LOGGING: %w", err)
+	}
+	// DATE columns carry no time zone; relate them to the local clock through SYSDATE.
+	var dbNow time.Time
+	if err := o.db.QueryRow("SELECT SYSDATE FROM DUAL").Scan(&dbNow); err != nil {
+		return "", nil, fmt.Errorf("failed to query SYSDATE: %w", err)
+	}
+	shift := time.Since(dbNow)
+
+	query := `
+		SELECT FILE#, NAME, UNRECOVERABLE_CHANGE#, UNRECOVERABLE_TIME
+		FROM V$DATAFILE
+		WHERE UNRECOVERABLE_CHANGE# > 0
+		ORDER BY FILE#`
+	rows, err := o.db.Query(query)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to query V$DATAFILE: %w", err)
+	}
+	defer rows.Close()
+
+	files := []models.UnrecoverableFile{}
+	for rows.Next() {
+		var file models.UnrecoverableFile
+		var changeTime sql.NullTime
+		if err := rows.Scan(&file.File, &file.Name, &file.Change, &changeTime); err != nil {
+			return "", nil, fmt.Errorf("failed to scan V$DATAFILE row: %w", err)
+		}
+		if changeTime.Valid {
+			t := changeTime.Time.Add(shift)
+			file.Time = &t
+		}
+		files = append(files, file)
+	}
+	if err := rows.Err(); err != nil {
+		return "", nil, fmt.Errorf("error iterating V$DATAFILE results: %w", err)
+	}
+	return forceLogging, files, nil
+}
+
+// GetNonloggedBlocks summarizes V$NONLOGGED_BLOCK on a standby. It returns nil
+// without an error when the view does not exist, i.e. before Oracle 12.2.
+func (o *OracleDB) GetNonloggedBlocks() (*models.NonloggedBlocks, error) {
+	query := `
+		SELECT COUNT(DISTINCT FILE#), COUNT(*), NVL(SUM(BLOCKS), 0)
+		FROM V$NONLOGGED_BLOCK`
+	var blocks models.NonloggedBlocks
+	if err := o.db.QueryRow(query).Scan(&blocks.Files, &blocks.Ranges, &blocks.Blocks); err != nil {
+		if isOracleError(err, tableNotFoundCode) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to query V$NONLOGGED_BLOCK: %w", err)
+	}
+	return &blocks, nil
+}
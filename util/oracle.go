@@ -11,6 +11,27 @@ import (
 	go_ora "github.com/sijms/go-ora/v2"
 )
 
+// RequiredViews lists the dictionary views read by the status checks in this package.
+// The diagnostics self-check verifies that the monitoring account can read each of them,
+// so every new query must add the views it uses here.
+var RequiredViews = []string{
+	"V$DATABASE",
+	"V$DATAGUARD_STATS",
+	"V$SESSION",
+	"V$RECOVERY_FILE_DEST",
+	"V$RECOVERY_AREA_USAGE",
+	"V$ARCHIVE_DEST",
+	"V$ASM_DISKGROUP_STAT",
+	"V$RMAN_BACKUP_JOB_DETAILS",
+	"V$PARAMETER",
+	"V$INSTANCE",
+	"V$LOG",
+	"V$STANDBY_LOG",
+	"DBA_REGISTRY_SQLPATCH",
+	"V$DATAFILE",
+	"V$PDBS",
+}
+
 // OracleConfig holds Oracle connection parameters.
 type OracleConfig struct {
 	Host        string
@@ -67,7 +88,7 @@ func NewOracleDB(cfg *OracleConfig) (*OracleDB, error) {
 
 	if err = db.Ping(); err != nil {
 		db.Close()
-		return nil, fmt.Errorf("failed to ping database: %w (DSN: %s)", err, dsn)
+		return nil, fmt.Errorf("failed to ping database: %w (DSN: %s)", err, SanitizeErrorMessage(dsn))
 	}
 
 	return &OracleDB{db: db, cfg: cfg}, nil
@@ -93,20 +114,31 @@ func TestConnection(host string, port int, user, password, serviceName string) b
 	return err == nil
 }
 
-// GetDatabaseInfo retrieves basic database information like role and open mode.
+// GetDatabaseInfo retrieves basic database information like role and open mode,
+// along with the identity of the instance that served the session.
 func (o *OracleDB) GetDatabaseInfo() (map[string]interface{}, error) {
-	query := "SELECT DATABASE_ROLE, OPEN_MODE FROM V$DATABASE"
+	query := `
+		SELECT DATABASE_ROLE, OPEN_MODE, DB_UNIQUE_NAME, RESETLOGS_CHANGE#,
+		       SYS_CONTEXT('USERENV', 'INSTANCE_NAME'),
+		       SYS_CONTEXT('USERENV', 'SERVER_HOST')
+		FROM V$DATABASE`
 	row := o.db.QueryRow(query)
 
-	var databaseRole, openMode string
-	err := row.Scan(&databaseRole, &openMode)
+	var databaseRole, openMode, dbUniqueName string
+	var resetlogsChange int64
+	var instanceName, serverHost sql.NullString
+	err := row.Scan(&databaseRole, &openMode, &dbUniqueName, &resetlogsChange, &instanceName, &serverHost)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query V$DATABASE: %w", err)
 	}
 
 	return map[string]interface{}{
-		"DATABASE_ROLE": databaseRole,
-		"OPEN_MODE":     openMode,
+		"DATABASE_ROLE":     databaseRole,
+		"OPEN_MODE":         openMode,
+		"DB_UNIQUE_NAME":    dbUniqueName,
+		"RESETLOGS_CHANGE#": resetlogsChange,
+		"INSTANCE_NAME":     instanceName.String,
+		"SERVER_HOST":       serverHost.String,
 	}, nil
 }
 
@@ -267,6 +299,34 @@ func (o *OracleDB) GetBusinessConnectionCount() (int, error) {
 	return count, nil
 }
 
+// CheckViewAccess verifies that the connected user can select from the view.
+// The query returns no rows; only missing privileges or objects raise an error.
+func (o *OracleDB) CheckViewAccess(view string) error {
+	rows, err := o.db.Query(fmt.Sprintf("SELECT 1 FROM %s WHERE ROWNUM < 1", view))
+	if err != nil {
+		return fmt.Errorf("failed to query %s: %w", view, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+	}
+	return rows.Err()
+}
+
+// GetAccountStatus retrieves the status and password expiry of the connected user.
+func (o *OracleDB) GetAccountStatus() (models.AccountStatus, error) {
+	query := "SELECT USERNAME, ACCOUNT_STATUS, EXPIRY_DATE FROM USER_USERS"
+	var account models.AccountStatus
+	var expiry sql.NullTime
+	err := o.db.QueryRow(query).Scan(&account.Username, &account.AccountStatus, &expiry)
+	if err != nil {
+		return account, fmt.Errorf("failed to query USER_USERS: %w", err)
+	}
+	if expiry.Valid {
+		account.ExpiryDate = &expiry.Time
+	}
+	return account, nil
+}
+
 func CreateOraUtilConfig(ip string, dbCfg models.DatabaseConfig) *OracleConfig {
 	return &OracleConfig{
 		Host:        ip,
@@ -274,8 +334,8 @@ func CreateOraUtilConfig(ip string, dbCfg models.DatabaseConfig) *OracleConfig {
 		ServiceName: dbCfg.ServiceName,
 		Username:    dbCfg.Username,
 		Password:    dbCfg.Password,
-		ConnTimeout: 5, // Short timeout for status check connection attempt
+		ConnTimeout: dbCfg.ConnTimeout,
 		ConnectType: "service_name",
 		URLOptions:  make(map[string]string),
 	}
-} 
\ No newline at end of file
+}
@@ -0,0 +1,115 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+	"github.com/sijms/go-ora/v2/network"
+)
+
+var (
+	oraCodePattern      = regexp.MustCompile(`ORA-(\d{5})`)
+	dsnCredentialsRegex = regexp.MustCompile(`oracle://\S*@`)
+	passwordParamRegex  = regexp.MustCompile(`(?i)(password\s*[=:]\s*)\S+`)
+)
+
+// maxErrorMessageLength bounds the sanitized message exposed through the API.
+const maxErrorMessageLength = 300
+
+// oraCategories maps Oracle error numbers to error categories.
+var oraCategories = map[int]string{
+	1017:  models.ErrInvalidCredentials,
+	28000: models.ErrAccountLocked,
+	28001: models.ErrPasswordExpired,
+	942:   models.ErrInsufficientPrivileges,
+	1031:  models.ErrInsufficientPrivileges,
+	12514: models.ErrServiceUnknown,
+	12505: models.ErrServiceUnknown,
+	1033:  models.ErrShutdownInProgress,
+	1089:  models.ErrShutdownInProgress,
+	1090:  models.ErrShutdownInProgress,
+	1034:  models.ErrDatabaseUnavailable,
+	27101: models.ErrDatabaseUnavailable,
+	12170: models.ErrTimeout,
+	12535: models.ErrTimeout,
+	12541: models.ErrConnectionRefused,
+	28759: models.ErrTLS,
+	28860: models.ErrTLS,
+	28862: models.ErrTLS,
+	28864: models.ErrTLS,
+	28865: models.ErrTLS,
+	29024: models.ErrTLS,
+	29106: models.ErrTLS,
+}
+
+// isOracleError reports whether err carries the given ORA- error code.
+func isOracleError(err error, code int) bool {
+	var oraErr *network.OracleError
+	return errors.As(err, &oraErr) && oraErr.ErrCode == code
+}
+
+// ClassifyOracleError maps an error returned by the driver or the network stack
+// to an error category and extracts the ORA- code if there is one.
+// stage names the check step that failed, one of the models.Stage* constants.
+// A nil error yields nil.
+func ClassifyOracleError(err error, stage string) *models.MemberError {
+	if err == nil {
+		return nil
+	}
+	result := &models.MemberError{
+		Category: models.ErrOther,
+		Message:  SanitizeErrorMessage(err.Error()),
+		Stage:    stage,
+	}
+
+	code := 0
+	var oraErr *network.OracleError
+	if errors.As(err, &oraErr) {
+		code = oraErr.ErrCode
+	} else if m := oraCodePattern.FindStringSubmatch(err.Error()); m != nil {
+		fmt.Sscanf(m[1], "%d", &code)
+	}
+	if code > 0 {
+		result.Code = fmt.Sprintf("ORA-%05d", code)
+		if category, ok := oraCategories[code]; ok {
+			result.Category = category
+			return result
+		}
+	}
+
+	// Fall back to the network layer for errors that carry no ORA- code.
+	var netErr net.Error
+	lower := strings.ToLower(err.Error())
+	// The DSN quoted in connect errors contains "connection timeout=", so refusals
+	// are matched first and timeouts only on the network error wording.
+	switch {
+	case strings.Contains(lower, "connection refused"):
+		result.Category = models.ErrConnectionRefused
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout(),
+		strings.Contains(lower, "i/o timeout"), strings.Contains(lower, "timed out"):
+		result.Category = models.ErrTimeout
+	case strings.Contains(lower, "tls:"), strings.Contains(lower, "x509:"), strings.Contains(lower, "certificate"):
+		result.Category = models.ErrTLS
+	}
+	return result
+}
+
+// SanitizeErrorMessage strips credentials from an error message and shortens it
+// to its first line so that it can be shown to dashboard users.
+func SanitizeErrorMessage(message string) string {
+	message = dsnCredentialsRegex.ReplaceAllString(message, "oracle://***@")
+	message = passwordParamRegex.ReplaceAllString(message, "${1}***")
+	if idx := strings.IndexAny(message, "\r\n"); idx >= 0 {
+		message = message[:idx]
+	}
+	message = strings.TrimSpace(message)
+	if len(message) > maxErrorMessageLength {
+		message = message[:maxErrorMessageLength] + "..."
+	}
+	return message
+}
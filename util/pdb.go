@@ -0,0 +1,42 @@
+package util
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+// GetPDBs returns the pluggable databases of a CDB from V$PDBS, ordered by
+// container ID. A non-CDB returns no rows; before 12c the view does not exist
+// and nil is returned without an error.
+func (o *OracleDB) GetPDBs() ([]models.PDBState, error) {
+	query := `
+		SELECT CON_ID, NAME, OPEN_MODE, RESTRICTED, RECOVERY_STATUS
+		FROM V$PDBS
+		ORDER BY CON_ID`
+	rows, err := o.db.Query(query)
+	if err != nil {
+		if isOracleError(err, tableNotFoundCode) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to query V$PDBS: %w", err)
+	}
+	defer rows.Close()
+
+	pdbs := []models.PDBState{}
+	for rows.Next() {
+		var pdb models.PDBState
+		var restricted, recoveryStatus sql.NullString
+		if err := rows.Scan(&pdb.ConID, &pdb.Name, &pdb.OpenMode, &restricted, &recoveryStatus); err != nil {
+			return nil, fmt.Errorf("failed to scan V$PDBS row: %w", err)
+		}
+		pdb.Restricted = restricted.String
+		pdb.RecoveryStatus = recoveryStatus.String
+		pdbs = append(pdbs, pdb)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating V$PDBS results: %w", err)
+	}
+	return pdbs, nil
+}
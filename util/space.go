@@ -0,0 +1,172 @@
+package util
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/goodwaysIT/go-oracle-dr-dashboard/models"
+)
+
+// fraDestination is how V$ARCHIVE_DEST shows a destination inside the recovery area.
+const fraDestination = "USE_DB_RECOVERY_FILE_DEST"
+
+// localDestRegex matches archive destinations on the database host itself:
+// absolute paths, Windows drive paths and ASM disk groups.
+var localDestRegex = regexp.MustCompile(`^(/|[A-Za-z]:\\|\+)`)
+
+// GetSpaceUsage collects the recovery area usage and the local archive destinations.
+// Sizes are filled in for destinations in the recovery area or on ASM; Oracle
+// exposes nothing for a file system path, so those only carry status and error.
+func (o *OracleDB) GetSpaceUsage() (models.SpaceUsage, error) {
+	usage := models.SpaceUsage{ArchiveDests: []models.ArchiveDestSpace{}}
+
+	fra, err := o.getRecoveryArea()
+	if err != nil {
+		return usage, err
+	}
+	usage.RecoveryArea = fra
+
+	dests, err := o.getArchiveDests()
+	if err != nil {
+		return usage, err
+	}
+	diskGroups, err := o.getDiskGroupSpace(dests)
+	if err != nil {
+		return usage, err
+	}
+
+	for _, dest := range dests {
+		switch {
+		case strings.EqualFold(dest.Destination, fraDestination):
+			if fra != nil {
+				setDestSpace(&dest, fra.LimitBytes, fra.LimitBytes-fra.UsedBytes+fra.ReclaimableBytes)
+			}
+		case strings.HasPrefix(dest.Destination, "+"):
+			if space, ok := diskGroups[diskGroupName(dest.Destination)]; ok {
+				setDestSpace(&dest, space[0], space[1])
+			}
+		}
+		usage.ArchiveDests = append(usage.ArchiveDests, dest)
+	}
+	return usage, nil
+}
+
+// getRecoveryArea reads V$RECOVERY_FILE_DEST and V$RECOVERY_AREA_USAGE.
+// It returns nil when no recovery area is configured.
+func (o *OracleDB) getRecoveryArea() (*models.RecoveryAreaUsage, error) {
+	query := "SELECT NAME, SPACE_LIMIT, SPACE_USED, SPACE_RECLAIMABLE FROM V$RECOVERY_FILE_DEST"
+	var name sql.NullString
+	var fra models.RecoveryAreaUsage
+	err := o.db.QueryRow(query).Scan(&name, &fra.LimitBytes, &fra.UsedBytes, &fra.ReclaimableBytes)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query V$RECOVERY_FILE_DEST: %w", err)
+	}
+	if !name.Valid || fra.LimitBytes <= 0 {
+		return nil, nil
+	}
+	fra.Name = name.String
+	fra.UsedPercent = float64(fra.UsedBytes-fra.ReclaimableBytes) / float64(fra.LimitBytes) * 100
+
+	rows, err := o.db.Query("SELECT FILE_TYPE, PERCENT_SPACE_USED, PERCENT_SPACE_RECLAIMABLE, NUMBER_OF_FILES FROM V$RECOVERY_AREA_USAGE")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query V$RECOVERY_AREA_USAGE: %w", err)
+	}
+	defer rows.Close()
+	fra.FileTypes = []models.RecoveryFileTypeUsage{}
+	for rows.Next() {
+		var fileType models.RecoveryFileTypeUsage
+		if err := rows.Scan(&fileType.FileType, &fileType.PercentUsed, &fileType.PercentReclaimable, &fileType.NumberOfFiles); err != nil {
+			return nil, fmt.Errorf("failed to scan V$RECOVERY_AREA_USAGE row: %w", err)
+		}
+		fra.FileTypes = append(fra.FileTypes, fileType)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating V$RECOVERY_AREA_USAGE results: %w", err)
+	}
+	return &fra, nil
+}
+
+// getArchiveDests reads the active local archive destinations from V$ARCHIVE_DEST.
+// Remote destinations (standby services) are skipped.
+func (o *OracleDB) getArchiveDests() ([]models.ArchiveDestSpace, error) {
+	query := `
+		SELECT DEST_NAME, DESTINATION, STATUS, ERROR
+		FROM V$ARCHIVE_DEST
+		WHERE STATUS <> 'INACTIVE' AND DESTINATION IS NOT NULL`
+	rows, err := o.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query V$ARCHIVE_DEST: %w", err)
+	}
+	defer rows.Close()
+
+	var dests []models.ArchiveDestSpace
+	for rows.Next() {
+		var dest models.ArchiveDestSpace
+		var destError sql.NullString
+		if err := rows.Scan(&dest.DestName, &dest.Destination, &dest.Status, &destError); err != nil {
+			return nil, fmt.Errorf("failed to scan V$ARCHIVE_DEST row: %w", err)
+		}
+		if !strings.EqualFold(dest.Destination, fraDestination) && !localDestRegex.MatchString(dest.Destination) {
+			continue
+		}
+		dest.Error = strings.TrimSpace(destError.String)
+		dests = append(dests, dest)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating V$ARCHIVE_DEST results: %w", err)
+	}
+	return dests, nil
+}
+
+// getDiskGroupSpace returns total and free bytes per ASM disk group, but only
+// queries V$ASM_DISKGROUP_STAT when an archive destination is on ASM.
+func (o *OracleDB) getDiskGroupSpace(dests []models.ArchiveDestSpace) (map[string][2]int64, error) {
+	space := map[string][2]int64{}
+	onASM := false
+	for _, dest := range dests {
+		onASM = onASM || strings.HasPrefix(dest.Destination, "+")
+	}
+	if !onASM {
+		return space, nil
+	}
+
+	rows, err := o.db.Query("SELECT NAME, TOTAL_MB, FREE_MB FROM V$ASM_DISKGROUP_STAT")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query V$ASM_DISKGROUP_STAT: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var totalMB, freeMB int64
+		if err := rows.Scan(&name, &totalMB, &freeMB); err != nil {
+			return nil, fmt.Errorf("failed to scan V$ASM_DISKGROUP_STAT row: %w", err)
+		}
+		space[strings.ToUpper(name)] = [2]int64{totalMB << 20, freeMB << 20}
+	}
+	return space, rows.Err()
+}
+
+// diskGroupName extracts "DATA" from an ASM destination such as "+DATA/ORCL/ARCHIVELOG".
+func diskGroupName(destination string) string {
+	name := strings.TrimPrefix(destination, "+")
+	if idx := strings.Index(name, "/"); idx >= 0 {
+		name = name[:idx]
+	}
+	return strings.ToUpper(name)
+}
+
+// setDestSpace fills in the size and free space of an archive destination.
+func setDestSpace(dest *models.ArchiveDestSpace, total, free int64) {
+	if total <= 0 {
+		return
+	}
+	used := float64(total-free) / float64(total) * 100
+	dest.TotalBytes = &total
+	dest.FreeBytes = &free
+	dest.UsedPercent = &used
+}
@@ -0,0 +1,186 @@
+package util
+
+// This file implements a credential-free probe of the Oracle TNS listener.
+// It sends a TNS CONNECT packet for a service and classifies the listener's
+// answer, which tells a listener without the service registered (ORA-12514)
+// apart from a database that merely rejects the monitoring login.
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// TNS packet types relevant to the connect handshake.
+const (
+	tnsPacketConnect  = 1
+	tnsPacketAccept   = 2
+	tnsPacketRefuse   = 4
+	tnsPacketRedirect = 5
+	tnsPacketData     = 6
+	tnsPacketResend   = 11
+)
+
+// tnsDataFlagEOF marks the DATA packet a client sends to end a session.
+const tnsDataFlagEOF = 0x0040
+
+// Outcomes reported in TNSProbeResult.Outcome.
+const (
+	TNSAccept   = "ACCEPT"
+	TNSRefuse   = "REFUSE"
+	TNSRedirect = "REDIRECT"
+)
+
+// tnsConnectDataOffset is where the connect descriptor starts in a CONNECT packet:
+// 8 header bytes followed by 50 bytes of fixed connect fields.
+const tnsConnectDataOffset = 58
+
+var tnsErrPattern = regexp.MustCompile(`\(ERR=(\d+)\)`)
+
+// TNSProbeResult describes how the listener answered a CONNECT for a service.
+type TNSProbeResult struct {
+	Outcome   string // TNSAccept, TNSRefuse or TNSRedirect
+	ErrorCode int    // TNS/ORA error code carried by a REFUSE, e.g. 12514; 0 otherwise
+	Data      string // Refuse or redirect data as sent by the listener
+}
+
+// ProbeTNSListener sends a TNS CONNECT packet for the service to the listener
+// and reads its answer without authenticating. An ACCEPT means the listener
+// handed the connection to a registered service handler; the probe then ends
+// the session with an end-of-file DATA packet, as a client disconnecting
+// cleanly does, so the server process exits without logging TNS-12537 or
+// TNS-12547 for a dropped connection.
+// An error is returned when no classifiable answer is received.
+func ProbeTNSListener(host string, port int, serviceName string, timeout time.Duration) (TNSProbeResult, error) {
+	var result TNSProbeResult
+	if host == "" {
+		return result, fmt.Errorf("IP address cannot be empty")
+	}
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return result, fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return result, err
+	}
+
+	// Connect data longer than 230 bytes would have to follow in a separate packet,
+	// so the descriptor is kept to the essentials.
+	connectData := fmt.Sprintf("(DESCRIPTION=(CONNECT_DATA=(SERVICE_NAME=%s)(CID=(PROGRAM=dr-dashboard)(HOST=probe)(USER=probe))))", serviceName)
+	if len(connectData) > 230 {
+		return result, fmt.Errorf("service name '%s' is too long for a TNS probe", serviceName)
+	}
+	packet := buildTNSConnect(connectData)
+
+	// Listeners may ask for the CONNECT to be sent again once.
+	for attempt := 0; attempt < 2; attempt++ {
+		if _, err := conn.Write(packet); err != nil {
+			return result, fmt.Errorf("failed to send TNS connect to %s: %w", address, err)
+		}
+		packetType, body, err := readTNSPacket(conn)
+		if err != nil {
+			return result, fmt.Errorf("failed to read TNS response from %s: %w", address, err)
+		}
+
+		switch packetType {
+		case tnsPacketAccept:
+			result.Outcome = TNSAccept
+			// The answer is known at this point; a failed disconnect only leaves the
+			// server process to notice the closed socket.
+			conn.Write(buildTNSDisconnect())
+			return result, nil
+		case tnsPacketRefuse:
+			// User reason (1), system reason (1), data length (2), data.
+			result.Outcome = TNSRefuse
+			result.Data = tnsPayload(body, 2)
+			if m := tnsErrPattern.FindStringSubmatch(result.Data); m != nil {
+				result.ErrorCode, _ = strconv.Atoi(m[1])
+			}
+			return result, nil
+		case tnsPacketRedirect:
+			// Data length (2), data.
+			result.Outcome = TNSRedirect
+			result.Data = tnsPayload(body, 0)
+			return result, nil
+		case tnsPacketResend:
+			continue
+		default:
+			return result, fmt.Errorf("unexpected TNS packet type %d from %s", packetType, address)
+		}
+	}
+	return result, fmt.Errorf("listener at %s kept requesting a resend", address)
+}
+
+// buildTNSConnect assembles a CONNECT packet carrying the connect descriptor.
+func buildTNSConnect(connectData string) []byte {
+	data := []byte(connectData)
+	packet := make([]byte, tnsConnectDataOffset+len(data))
+
+	binary.BigEndian.PutUint16(packet[0:], uint16(len(packet))) // Packet length
+	packet[4] = tnsPacketConnect                                // Packet type
+
+	binary.BigEndian.PutUint16(packet[8:], 0x013A)  // Version 314
+	binary.BigEndian.PutUint16(packet[10:], 0x012C) // Lowest compatible version 300
+	binary.BigEndian.PutUint16(packet[12:], 0x0C41) // Service options
+	binary.BigEndian.PutUint16(packet[14:], 0x2000) // Session data unit size
+	binary.BigEndian.PutUint16(packet[16:], 0x7FFF) // Maximum transmission data unit size
+	binary.BigEndian.PutUint16(packet[18:], 0x4F98) // NT protocol characteristics
+	binary.BigEndian.PutUint16(packet[22:], 0x0001) // Value of 1 in hardware (byte order)
+	binary.BigEndian.PutUint16(packet[24:], uint16(len(data)))
+	binary.BigEndian.PutUint16(packet[26:], tnsConnectDataOffset)
+	binary.BigEndian.PutUint32(packet[28:], 0x00000800) // Maximum receivable connect data
+	packet[32] = 0x01                                   // Connect flags 0
+	packet[33] = 0x01                                   // Connect flags 1
+	copy(packet[tnsConnectDataOffset:], data)
+	return packet
+}
+
+// buildTNSDisconnect assembles the DATA packet with the end-of-file flag that
+// closes a session.
+func buildTNSDisconnect() []byte {
+	packet := make([]byte, 10)
+	binary.BigEndian.PutUint16(packet[0:], uint16(len(packet))) // Packet length
+	packet[4] = tnsPacketData                                   // Packet type
+	binary.BigEndian.PutUint16(packet[8:], tnsDataFlagEOF)      // Data flags
+	return packet
+}
+
+// readTNSPacket reads one TNS packet and returns its type and body.
+func readTNSPacket(r io.Reader) (byte, []byte, error) {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+	length := int(binary.BigEndian.Uint16(header[0:]))
+	if length < len(header) {
+		return 0, nil, fmt.Errorf("invalid TNS packet length %d", length)
+	}
+	body := make([]byte, length-len(header))
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header[4], body, nil
+}
+
+// tnsPayload extracts the length-prefixed data that starts at offset in a packet body.
+func tnsPayload(body []byte, offset int) string {
+	if len(body) < offset+2 {
+		return ""
+	}
+	length := int(binary.BigEndian.Uint16(body[offset:]))
+	start := offset + 2
+	if start+length > len(body) {
+		length = len(body) - start
+	}
+	return string(body[start : start+length])
+}
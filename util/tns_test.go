@@ -0,0 +1,169 @@
+package util
+
+import (
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// tnsPacket builds a TNS packet of the given type around body.
+func tnsPacket(packetType byte, body []byte) []byte {
+	packet := make([]byte, 8+len(body))
+	binary.BigEndian.PutUint16(packet[0:], uint16(len(packet)))
+	packet[4] = packetType
+	copy(packet[8:], body)
+	return packet
+}
+
+// tnsRefuse builds a REFUSE packet carrying the listener's error description.
+func tnsRefuse(data string) []byte {
+	body := make([]byte, 4+len(data))
+	binary.BigEndian.PutUint16(body[2:], uint16(len(data)))
+	copy(body[4:], data)
+	return tnsPacket(tnsPacketRefuse, body)
+}
+
+// fakeListener accepts one connection and answers each CONNECT packet it
+// receives with the next of replies. It returns the listener's port and a
+// channel with the connect descriptors received.
+func fakeListener(t *testing.T, replies ...[]byte) (int, <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	received := make(chan string, len(replies))
+	go func() {
+		defer close(received)
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		for _, reply := range replies {
+			packetType, body, err := readTNSPacket(conn)
+			if err != nil || packetType != tnsPacketConnect {
+				return
+			}
+			// The connect data offset at byte 26 counts from the start of the packet, header included.
+			offset := int(binary.BigEndian.Uint16(body[26-8:])) - 8
+			received <- string(body[offset:])
+			if _, err := conn.Write(reply); err != nil {
+				return
+			}
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port, received
+}
+
+func TestProbeTNSListener(t *testing.T) {
+	accept := tnsPacket(tnsPacketAccept, make([]byte, 24))
+	resend := tnsPacket(tnsPacketResend, nil)
+
+	tests := []struct {
+		name      string
+		replies   [][]byte
+		outcome   string
+		errorCode int
+		wantErr   string
+	}{
+		{name: "accept", replies: [][]byte{accept}, outcome: TNSAccept},
+		{
+			name:      "service not registered",
+			replies:   [][]byte{tnsRefuse("(DESCRIPTION=(TMP=)(VSNNUM=0)(ERR=12514)(ERROR_STACK=(ERROR=(CODE=12514)(EMFI=4))))")},
+			outcome:   TNSRefuse,
+			errorCode: 12514,
+		},
+		{
+			name:      "instance blocked",
+			replies:   [][]byte{tnsRefuse("(DESCRIPTION=(ERR=12528)(VSNNUM=0))")},
+			outcome:   TNSRefuse,
+			errorCode: 12528,
+		},
+		{
+			name:    "refuse without error code",
+			replies: [][]byte{tnsRefuse("(DESCRIPTION=(TMP=))")},
+			outcome: TNSRefuse,
+		},
+		{name: "resend then accept", replies: [][]byte{resend, accept}, outcome: TNSAccept},
+		{name: "resend twice", replies: [][]byte{resend, resend}, wantErr: "kept requesting a resend"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port, received := fakeListener(t, tt.replies...)
+			result, err := ProbeTNSListener("127.0.0.1", port, "ORCLPDB", 2*time.Second)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("probe: %v", err)
+			}
+			if result.Outcome != tt.outcome || result.ErrorCode != tt.errorCode {
+				t.Errorf("got outcome %s, error code %d; want %s, %d", result.Outcome, result.ErrorCode, tt.outcome, tt.errorCode)
+			}
+			descriptor := <-received
+			if !strings.Contains(descriptor, "(SERVICE_NAME=ORCLPDB)") {
+				t.Errorf("connect descriptor %q does not ask for the service", descriptor)
+			}
+		})
+	}
+}
+
+func TestProbeTNSListenerDisconnectsAfterAccept(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	type packet struct {
+		packetType byte
+		body       []byte
+		err        error
+	}
+	after := make(chan packet, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			after <- packet{err: err}
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if _, _, err := readTNSPacket(conn); err != nil {
+			after <- packet{err: err}
+			return
+		}
+		conn.Write(tnsPacket(tnsPacketAccept, make([]byte, 24)))
+		packetType, body, err := readTNSPacket(conn)
+		after <- packet{packetType, body, err}
+	}()
+
+	result, err := ProbeTNSListener("127.0.0.1", ln.Addr().(*net.TCPAddr).Port, "ORCLPDB", 2*time.Second)
+	if err != nil || result.Outcome != TNSAccept {
+		t.Fatalf("probe: got %+v, %v", result, err)
+	}
+	p := <-after
+	if p.err != nil {
+		t.Fatalf("no packet after ACCEPT: %v", p.err)
+	}
+	if p.packetType != tnsPacketData || len(p.body) < 2 || binary.BigEndian.Uint16(p.body) != tnsDataFlagEOF {
+		t.Errorf("got packet type %d with body %x after ACCEPT, want DATA with the EOF flag", p.packetType, p.body)
+	}
+}
+
+func TestProbeTNSListenerUnexpectedPacket(t *testing.T) {
+	port, _ := fakeListener(t, tnsPacket(tnsPacketData, nil)) // DATA instead of an answer to CONNECT
+	if _, err := ProbeTNSListener("127.0.0.1", port, "ORCLPDB", 2*time.Second); err == nil {
+		t.Fatal("expected an error for an unexpected packet type")
+	}
+}